@@ -1,15 +1,120 @@
 -- Create the necessary ENUM types
-CREATE TYPE order_status AS ENUM('accepted','pending', 'processing', 'completed', 'cancelled','rejected');
+CREATE TYPE order_status AS ENUM('accepted','pending', 'processing', 'ready', 'completed', 'cancelled','rejected');
 CREATE TYPE unit_of_measurement AS ENUM('mg', 'g', 'kg', 'oz', 'lb', 'ml', 'l', 'dl', 'fl', 'pc', 'dozen', 'cup', 'tsp', 'tbsp', 'shots'); 
 CREATE TYPE type_of_transaction AS ENUM('addition', 'deduction');
+CREATE TYPE transaction_reason AS ENUM('opening_balance', 'restock', 'manual_adjustment', 'order', 'count_correction');
+CREATE TYPE stock_count_status AS ENUM('open', 'finalized');
+CREATE TYPE webhook_delivery_status AS ENUM('pending', 'delivered', 'dead');
+CREATE TYPE loyalty_reason AS ENUM('earn', 'redeem', 'reversal', 'adjustment');
+CREATE TYPE promotion_type AS ENUM('percentage', 'fixed', 'buy_x_get_y');
+CREATE TYPE order_type AS ENUM('dine_in', 'takeaway');
+CREATE TYPE payment_method AS ENUM('cash', 'card', 'voucher');
+CREATE TYPE drawer_session_status AS ENUM('open', 'closed');
+CREATE TYPE drawer_movement_type AS ENUM('cash_in', 'cash_out');
+CREATE TYPE employee_role AS ENUM('barista', 'shift_lead', 'manager', 'owner');
+CREATE TYPE audit_action AS ENUM('create', 'update', 'delete');
+
+-- Create Employees table. Employees are deactivated rather than deleted, so
+-- the orders and movements they made stay attributed
+CREATE TABLE employees(
+    employee_id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    email VARCHAR(255) UNIQUE,
+    phone VARCHAR(50),
+    role employee_role NOT NULL DEFAULT 'barista',
+    hourly_rate NUMERIC NOT NULL DEFAULT 0 CHECK(hourly_rate >= 0),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    username VARCHAR(100) UNIQUE,
+    password_hash TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create Auth Sessions table, staff logins. The session token is signed and
+-- carries its expiry; the row is what revocation is checked against
+CREATE TABLE auth_sessions(
+    session_id SERIAL PRIMARY KEY,
+    employee_id INT NOT NULL REFERENCES employees(employee_id),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP
+);
+
+-- Create API Tokens table for integrations. Only the SHA-256 of a token is
+-- stored; role sets what the token is allowed to do
+CREATE TABLE api_tokens(
+    token_id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    role employee_role NOT NULL,
+    created_by INT REFERENCES employees(employee_id),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP,
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP
+);
+
+-- Create Role Permissions table, what each role may do. The owner has
+-- every permission and is not listed
+CREATE TABLE role_permissions(
+    role employee_role NOT NULL,
+    permission VARCHAR(100) NOT NULL,
+    PRIMARY KEY(role, permission),
+    CHECK(role <> 'owner')
+);
+
+-- Create Shifts table, the scheduled working hours
+CREATE TABLE shifts(
+    shift_id SERIAL PRIMARY KEY,
+    employee_id INT NOT NULL REFERENCES employees(employee_id),
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    note TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK(ends_at > starts_at)
+);
+
+-- Create Time Entries table, the hours actually worked from clock-in to
+-- clock-out
+CREATE TABLE time_entries(
+    entry_id SERIAL PRIMARY KEY,
+    employee_id INT NOT NULL REFERENCES employees(employee_id),
+    shift_id INT REFERENCES shifts(shift_id) ON DELETE SET NULL,
+    clock_in TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    clock_out TIMESTAMP,
+    CHECK(clock_out IS NULL OR clock_out >= clock_in)
+);
+
+-- Create Customers table
+CREATE TABLE customers(
+    customer_id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    phone VARCHAR(50) UNIQUE,
+    email VARCHAR(255) UNIQUE,
+    marketing_consent BOOLEAN NOT NULL DEFAULT FALSE,
+    loyalty_points INT NOT NULL DEFAULT 0 CHECK(loyalty_points >= 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
 
 -- Create Orders table
 CREATE TABLE orders(
     order_id SERIAL PRIMARY KEY,
     customer_name VARCHAR(255) NOT NULL,
+    customer_id INT REFERENCES customers(customer_id) ON DELETE SET NULL,
     special_instructions JSONB,
+    subtotal NUMERIC NOT NULL DEFAULT 0,
+    discount_amount NUMERIC NOT NULL DEFAULT 0 CHECK(discount_amount >= 0),
+    tax_amount NUMERIC NOT NULL DEFAULT 0 CHECK(tax_amount >= 0),
     total_amount NUMERIC DEFAULT 0 CHECK(total_amount >= 0),
+    order_type order_type NOT NULL DEFAULT 'dine_in',
+    employee_id INT REFERENCES employees(employee_id),
     status order_status DEFAULT 'pending',
+    priority INT NOT NULL DEFAULT 0,
+    station VARCHAR(100),
+    handled_by VARCHAR(255),
+    claimed_at TIMESTAMP,
+    ready_at TIMESTAMP,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
@@ -30,6 +135,7 @@ CREATE TABLE inventory(
     name VARCHAR(255) NOT NULL,
     quantity NUMERIC NOT NULL CHECK(quantity >= 0),
     unit unit_of_measurement NOT NULL,
+    cost_per_unit NUMERIC NOT NULL DEFAULT 0 CHECK(cost_per_unit >= 0),
     last_updated TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
@@ -39,7 +145,9 @@ CREATE TABLE order_items(
     order_id INT REFERENCES orders(order_id) ON DELETE CASCADE,
     menu_item_id INT REFERENCES menu_items(menu_item_id) ON DELETE CASCADE,
     quantity INT NOT NULL CHECK(quantity > 0),
-    price_at_order NUMERIC NOT NULL CHECK(price_at_order > 0)
+    price_at_order NUMERIC NOT NULL CHECK(price_at_order > 0),
+    customization_options JSONB,
+    done_at TIMESTAMP
 );
 
 -- Create Menu Item Ingredients table
@@ -56,9 +164,213 @@ CREATE TABLE inventory_transactions(
     ingredient_id INT REFERENCES inventory(ingredient_id) ON DELETE CASCADE,
     quantity_change NUMERIC NOT NULL,
     transaction_type type_of_transaction NOT NULL,
+    reason transaction_reason NOT NULL DEFAULT 'manual_adjustment',
+    unit_cost NUMERIC CHECK(unit_cost >= 0),
+    employee_id INT REFERENCES employees(employee_id),
     transaction_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Create Stock Counts table (physical stock-take sessions)
+CREATE TABLE stock_counts(
+    count_id SERIAL PRIMARY KEY,
+    status stock_count_status NOT NULL DEFAULT 'open',
+    note TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    finalized_at TIMESTAMP
+);
+
+-- Create Stock Count Lines table
+CREATE TABLE stock_count_lines(
+    count_id INT REFERENCES stock_counts(count_id) ON DELETE CASCADE,
+    ingredient_id INT REFERENCES inventory(ingredient_id) ON DELETE CASCADE,
+    counted_quantity NUMERIC NOT NULL CHECK(counted_quantity >= 0),
+    system_quantity NUMERIC NOT NULL,
+    counted_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (count_id, ingredient_id)
+);
+
+-- Create Order Events table, the feed of GET /orders/stream
+CREATE TABLE order_events(
+    event_id BIGSERIAL PRIMARY KEY,
+    order_id INT NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create Outbox Events table; rows are written in the same transaction as
+-- the change they describe and later fanned out to webhook subscriptions
+CREATE TABLE outbox_events(
+    event_id BIGSERIAL PRIMARY KEY,
+    event_type VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    dispatched_at TIMESTAMP
+);
+
+-- Create Webhook Subscriptions table
+CREATE TABLE webhook_subscriptions(
+    subscription_id SERIAL PRIMARY KEY,
+    url TEXT NOT NULL,
+    event_types TEXT[] NOT NULL,
+    secret TEXT NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create Webhook Deliveries table
+CREATE TABLE webhook_deliveries(
+    delivery_id BIGSERIAL PRIMARY KEY,
+    subscription_id INT REFERENCES webhook_subscriptions(subscription_id) ON DELETE CASCADE,
+    event_id BIGINT REFERENCES outbox_events(event_id) ON DELETE CASCADE,
+    status webhook_delivery_status NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    last_status_code INT,
+    last_error TEXT,
+    delivered_at TIMESTAMP,
+    UNIQUE (subscription_id, event_id)
+);
+
+-- Create Webhook Dead Letters table
+CREATE TABLE webhook_dead_letters(
+    dead_letter_id BIGSERIAL PRIMARY KEY,
+    delivery_id BIGINT REFERENCES webhook_deliveries(delivery_id) ON DELETE CASCADE,
+    attempts INT NOT NULL,
+    last_error TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    redelivered_at TIMESTAMP
+);
+
+-- Create Loyalty Transactions table; points are signed, the sum per
+-- customer equals customers.loyalty_points
+CREATE TABLE loyalty_transactions(
+    transaction_id SERIAL PRIMARY KEY,
+    customer_id INT NOT NULL REFERENCES customers(customer_id) ON DELETE CASCADE,
+    order_id INT REFERENCES orders(order_id) ON DELETE SET NULL,
+    points INT NOT NULL,
+    reason loyalty_reason NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create Promotions table. A promotion without a code is applied
+-- automatically; menu_item_id and category narrow it to matching lines.
+-- For buy_x_get_y, value is the percentage off the free items.
+CREATE TABLE promotions(
+    promotion_id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    type promotion_type NOT NULL,
+    value NUMERIC NOT NULL CHECK(value > 0),
+    code VARCHAR(50) UNIQUE,
+    menu_item_id INT REFERENCES menu_items(menu_item_id) ON DELETE CASCADE,
+    category VARCHAR(100),
+    buy_quantity INT CHECK(buy_quantity > 0),
+    get_quantity INT CHECK(get_quantity > 0),
+    min_subtotal NUMERIC NOT NULL DEFAULT 0,
+    starts_at TIMESTAMP,
+    ends_at TIMESTAMP,
+    days_of_week INT[],
+    start_time TIME,
+    end_time TIME,
+    usage_limit INT CHECK(usage_limit > 0),
+    usage_count INT NOT NULL DEFAULT 0,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK(type <> 'buy_x_get_y' OR (buy_quantity IS NOT NULL AND get_quantity IS NOT NULL)),
+    CHECK(type <> 'percentage' OR value <= 100)
+);
+
+-- Create Order Promotions table, the discounts applied to each order
+CREATE TABLE order_promotions(
+    order_id INT REFERENCES orders(order_id) ON DELETE CASCADE,
+    promotion_id INT REFERENCES promotions(promotion_id) ON DELETE CASCADE,
+    discount_amount NUMERIC NOT NULL CHECK(discount_amount >= 0),
+    PRIMARY KEY (order_id, promotion_id)
+);
+
+-- Create Settings table, shop-wide options stored as text
+CREATE TABLE settings(
+    key VARCHAR(100) PRIMARY KEY,
+    value TEXT NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create Tax Rates table. A rate without category or order type applies
+-- to every line; rate is a percentage.
+CREATE TABLE tax_rates(
+    tax_rate_id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    rate NUMERIC NOT NULL CHECK(rate >= 0 AND rate <= 100),
+    category VARCHAR(100),
+    order_type order_type,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create Order Item Taxes table, the tax of every order line per rate.
+-- The rows are kept when the menu item is deleted, so tax reports of past
+-- periods don't change
+CREATE TABLE order_item_taxes(
+    order_item_tax_id SERIAL PRIMARY KEY,
+    order_id INT REFERENCES orders(order_id) ON DELETE CASCADE,
+    menu_item_id INT REFERENCES menu_items(menu_item_id) ON DELETE SET NULL,
+    tax_rate_id INT REFERENCES tax_rates(tax_rate_id),
+    rate NUMERIC NOT NULL,
+    taxable_amount NUMERIC NOT NULL,
+    tax_amount NUMERIC NOT NULL,
+    UNIQUE (order_id, menu_item_id, tax_rate_id)
+);
+
+-- Create Drawer Sessions table, one till shift from the opening float to
+-- the Z-report. The report is a snapshot taken when the session is closed
+CREATE TABLE drawer_sessions(
+    session_id SERIAL PRIMARY KEY,
+    opened_by VARCHAR(100) NOT NULL,
+    opening_float NUMERIC NOT NULL CHECK(opening_float >= 0),
+    status drawer_session_status NOT NULL DEFAULT 'open',
+    opened_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    closed_by VARCHAR(100),
+    closed_at TIMESTAMP,
+    counted_cash NUMERIC CHECK(counted_cash >= 0),
+    z_report JSONB
+);
+
+-- Create Drawer Movements table, cash put into or taken out of the drawer
+CREATE TABLE drawer_movements(
+    movement_id SERIAL PRIMARY KEY,
+    session_id INT NOT NULL REFERENCES drawer_sessions(session_id),
+    type drawer_movement_type NOT NULL,
+    amount NUMERIC NOT NULL CHECK(amount > 0),
+    reason TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create Payments table. Amount goes towards the order total, the tip is on
+-- top of it and change is what was handed back from the tendered cash
+CREATE TABLE payments(
+    payment_id SERIAL PRIMARY KEY,
+    order_id INT NOT NULL REFERENCES orders(order_id),
+    method payment_method NOT NULL,
+    amount NUMERIC NOT NULL CHECK(amount > 0),
+    tip NUMERIC NOT NULL DEFAULT 0 CHECK(tip >= 0),
+    tendered NUMERIC CHECK(tendered >= 0),
+    change_given NUMERIC NOT NULL DEFAULT 0 CHECK(change_given >= 0),
+    reference VARCHAR(100),
+    drawer_session_id INT REFERENCES drawer_sessions(session_id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create Refunds table, full or partial refunds of a payment
+CREATE TABLE refunds(
+    refund_id SERIAL PRIMARY KEY,
+    order_id INT NOT NULL REFERENCES orders(order_id),
+    payment_id INT NOT NULL REFERENCES payments(payment_id),
+    amount NUMERIC NOT NULL CHECK(amount > 0),
+    reason TEXT NOT NULL,
+    drawer_session_id INT REFERENCES drawer_sessions(session_id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
 -- Create Order Status History table
 CREATE TABLE order_status_history(
     status_id SERIAL PRIMARY KEY,
@@ -76,30 +388,47 @@ CREATE TABLE price_history(
     changed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Create Audit Log table. Entries are never changed or deleted, and keep no
+-- foreign keys so that they outlive what they describe
+CREATE TABLE audit_log(
+    audit_id BIGSERIAL PRIMARY KEY,
+    action audit_action NOT NULL,
+    entity VARCHAR(50) NOT NULL,
+    entity_id INT NOT NULL,
+    before_data JSONB,
+    after_data JSONB,
+    actor_kind VARCHAR(20),
+    actor_id INT,
+    actor_name VARCHAR(100),
+    employee_id INT,
+    request_id VARCHAR(100),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
 
 -- Insert sample data into inventory
-INSERT INTO inventory(name, quantity, unit, last_updated) VALUES
-    ('Coffee Beans', 5500, 'g', '2024-01-01 08:00:00'),
-    ('Muffin', 3000, 'pc', '2024-01-01 09:00:00'),  
-    ('Milk', 1000, 'l', '2024-01-01 09:30:00'),
-    ('Sugar', 1000, 'g', '2024-01-01 10:00:00'),
-    ('Flour', 10000, 'g', '2024-01-01 11:00:00'),
-    ('Eggs', 500, 'pc', '2024-01-01 12:00:00'),
-    ('Chocolate', 3000, 'g', '2024-01-01 13:00:00'),
-    ('Vanilla Extract', 200, 'ml', '2024-01-01 14:00:00'),
-    ('Butter', 2000, 'g', '2024-01-01 15:00:00'),
-    ('Cinnamon', 500, 'g', '2024-01-01 16:00:00'),
-    ('Salt', 1000, 'g', '2024-01-01 17:00:00'),
-    ('Bananas', 150, 'pc', '2024-01-01 18:00:00'),
-    ('Strawberries', 300, 'pc', '2024-01-01 19:00:00'),
-    ('Blueberries', 200, 'pc', '2024-01-01 20:00:00'),
-    ('Lemons', 100, 'pc', '2024-01-01 21:00:00'),
-    ('Oranges', 120, 'pc', '2024-01-01 22:00:00'),
-    ('Yeast', 100, 'g', '2024-01-01 23:00:00'),
-    ('Baking Powder', 300, 'g', '2024-01-01 00:00:00'),
-    ('Cream', 100, 'l', '2024-01-01 01:00:00'),
-    ('Honey', 500, 'g', '2024-01-01 02:00:00'),
-    ('Whipped Cream', 50, 'l', '2024-01-01 03:00:00');
+INSERT INTO inventory(name, quantity, unit, cost_per_unit, last_updated) VALUES
+    ('Coffee Beans', 5500, 'g', 0.02, '2024-01-01 08:00:00'),
+    ('Muffin', 3000, 'pc', 0.8, '2024-01-01 09:00:00'),  
+    ('Milk', 1000, 'l', 1.2, '2024-01-01 09:30:00'),
+    ('Sugar', 1000, 'g', 0.003, '2024-01-01 10:00:00'),
+    ('Flour', 10000, 'g', 0.002, '2024-01-01 11:00:00'),
+    ('Eggs', 500, 'pc', 0.25, '2024-01-01 12:00:00'),
+    ('Chocolate', 3000, 'g', 0.015, '2024-01-01 13:00:00'),
+    ('Vanilla Extract', 200, 'ml', 0.3, '2024-01-01 14:00:00'),
+    ('Butter', 2000, 'g', 0.01, '2024-01-01 15:00:00'),
+    ('Cinnamon', 500, 'g', 0.02, '2024-01-01 16:00:00'),
+    ('Salt', 1000, 'g', 0.001, '2024-01-01 17:00:00'),
+    ('Bananas', 150, 'pc', 0.3, '2024-01-01 18:00:00'),
+    ('Strawberries', 300, 'pc', 0.2, '2024-01-01 19:00:00'),
+    ('Blueberries', 200, 'pc', 0.1, '2024-01-01 20:00:00'),
+    ('Lemons', 100, 'pc', 0.4, '2024-01-01 21:00:00'),
+    ('Oranges', 120, 'pc', 0.35, '2024-01-01 22:00:00'),
+    ('Yeast', 100, 'g', 0.02, '2024-01-01 23:00:00'),
+    ('Baking Powder', 300, 'g', 0.01, '2024-01-01 00:00:00'),
+    ('Cream', 100, 'l', 4.5, '2024-01-01 01:00:00'),
+    ('Honey', 500, 'g', 0.012, '2024-01-01 02:00:00'),
+    ('Whipped Cream', 50, 'l', 6, '2024-01-01 03:00:00');
 
 -- Insert sample data into menu_items
 INSERT INTO menu_items(name, description, price, categories, allergens) VALUES
@@ -135,13 +464,69 @@ INSERT INTO menu_item_ingredients(menu_item_id, inventory_id, quantity) VALUES
     (10, 4, 200),
     (10, 18, 100);
 
+-- Insert sample data into employees
+INSERT INTO employees (name, email, role, hourly_rate) VALUES
+    ('Olivia Owner', 'olivia@frappuccino.local', 'owner', 30),
+    ('Mark Manager', 'mark@frappuccino.local', 'manager', 22),
+    ('Sam Lead', 'sam@frappuccino.local', 'shift_lead', 18),
+    ('Bella Barista', 'bella@frappuccino.local', 'barista', 15);
+
+-- The owner logs in as olivia with the password frappuccino, which should be
+-- changed right after the first login
+UPDATE employees
+SET username = 'olivia',
+    password_hash = 'pbkdf2-sha256$600000$nxwqe+TQWjMWyI4vS32aAQ$VfqZbg+oDvxraVZ7XjivEgcR0FpnemSu5BPDPrPBDdQ'
+WHERE email = 'olivia@frappuccino.local';
+
+-- Insert default role permissions. A barista takes orders and payments and
+-- works the drawer, a shift lead also cancels, refunds and counts stock, and
+-- a manager runs the menu, inventory, staff and reports
+INSERT INTO role_permissions (role, permission)
+SELECT r.role::employee_role, p.permission
+FROM (VALUES ('barista'), ('shift_lead'), ('manager')) AS r(role)
+CROSS JOIN (VALUES
+    ('orders.read'), ('orders.create'), ('orders.update'), ('payments.create'),
+    ('customers.read'), ('customers.write'), ('menu.read'), ('promotions.read'),
+    ('drawer.read'), ('drawer.operate'), ('settings.read'), ('inventory.read'),
+    ('shifts.read'), ('time.clock')) AS p(permission);
+
+INSERT INTO role_permissions (role, permission)
+SELECT r.role::employee_role, p.permission
+FROM (VALUES ('shift_lead'), ('manager')) AS r(role)
+CROSS JOIN (VALUES
+    ('orders.delete'), ('payments.refund'), ('inventory.count'), ('reports.sales'),
+    ('employees.read')) AS p(permission);
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('manager', 'orders.close_override'),
+    ('manager', 'menu.write'),
+    ('manager', 'promotions.write'),
+    ('manager', 'settings.write'),
+    ('manager', 'inventory.write'),
+    ('manager', 'reports.profit'),
+    ('manager', 'employees.write'),
+    ('manager', 'shifts.write'),
+    ('manager', 'webhooks.manage'),
+    ('manager', 'audit.read');
+
+-- Insert default settings
+INSERT INTO settings (key, value) VALUES
+    ('tax_inclusive_pricing', 'false'),
+    ('receipt_header', E'Frappuccino Coffee Shop\n1 Coffee Street'),
+    ('receipt_footer', 'Thank you for your visit!');
+
+-- Insert sample data into tax_rates
+INSERT INTO tax_rates (name, rate, order_type) VALUES
+    ('VAT dine-in', 12, 'dine_in'),
+    ('VAT takeaway', 8, 'takeaway');
+
 -- Insert sample data into orders
-INSERT INTO orders (customer_name, special_instructions, total_amount, status, created_at, updated_at) VALUES
-    ('Alice', '["No sugar", "Extra shot"]'::jsonb, 15.5, 'completed', '2024-12-01 10:00:00', '2024-12-01 10:15:00'),
-    ('Bob', '["No dairy"]'::jsonb, 12.0, 'pending', '2024-12-02 12:00:00', '2024-12-02 12:05:00'),
-    ('Charlie', '["Gluten free"]'::jsonb, 18.0, 'cancelled', '2024-12-03 14:00:00', '2024-12-03 14:10:00'),
-    ('Diana', '["Low fat milk"]'::jsonb, 20.5, 'completed', '2024-12-04 16:00:00', '2024-12-04 16:10:00'),
-    ('Eve', '["Extra vanilla", "No foam"]'::jsonb, 22.0, 'completed', '2024-12-05 08:00:00', '2024-12-05 08:10:00');
+INSERT INTO orders (customer_name, special_instructions, subtotal, total_amount, status, created_at, updated_at) VALUES
+    ('Alice', '["No sugar", "Extra shot"]'::jsonb, 15.5, 15.5, 'completed', '2024-12-01 10:00:00', '2024-12-01 10:15:00'),
+    ('Bob', '["No dairy"]'::jsonb, 12.0, 12.0, 'pending', '2024-12-02 12:00:00', '2024-12-02 12:05:00'),
+    ('Charlie', '["Gluten free"]'::jsonb, 18.0, 18.0, 'cancelled', '2024-12-03 14:00:00', '2024-12-03 14:10:00'),
+    ('Diana', '["Low fat milk"]'::jsonb, 20.5, 20.5, 'completed', '2024-12-04 16:00:00', '2024-12-04 16:10:00'),
+    ('Eve', '["Extra vanilla", "No foam"]'::jsonb, 22.0, 22.0, 'completed', '2024-12-05 08:00:00', '2024-12-05 08:10:00');
 
 -- Insert sample data into order_items
 INSERT INTO order_items(order_id, menu_item_id, quantity, price_at_order) VALUES
@@ -168,14 +553,21 @@ INSERT INTO price_history(menu_item_id, old_price, new_price, changed_at) VALUES
     (4, 1.8, 2.0, '2024-12-04 15:00:00'),
     (5, 2.0, 2.5, '2024-12-05 17:00:00');
 
+-- Opening balances, so that replaying the ledger from zero gives inventory.quantity
+INSERT INTO inventory_transactions (ingredient_id, quantity_change, transaction_type, reason, unit_cost, transaction_date)
+SELECT i.ingredient_id, i.quantity - COALESCE(s.net, 0), 'addition', 'opening_balance', i.cost_per_unit, i.last_updated
+FROM inventory i
+LEFT JOIN (VALUES (1, -30), (2, 500), (3, -5), (4, 300)) AS s(ingredient_id, net) ON s.ingredient_id = i.ingredient_id
+ORDER BY i.ingredient_id;
+
 -- Insert sample data into inventory_transactions
-INSERT INTO inventory_transactions (ingredient_id, quantity_change, transaction_type, transaction_date)
+INSERT INTO inventory_transactions (ingredient_id, quantity_change, transaction_type, reason, unit_cost, transaction_date)
 VALUES
-(1, 10, 'deduction', '2025-02-12 10:05:00'),
-(2, 500, 'addition', '2025-02-12 12:00:00'),
-(3, 5, 'deduction', '2025-02-12 14:00:00'),
-(1, 20, 'deduction', '2025-02-12 15:30:00'),
-(4, 300, 'addition', '2025-02-12 16:00:00');
+(1, 10, 'deduction', 'order', NULL, '2025-02-12 10:05:00'),
+(2, 500, 'addition', 'restock', 0.75, '2025-02-12 12:00:00'),
+(3, 5, 'deduction', 'order', NULL, '2025-02-12 14:00:00'),
+(1, 20, 'deduction', 'order', NULL, '2025-02-12 15:30:00'),
+(4, 300, 'addition', 'restock', 0.004, '2025-02-12 16:00:00');
 
 -- Index for fast inventory lookup by ID
 CREATE INDEX idx_inventory_id ON inventory (ingredient_id);
@@ -186,6 +578,165 @@ CREATE INDEX idx_menu_items_id ON menu_items (menu_item_id);
 -- Index for fast orders lookup by ID
 CREATE INDEX idx_orders_id ON orders (order_id);
 
+-- Indexes for order listing
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX idx_orders_created_at ON orders (created_at, order_id);
+CREATE INDEX idx_orders_total_amount ON orders (total_amount, order_id);
+CREATE INDEX idx_orders_status ON orders (status);
+CREATE INDEX idx_orders_customer_name ON orders USING GIN (customer_name gin_trgm_ops);
+CREATE INDEX idx_order_items_order_id ON order_items (order_id);
+CREATE INDEX idx_order_items_menu_item_id ON order_items (menu_item_id, order_id);
+
+-- Indexes for customer history and loyalty
+CREATE INDEX idx_orders_customer_id ON orders (customer_id, created_at) WHERE customer_id IS NOT NULL;
+CREATE INDEX idx_loyalty_transactions_customer ON loyalty_transactions (customer_id, transaction_id);
+CREATE INDEX idx_loyalty_transactions_order ON loyalty_transactions (order_id);
+
+-- Indexes for the payments ledger
+CREATE INDEX idx_payments_order ON payments (order_id, payment_id);
+CREATE INDEX idx_refunds_order ON refunds (order_id, refund_id);
+CREATE INDEX idx_refunds_payment ON refunds (payment_id);
+
+-- Indexes for scheduling and time tracking; an employee can be clocked in
+-- only once at a time
+CREATE INDEX idx_shifts_employee ON shifts (employee_id, starts_at);
+CREATE INDEX idx_shifts_starts_at ON shifts (starts_at);
+CREATE INDEX idx_time_entries_employee ON time_entries (employee_id, clock_in);
+CREATE UNIQUE INDEX idx_time_entries_open ON time_entries (employee_id) WHERE clock_out IS NULL;
+CREATE INDEX idx_orders_employee_id ON orders (employee_id) WHERE employee_id IS NOT NULL;
+
+-- Indexes for authentication
+CREATE INDEX idx_auth_sessions_employee ON auth_sessions (employee_id) WHERE revoked_at IS NULL;
+
+-- Indexes for the audit log
+CREATE INDEX idx_audit_log_entity ON audit_log (entity, entity_id, audit_id);
+CREATE INDEX idx_audit_log_created_at ON audit_log (created_at);
+CREATE INDEX idx_audit_log_request ON audit_log (request_id) WHERE request_id IS NOT NULL;
+
+-- Only one drawer can be open at a time
+CREATE UNIQUE INDEX idx_drawer_sessions_open ON drawer_sessions (status) WHERE status = 'open';
+CREATE INDEX idx_drawer_movements_session ON drawer_movements (session_id);
+CREATE INDEX idx_payments_drawer_session ON payments (drawer_session_id);
+CREATE INDEX idx_refunds_drawer_session ON refunds (drawer_session_id);
+
+-- Index for tax reporting
+CREATE INDEX idx_order_item_taxes_rate ON order_item_taxes (tax_rate_id);
+
+-- Index for discount reporting
+CREATE INDEX idx_order_promotions_promotion ON order_promotions (promotion_id);
+
+-- Indexes for webhook dispatching
+CREATE INDEX idx_outbox_events_pending ON outbox_events (event_id) WHERE dispatched_at IS NULL;
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+
+-- Index for the barista queue
+CREATE INDEX idx_orders_queue ON orders (priority DESC, created_at, order_id) WHERE status IN ('pending', 'accepted', 'processing');
+
+-- Indexes for inventory sorting and filtering
+CREATE INDEX idx_inventory_name ON inventory (name, ingredient_id);
+CREATE INDEX idx_inventory_quantity ON inventory (quantity, ingredient_id);
+
+-- Index for the inventory ledger
+CREATE INDEX idx_inventory_transactions_ingredient ON inventory_transactions (ingredient_id, transaction_id);
+
+-- Every change of an order is recorded in order_events and announced with
+-- NOTIFY, so all app instances stream the same events
+CREATE FUNCTION record_order_event() RETURNS trigger AS $$
+DECLARE
+    target orders%ROWTYPE;
+    kind VARCHAR(50);
+    previous order_status;
+    new_event_id BIGINT;
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        -- Fired at commit, so the order is read again with its items
+        SELECT * INTO target FROM orders WHERE order_id = NEW.order_id;
+        IF NOT FOUND THEN
+            RETURN NULL;
+        END IF;
+        kind := 'order.created';
+    ELSIF TG_OP = 'DELETE' THEN
+        target := OLD;
+        kind := 'order.cancelled';
+    ELSIF NEW.status IS DISTINCT FROM OLD.status THEN
+        target := NEW;
+        previous := OLD.status;
+        kind := CASE WHEN NEW.status = 'cancelled' THEN 'order.cancelled' ELSE 'order.status_changed' END;
+    ELSE
+        target := NEW;
+        kind := 'order.updated';
+    END IF;
+
+    INSERT INTO order_events (order_id, event_type, payload)
+    VALUES (target.order_id, kind, jsonb_build_object(
+        'order_id', target.order_id,
+        'customer_name', target.customer_name,
+        'total_amount', target.total_amount,
+        'status', target.status,
+        'previous_status', previous,
+        'items', COALESCE((
+            SELECT jsonb_agg(jsonb_build_object('product_id', oi.menu_item_id, 'quantity', oi.quantity,
+                   'price_at_order', oi.price_at_order) ORDER BY oi.order_item_id)
+            FROM order_items oi WHERE oi.order_id = target.order_id), '[]'::jsonb)))
+    RETURNING event_id INTO new_event_id;
+
+    PERFORM pg_notify('order_events', new_event_id::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER orders_record_event
+AFTER UPDATE OR DELETE ON orders
+FOR EACH ROW EXECUTE FUNCTION record_order_event();
+
+-- The created event is deferred to the end of the transaction, after the
+-- items of the order are inserted
+CREATE CONSTRAINT TRIGGER orders_record_created_event
+AFTER INSERT ON orders
+DEFERRABLE INITIALLY DEFERRED
+FOR EACH ROW EXECUTE FUNCTION record_order_event();
+
+-- Changes to role permissions are announced with NOTIFY, so every app
+-- instance reloads them
+CREATE FUNCTION notify_role_permissions() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('role_permissions', '');
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER role_permissions_notify
+AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON role_permissions
+FOR EACH STATEMENT EXECUTE FUNCTION notify_role_permissions();
+
+-- A closed drawer session and its Z-report can't be changed or deleted
+CREATE FUNCTION protect_closed_drawer_session() RETURNS trigger AS $$
+BEGIN
+    IF OLD.status = 'closed' THEN
+        RAISE EXCEPTION 'drawer session % is closed and can''t be changed', OLD.session_id;
+    END IF;
+    IF TG_OP = 'DELETE' THEN
+        RETURN OLD;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER drawer_sessions_protect_closed
+BEFORE UPDATE OR DELETE ON drawer_sessions
+FOR EACH ROW EXECUTE FUNCTION protect_closed_drawer_session();
 
+-- The audit log is append-only
+CREATE FUNCTION protect_audit_log() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
 
+CREATE TRIGGER audit_log_append_only
+BEFORE UPDATE OR DELETE ON audit_log
+FOR EACH ROW EXECUTE FUNCTION protect_audit_log();
 
+CREATE TRIGGER audit_log_no_truncate
+BEFORE TRUNCATE ON audit_log
+FOR EACH STATEMENT EXECUTE FUNCTION protect_audit_log();
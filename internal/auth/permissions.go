@@ -0,0 +1,87 @@
+package auth
+
+// Permissions name what a role may do. Every route requires one of them.
+const (
+	PermOrdersRead     = "orders.read"
+	PermOrdersCreate   = "orders.create"
+	PermOrdersUpdate   = "orders.update"
+	PermOrdersDelete   = "orders.delete"
+	PermOrdersOverride = "orders.close_override" // Close an order while a balance is due
+	PermPaymentsCreate = "payments.create"
+	PermPaymentsRefund = "payments.refund"
+	PermCustomersRead  = "customers.read"
+	PermCustomersWrite = "customers.write"
+	PermMenuRead       = "menu.read"
+	PermMenuWrite      = "menu.write"
+	PermPromotionsRead = "promotions.read"
+	PermPromotionsEdit = "promotions.write"
+	PermDrawerRead     = "drawer.read"
+	PermDrawerOperate  = "drawer.operate"
+	PermSettingsRead   = "settings.read"
+	PermSettingsWrite  = "settings.write"
+	PermInventoryRead  = "inventory.read"
+	PermInventoryWrite = "inventory.write"
+	PermInventoryCount = "inventory.count"
+	PermReportsSales   = "reports.sales"
+	PermReportsProfit  = "reports.profit"
+	PermEmployeesRead  = "employees.read"
+	PermEmployeesWrite = "employees.write"
+	PermShiftsRead     = "shifts.read"
+	PermShiftsWrite    = "shifts.write"
+	PermTimeClock      = "time.clock"
+	PermWebhooks       = "webhooks.manage"
+	PermAuditRead      = "audit.read"
+	PermAuthManage     = "auth.manage"
+	PermRolesManage    = "roles.manage"
+)
+
+// Permissions lists every permission in the order they are shown.
+var Permissions = []string{
+	PermOrdersRead, PermOrdersCreate, PermOrdersUpdate, PermOrdersDelete, PermOrdersOverride,
+	PermPaymentsCreate, PermPaymentsRefund,
+	PermCustomersRead, PermCustomersWrite,
+	PermMenuRead, PermMenuWrite,
+	PermPromotionsRead, PermPromotionsEdit,
+	PermDrawerRead, PermDrawerOperate,
+	PermSettingsRead, PermSettingsWrite,
+	PermInventoryRead, PermInventoryWrite, PermInventoryCount,
+	PermReportsSales, PermReportsProfit,
+	PermEmployeesRead, PermEmployeesWrite, PermShiftsRead, PermShiftsWrite, PermTimeClock,
+	PermWebhooks, PermAuditRead, PermAuthManage, PermRolesManage,
+}
+
+// Roles lists the employee roles from the least to the most trusted.
+var Roles = []string{"barista", "shift_lead", "manager", OwnerRole}
+
+// OwnerRole has every permission, whatever the mapping says, so the shop
+// can't lock itself out.
+const OwnerRole = "owner"
+
+// IsPermission reports whether name is a known permission.
+func IsPermission(name string) bool {
+	for _, permission := range Permissions {
+		if permission == name {
+			return true
+		}
+	}
+	return false
+}
+
+// CanManageRole reports whether a caller with the role caller may give role
+// to an employee or change an employee who has it. Only the owner manages
+// owners, and nobody manages a role more trusted than their own.
+func CanManageRole(caller, role string) bool {
+	if caller == OwnerRole {
+		return true
+	}
+	return role != OwnerRole && roleRank(role) <= roleRank(caller)
+}
+
+func roleRank(role string) int {
+	for i, r := range Roles {
+		if r == role {
+			return i
+		}
+	}
+	return -1
+}
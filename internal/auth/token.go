@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// APITokenPrefix marks API tokens, so they are told apart from session
+// tokens and are easy to spot when leaked.
+const APITokenPrefix = "frp_"
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrExpiredToken = errors.New("token has expired")
+)
+
+// NewAPIToken returns a random API token and the hash to store for it. The
+// token itself is shown once and never stored.
+func NewAPIToken() (token, hash string, err error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	token = APITokenPrefix + hex.EncodeToString(secret)
+	return token, HashAPIToken(token), nil
+}
+
+// HashAPIToken returns the hex SHA-256 of a token. API tokens are long and
+// random, so a fast hash is enough to look them up.
+func HashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// SignSession returns a session token "<session id>.<expiry>.<signature>",
+// where the signature is the HMAC-SHA256 of the first two parts.
+func SignSession(secret []byte, sessionID int, expiresAt time.Time) string {
+	payload := strconv.Itoa(sessionID) + "." + strconv.FormatInt(expiresAt.Unix(), 10)
+	return payload + "." + sessionSignature(secret, payload)
+}
+
+// ParseSession checks the signature and expiry of a session token and
+// returns its session id. Revocation is checked against the database.
+func ParseSession(secret []byte, token string, now time.Time) (int, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return 0, ErrInvalidToken
+	}
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(sessionSignature(secret, payload))) {
+		return 0, ErrInvalidToken
+	}
+	sessionID, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, ErrInvalidToken
+	}
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, ErrInvalidToken
+	}
+	if now.Unix() >= expires {
+		return 0, ErrExpiredToken
+	}
+	return sessionID, nil
+}
+
+func sessionSignature(secret []byte, payload string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
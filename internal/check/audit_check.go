@@ -0,0 +1,21 @@
+package check
+
+import (
+	"frappuccino/models"
+)
+
+func Check_AuditEntity(entity string) error {
+	switch entity {
+	case models.AuditMenuItem, models.AuditIngredient, models.AuditOrder:
+		return nil
+	}
+	var errs fieldErrors
+	errs.param("entity", models.CodeInvalidValue, "Invalid entity! Allowed values: menu_item, ingredient, order.")
+	return errs.err()
+}
+
+func Check_AuditAction(action string) error {
+	var errs fieldErrors
+	errs.paramEnum("action", EnumAuditAction, action, "action")
+	return errs.err()
+}
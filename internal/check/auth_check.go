@@ -0,0 +1,48 @@
+package check
+
+import (
+	"frappuccino/models"
+	"strings"
+)
+
+const minPasswordLength = 8
+
+func Check_Login(request models.LoginRequest) error {
+	var errs fieldErrors
+	if strings.TrimSpace(request.Username) == "" {
+		errs.field("/username", models.CodeRequired, "Username is required!")
+	}
+	if request.Password == "" {
+		errs.field("/password", models.CodeRequired, "Password is required!")
+	}
+	return errs.err()
+}
+
+func Check_Credentials(credentials models.Credentials) error {
+	var errs fieldErrors
+	if len(credentials.Username) < 3 || len(credentials.Username) > 100 {
+		errs.field("/username", models.CodeOutOfRange, "Invalid username! Username should be from 3 to 100 characters long!")
+	}
+	for _, c := range credentials.Username {
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '.' || c == '_' || c == '-') {
+			errs.field("/username", models.CodeInvalidValue, "Invalid username! Only lowercase letters, digits and . _ - are allowed!")
+			break
+		}
+	}
+	if len(credentials.Password) < minPasswordLength {
+		errs.field("/password", models.CodeOutOfRange, "Invalid password! Password should be at least 8 characters long!")
+	}
+	return errs.err()
+}
+
+func Check_APIToken(token models.APIToken) error {
+	var errs fieldErrors
+	if strings.TrimSpace(token.Name) == "" {
+		errs.field("/name", models.CodeRequired, "Empty API token name!")
+	}
+	errs.enum("/role", EnumEmployeeRole, token.Role, "role")
+	if token.ExpiresInDays != nil && *token.ExpiresInDays <= 0 {
+		errs.field("/expires_in_days", models.CodeOutOfRange, "Invalid expires_in_days! It should be more than 0!")
+	}
+	return errs.err()
+}
@@ -0,0 +1,48 @@
+package check
+
+import (
+	"frappuccino/models"
+	"strings"
+)
+
+// fieldErrors collects every invalid field of a request, so a validator
+// reports them all instead of stopping at the first.
+type fieldErrors []models.FieldError
+
+// field adds an error for the body field at the JSON pointer.
+func (errs *fieldErrors) field(pointer, code, message string) {
+	*errs = append(*errs, models.FieldError{Pointer: pointer, Code: code, Message: message})
+}
+
+// param adds an error for a query parameter.
+func (errs *fieldErrors) param(parameter, code, message string) {
+	*errs = append(*errs, models.FieldError{Parameter: parameter, Code: code, Message: message})
+}
+
+// enum adds an error for the body field at the JSON pointer unless value is
+// one of the values of the database enum.
+func (errs *fieldErrors) enum(pointer, enum, value, name string) {
+	if !validEnum(enum, value) {
+		errs.field(pointer, models.CodeInvalidValue, invalidEnumMessage(enum, name))
+	}
+}
+
+// paramEnum is enum for a query parameter.
+func (errs *fieldErrors) paramEnum(parameter, enum, value, name string) {
+	if !validEnum(enum, value) {
+		errs.param(parameter, models.CodeInvalidValue, invalidEnumMessage(enum, name))
+	}
+}
+
+// err returns the collected errors as a *models.ValidationError, nil when
+// the request is valid.
+func (errs fieldErrors) err() error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return &models.ValidationError{Fields: errs}
+}
+
+func invalidEnumMessage(enum, name string) string {
+	return "Invalid " + name + "! Allowed values: " + strings.Join(EnumValues(enum), ", ") + "."
+}
@@ -0,0 +1,39 @@
+package check
+
+import (
+	"frappuccino/models"
+	"net/mail"
+	"strings"
+)
+
+func Check_Customer(customer models.Customer) error {
+	var errs fieldErrors
+	if strings.TrimSpace(customer.Name) == "" {
+		errs.field("/name", models.CodeRequired, "Empty customer name!")
+	}
+	if customer.Email != nil {
+		if _, err := mail.ParseAddress(*customer.Email); err != nil {
+			errs.field("/email", models.CodeInvalidValue, "Invalid customer email!")
+		}
+	}
+	if customer.Phone != nil {
+		digits := 0
+		valid := true
+		for _, c := range *customer.Phone {
+			switch {
+			case c >= '0' && c <= '9':
+				digits++
+			case c == '+' || c == ' ' || c == '-' || c == '(' || c == ')':
+			default:
+				valid = false
+			}
+		}
+		switch {
+		case !valid:
+			errs.field("/phone", models.CodeInvalidValue, "Invalid customer phone! Only digits, spaces and + - ( ) are allowed!")
+		case digits < 5:
+			errs.field("/phone", models.CodeInvalidValue, "Invalid customer phone! Too few digits!")
+		}
+	}
+	return errs.err()
+}
@@ -0,0 +1,40 @@
+package check
+
+import (
+	"frappuccino/models"
+	"strings"
+)
+
+func Check_DrawerOpen(session models.DrawerSession) error {
+	var errs fieldErrors
+	if strings.TrimSpace(session.OpenedBy) == "" {
+		errs.field("/opened_by", models.CodeRequired, "Empty opened_by in drawer session!")
+	}
+	if session.OpeningFloat < 0 {
+		errs.field("/opening_float", models.CodeOutOfRange, "Invalid opening float! Opening float can't be less than 0!")
+	}
+	return errs.err()
+}
+
+func Check_DrawerMovement(movement models.DrawerMovement) error {
+	var errs fieldErrors
+	errs.enum("/type", EnumDrawerMovement, movement.Type, "movement type")
+	if movement.Amount <= 0 {
+		errs.field("/amount", models.CodeOutOfRange, "Invalid movement amount! Amount should be more than 0!")
+	}
+	if strings.TrimSpace(movement.Reason) == "" {
+		errs.field("/reason", models.CodeRequired, "Empty movement reason!")
+	}
+	return errs.err()
+}
+
+func Check_DrawerClose(request models.DrawerCloseRequest) error {
+	var errs fieldErrors
+	if request.CountedCash == nil || *request.CountedCash < 0 {
+		errs.field("/counted_cash", models.CodeRequired, "Invalid counted_cash! Counted cash is required and can't be less than 0!")
+	}
+	if strings.TrimSpace(request.ClosedBy) == "" {
+		errs.field("/closed_by", models.CodeRequired, "Empty closed_by in drawer close!")
+	}
+	return errs.err()
+}
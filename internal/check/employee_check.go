@@ -0,0 +1,52 @@
+package check
+
+import (
+	"frappuccino/models"
+	"net/mail"
+	"strings"
+)
+
+func Check_Employee(employee models.Employee) error {
+	var errs fieldErrors
+	if strings.TrimSpace(employee.Name) == "" {
+		errs.field("/name", models.CodeRequired, "Empty employee name!")
+	}
+	errs.enum("/role", EnumEmployeeRole, employee.Role, "role")
+	if employee.HourlyRate < 0 {
+		errs.field("/hourly_rate", models.CodeOutOfRange, "Invalid hourly rate! Hourly rate can't be less than 0!")
+	}
+	if employee.Email != nil {
+		if _, err := mail.ParseAddress(*employee.Email); err != nil {
+			errs.field("/email", models.CodeInvalidValue, "Invalid employee email!")
+		}
+	}
+	return errs.err()
+}
+
+// Check_Role checks a role from the path of the request.
+func Check_Role(role string) error {
+	var errs fieldErrors
+	errs.paramEnum("role", EnumEmployeeRole, role, "role")
+	return errs.err()
+}
+
+func Check_Shift(shift models.Shift) error {
+	var errs fieldErrors
+	if shift.EmployeeID <= 0 {
+		errs.field("/employee_id", models.CodeRequired, "Invalid employee_id in shift!")
+	}
+	if shift.StartsAt.IsZero() {
+		errs.field("/starts_at", models.CodeRequired, "Shift starts_at is required!")
+	}
+	if shift.EndsAt.IsZero() {
+		errs.field("/ends_at", models.CodeRequired, "Shift ends_at is required!")
+	}
+	switch {
+	case shift.StartsAt.IsZero() || shift.EndsAt.IsZero():
+	case !shift.EndsAt.After(shift.StartsAt):
+		errs.field("/ends_at", models.CodeOutOfRange, "Invalid shift! ends_at should be after starts_at!")
+	case shift.EndsAt.Sub(shift.StartsAt).Hours() > 24:
+		errs.field("/ends_at", models.CodeOutOfRange, "Invalid shift! A shift can't be longer than 24 hours!")
+	}
+	return errs.err()
+}
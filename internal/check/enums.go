@@ -0,0 +1,50 @@
+package check
+
+import "slices"
+
+// Database enums checked by the validators.
+const (
+	EnumOrderStatus       = "order_status"
+	EnumUnit              = "unit_of_measurement"
+	EnumTransactionType   = "type_of_transaction"
+	EnumTransactionReason = "transaction_reason"
+	EnumPromotionType     = "promotion_type"
+	EnumOrderType         = "order_type"
+	EnumPaymentMethod     = "payment_method"
+	EnumDrawerMovement    = "drawer_movement_type"
+	EnumEmployeeRole      = "employee_role"
+	EnumAuditAction       = "audit_action"
+)
+
+// enums holds the values of the database enums. The defaults match init.sql
+// and are replaced by what the database has with SetEnums at startup, so the
+// validators accept exactly what the columns do.
+var enums = map[string][]string{
+	EnumOrderStatus:       {"accepted", "pending", "processing", "ready", "completed", "cancelled", "rejected"},
+	EnumUnit:              {"mg", "g", "kg", "oz", "lb", "ml", "l", "dl", "fl", "pc", "dozen", "cup", "tsp", "tbsp", "shots"},
+	EnumTransactionType:   {"addition", "deduction"},
+	EnumTransactionReason: {"opening_balance", "restock", "manual_adjustment", "order", "count_correction"},
+	EnumPromotionType:     {"percentage", "fixed", "buy_x_get_y"},
+	EnumOrderType:         {"dine_in", "takeaway"},
+	EnumPaymentMethod:     {"cash", "card", "voucher"},
+	EnumDrawerMovement:    {"cash_in", "cash_out"},
+	EnumEmployeeRole:      {"barista", "shift_lead", "manager", "owner"},
+	EnumAuditAction:       {"create", "update", "delete"},
+}
+
+// SetEnums replaces the values of the enums found in values, keyed by the
+// enum type name. It must be called before the server starts.
+func SetEnums(values map[string][]string) {
+	for enum, enumValues := range values {
+		enums[enum] = enumValues
+	}
+}
+
+// EnumValues returns the values of a database enum, in their order.
+func EnumValues(enum string) []string {
+	return enums[enum]
+}
+
+func validEnum(enum, value string) bool {
+	return slices.Contains(enums[enum], value)
+}
@@ -1,41 +1,43 @@
 package check
 
 import (
-	"frappuccino/internal/utils"
 	"frappuccino/models"
-	"net/http"
 )
 
-func Check_Inventory(w http.ResponseWriter, r *http.Request, ingredient models.InventoryItem) bool {
+func Check_Inventory(ingredient models.InventoryItem) error {
+	var errs fieldErrors
 	if ingredient.Name == "" {
-		utils.SendError(w, utils.StatusBadRequest, "Empty ingredient name in inventory items!")
-		return false
+		errs.field("/name", models.CodeRequired, "Empty ingredient name in inventory items!")
 	}
 	if ingredient.Quantity <= 0 {
-		utils.SendError(w, utils.StatusBadRequest, "Invalid quantity in inventory items! Quantity should be more than 0!")
-		return false
+		errs.field("/quantity", models.CodeOutOfRange, "Invalid quantity in inventory items! Quantity should be more than 0!")
 	}
 	if ingredient.Unit == "" {
-		utils.SendError(w, utils.StatusBadRequest, "Empty ingredient unit in inventory items! Please specify (mg/g/kg/oz/lb/ml/l/dl/fl oz/pc/dozen/cup/tsp/tbsp/shots)!")
-		return false
+		errs.field("/unit", models.CodeRequired, "Empty ingredient unit in inventory items!")
+	} else {
+		errs.enum("/unit", EnumUnit, ingredient.Unit, "unit of measurement")
 	}
 	if ingredient.Price <= 0 {
-		utils.SendError(w, utils.StatusBadRequest, "Invalid price in inventory items! Price should be more than 0!")
-		return false
+		errs.field("/price", models.CodeOutOfRange, "Invalid price in inventory items! Price should be more than 0!")
 	}
-	if !CheckUnit(ingredient.Unit) {
-		utils.SendError(w, utils.StatusBadRequest, "Invalid unit of measurement! Please specify (mg/g/kg/oz/lb/ml/l/dl/fl oz/pc/dozen/cup/tsp/tbsp/shots)!")
-		return false
-	}
-	return true
+	return errs.err()
 }
 
-func CheckUnit(unit string) bool {
-	units := []string{"mg", "g", "kg", "oz", "lb", "ml", "l", "cl", "dl", "fl oz", "pc", "dozen", "cup", "tsp", "tbsp", "shots"}
-	for _, u := range units {
-		if u == unit {
-			return true
-		}
-	}
-	return false
+// Check_Unit checks a unit from the unit query parameter.
+func Check_Unit(unit string) error {
+	var errs fieldErrors
+	errs.paramEnum("unit", EnumUnit, unit, "unit of measurement")
+	return errs.err()
+}
+
+func Check_TransactionType(transactionType string) error {
+	var errs fieldErrors
+	errs.paramEnum("type", EnumTransactionType, transactionType, "transaction type")
+	return errs.err()
+}
+
+func Check_TransactionReason(reason string) error {
+	var errs fieldErrors
+	errs.paramEnum("reason", EnumTransactionReason, reason, "transaction reason")
+	return errs.err()
 }
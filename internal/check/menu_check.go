@@ -1,31 +1,39 @@
 package check
 
 import (
-	"frappuccino/internal/utils"
 	"frappuccino/models"
-	"net/http"
+	"strconv"
 )
 
-func Check_Menu(w http.ResponseWriter, r *http.Request, item models.MenuItem) bool {
+func Check_Menu(item models.MenuItem) error {
+	var errs fieldErrors
 	if item.Name == "" {
-		utils.SendError(w, utils.StatusBadRequest, "Empty menu name in menu items!")
-		return false
+		errs.field("/name", models.CodeRequired, "Empty menu name in menu items!")
 	}
 	if item.Description == "" {
-		utils.SendError(w, utils.StatusBadRequest, "Empty menu description in menu items!")
-		return false
+		errs.field("/description", models.CodeRequired, "Empty menu description in menu items!")
 	}
 	if item.Price <= 0 {
-		utils.SendError(w, utils.StatusBadRequest, "Menu item can't be less than 0 in menu items!")
-		return false
+		errs.field("/price", models.CodeOutOfRange, "Menu item can't be less than 0 in menu items!")
 	}
 	if len(item.Category) == 0 {
-		utils.SendError(w, utils.StatusBadRequest, "Empty menu category in menu items!")
-		return false
+		errs.field("/category", models.CodeRequired, "Empty menu category in menu items!")
 	}
 	if len(item.Ingredients) == 0 {
-		utils.SendError(w, utils.StatusBadRequest, "Empty ingredient list in menu items!")
-		return false
+		errs.field("/ingredients", models.CodeRequired, "Empty ingredient list in menu items!")
 	}
-	return true
+	seen := make(map[int]bool)
+	for i, ingredient := range item.Ingredients {
+		pointer := "/ingredients/" + strconv.Itoa(i)
+		if ingredient.IngredientID <= 0 {
+			errs.field(pointer+"/ingredient_id", models.CodeRequired, "Invalid ingredient_id in menu item ingredients!")
+		} else if seen[ingredient.IngredientID] {
+			errs.field(pointer+"/ingredient_id", models.CodeInvalidValue, "Ingredient is listed twice in menu item ingredients!")
+		}
+		seen[ingredient.IngredientID] = true
+		if ingredient.Quantity <= 0 {
+			errs.field(pointer+"/quantity", models.CodeOutOfRange, "Invalid quantity in menu item ingredients! Quantity should be more than 0!")
+		}
+	}
+	return errs.err()
 }
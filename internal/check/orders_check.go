@@ -1,44 +1,94 @@
 package check
 
 import (
-	"frappuccino/internal/utils"
 	"frappuccino/models"
-	"net/http"
+	"strconv"
 	"time"
 )
 
-func Check_Orders(w http.ResponseWriter, r *http.Request, orders models.Order) bool {
-	if orders.CustomerName == "" {
-		utils.SendError(w, utils.StatusBadRequest, "Empty Customer name in orders!")
-		return false
+func Check_Orders(order models.Order) error {
+	var errs fieldErrors
+	checkOrder(&errs, "", order, false)
+	return errs.err()
+}
+
+// Check_OrderUpdate checks the body of an order update like a new order,
+// except that an item with quantity 0 is removed. The status can only be set
+// to pending or accepted; the queue, close and delete endpoints move an
+// order further.
+func Check_OrderUpdate(order models.Order) error {
+	var errs fieldErrors
+	checkOrder(&errs, "", order, true)
+	switch order.Status {
+	case "", "pending", "accepted":
+	case "processing", "ready":
+		errs.field("/status", models.CodeInvalidValue, "Orders are moved to processing and ready through the queue!")
+	case "completed":
+		errs.field("/status", models.CodeInvalidValue, "Orders are completed with POST /orders/{id}/close!")
+	case "cancelled", "rejected":
+		errs.field("/status", models.CodeInvalidValue, "Orders are cancelled with DELETE /orders/{id}!")
+	default:
+		errs.enum("/status", EnumOrderStatus, order.Status, "order status")
 	}
-	if orders.TotalAmount < 0 {
-		utils.SendError(w, utils.StatusBadRequest, "Invalid total amount in orders! Total amount should be more than 0!")
-		return false
+	return errs.err()
+}
+
+// Check_BulkOrders checks every order of a batch, reporting the fields of
+// each under /orders/{i}.
+func Check_BulkOrders(orders []models.Order) error {
+	var errs fieldErrors
+	if len(orders) == 0 {
+		errs.field("/orders", models.CodeRequired, "Empty list of orders!")
 	}
-	for i := range orders.Items {
-		Check_OrderItem(w, r, orders.Items[i])
+	for i, order := range orders {
+		checkOrder(&errs, "/orders/"+strconv.Itoa(i), order, false)
 	}
+	return errs.err()
+}
 
-	if len(orders.Items) <= 0 {
-
-		utils.SendError(w, utils.StatusBadRequest, "Invalid quantity in items! Quantity should be more than 0!")
-		return false
+func checkOrder(errs *fieldErrors, pointer string, order models.Order, update bool) {
+	if order.CustomerName == "" && order.CustomerID == nil {
+		errs.field(pointer+"/customer_name", models.CodeRequired, "Empty Customer name in orders!")
+	}
+	if order.RedeemPoints < 0 {
+		errs.field(pointer+"/redeem_points", models.CodeOutOfRange, "Invalid redeem points in orders! Points can't be less than 0!")
+	}
+	if order.RedeemPoints > 0 && order.CustomerID == nil {
+		errs.field(pointer+"/customer_id", models.CodeRequired, "Loyalty points can be redeemed only by a customer! Please specify customer_id!")
+	}
+	if order.OrderType != "" {
+		errs.enum(pointer+"/order_type", EnumOrderType, order.OrderType, "order type")
+	}
+	if order.TotalAmount < 0 {
+		errs.field(pointer+"/total_amount", models.CodeOutOfRange, "Invalid total amount in orders! Total amount should be more than 0!")
+	}
+	if len(order.Items) == 0 {
+		errs.field(pointer+"/items", models.CodeRequired, "Empty list of items in orders!")
+	}
+	for i, item := range order.Items {
+		itemPointer := pointer + "/items/" + strconv.Itoa(i)
+		if item.ProductID <= 0 {
+			errs.field(itemPointer+"/product_id", models.CodeRequired, "Invalid product_id in items!")
+		}
+		if item.Quantity < 0 || item.Quantity == 0 && !update {
+			errs.field(itemPointer+"/quantity", models.CodeOutOfRange, "Invalid quantity in items! Quantity should be more than 0!")
+		}
 	}
-	return true
 }
 
-func Check_OrderItem(w http.ResponseWriter, r *http.Request, orderItem models.OrderItem) bool {
-	if orderItem.Quantity <= 0 {
-		utils.SendError(w, utils.StatusBadRequest, "Invalid quantity in items! Quantity should be more than 0!")
-		return false
-	}
-	return true
+// Check_OrderStatus checks a status from the status query parameter.
+func Check_OrderStatus(status string) error {
+	var errs fieldErrors
+	errs.paramEnum("status", EnumOrderStatus, status, "order status")
+	return errs.err()
 }
 
-func Check_Date(w http.ResponseWriter, r *http.Request, startDate, endDate string) (*string, *string, bool) {
+// Check_Date checks the startDate and endDate query parameters and returns
+// the ones that are set.
+func Check_Date(startDate, endDate string) (*string, *string, error) {
 	const dateFormat = "2006-01-02" // Формат YYYY-MM-DD
 
+	var errs fieldErrors
 	var startDatePtr, endDatePtr *string
 	var start, end time.Time
 	var err error
@@ -46,38 +96,39 @@ func Check_Date(w http.ResponseWriter, r *http.Request, startDate, endDate strin
 	if startDate != "" {
 		start, err = time.Parse(dateFormat, startDate)
 		if err != nil {
-			utils.SendError(w, utils.StatusBadRequest, "Invalid 'startDate' format. Use 'YYYY-MM-DD'.")
-			return nil, nil, false
+			errs.param("startDate", models.CodeInvalidValue, "Invalid 'startDate' format. Use 'YYYY-MM-DD'.")
+		} else {
+			startDatePtr = &startDate
 		}
-		startDatePtr = &startDate
 	}
 
 	if endDate != "" {
 		end, err = time.Parse(dateFormat, endDate)
 		if err != nil {
-			utils.SendError(w, utils.StatusBadRequest, "Invalid 'endDate' format. Use 'YYYY-MM-DD'.")
-			return nil, nil, false
+			errs.param("endDate", models.CodeInvalidValue, "Invalid 'endDate' format. Use 'YYYY-MM-DD'.")
+		} else {
+			endDatePtr = &endDate
 		}
-		endDatePtr = &endDate
 	}
 
 	if startDatePtr != nil && endDatePtr != nil && start.After(end) {
-		utils.SendError(w, utils.StatusBadRequest, "'startDate' cannot be later than 'endDate'.")
-		return nil, nil, false
+		errs.param("startDate", models.CodeOutOfRange, "'startDate' cannot be later than 'endDate'.")
 	}
 
-	return startDatePtr, endDatePtr, true
+	if err := errs.err(); err != nil {
+		return nil, nil, err
+	}
+	return startDatePtr, endDatePtr, nil
 }
 
-func Check_OrderItemheckFilters(w http.ResponseWriter, r *http.Request, filters []string) bool {
+func Check_OrderItemheckFilters(filters []string) error {
 	validFilters := map[string]bool{"menu": true, "orders": true, "all": true}
 
+	var errs fieldErrors
 	for _, f := range filters {
 		if !validFilters[f] {
-			utils.SendError(w, utils.StatusBadRequest, "Invalid filter: "+f+". Allowed values: menu, order, all.")
-			return false
+			errs.param("filter", models.CodeInvalidValue, "Invalid filter: "+f+". Allowed values: menu, order, all.")
 		}
 	}
-
-	return true
+	return errs.err()
 }
@@ -0,0 +1,35 @@
+package check
+
+import (
+	"frappuccino/models"
+	"strings"
+)
+
+func Check_Payment(payment models.Payment) error {
+	var errs fieldErrors
+	errs.enum("/method", EnumPaymentMethod, payment.Method, "payment method")
+	if payment.Amount <= 0 {
+		errs.field("/amount", models.CodeOutOfRange, "Invalid payment amount! Amount should be more than 0!")
+	}
+	if payment.Tip < 0 {
+		errs.field("/tip", models.CodeOutOfRange, "Invalid tip! Tip can't be less than 0!")
+	}
+	if payment.Tendered != nil && payment.Method != "cash" {
+		errs.field("/tendered", models.CodeInvalidValue, "Tendered amount is only for cash payments!")
+	}
+	return errs.err()
+}
+
+func Check_Refund(refund models.Refund) error {
+	var errs fieldErrors
+	if refund.PaymentID <= 0 {
+		errs.field("/payment_id", models.CodeRequired, "Invalid payment_id in refund!")
+	}
+	if refund.Amount <= 0 {
+		errs.field("/amount", models.CodeOutOfRange, "Invalid refund amount! Amount should be more than 0!")
+	}
+	if strings.TrimSpace(refund.Reason) == "" {
+		errs.field("/reason", models.CodeRequired, "Empty refund reason!")
+	}
+	return errs.err()
+}
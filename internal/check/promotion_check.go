@@ -0,0 +1,61 @@
+package check
+
+import (
+	"frappuccino/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func Check_Promotion(promotion models.Promotion) error {
+	var errs fieldErrors
+	if strings.TrimSpace(promotion.Name) == "" {
+		errs.field("/name", models.CodeRequired, "Empty promotion name!")
+	}
+	errs.enum("/type", EnumPromotionType, promotion.Type, "promotion type")
+	if promotion.Type == "buy_x_get_y" {
+		if promotion.BuyQuantity == nil || *promotion.BuyQuantity <= 0 {
+			errs.field("/buy_quantity", models.CodeRequired, "Buy X get Y promotion needs buy_quantity more than 0!")
+		}
+		if promotion.GetQuantity == nil || *promotion.GetQuantity <= 0 {
+			errs.field("/get_quantity", models.CodeRequired, "Buy X get Y promotion needs get_quantity more than 0!")
+		}
+	}
+	if promotion.Value <= 0 {
+		errs.field("/value", models.CodeOutOfRange, "Invalid promotion value! Value should be more than 0!")
+	} else if promotion.Type != "fixed" && promotion.Value > 100 {
+		errs.field("/value", models.CodeOutOfRange, "Invalid promotion value! Percentage can't be more than 100!")
+	}
+	if promotion.MinSubtotal < 0 {
+		errs.field("/min_subtotal", models.CodeOutOfRange, "Invalid min_subtotal! It can't be less than 0!")
+	}
+	if promotion.StartsAt != nil && promotion.EndsAt != nil && !promotion.StartsAt.Before(*promotion.EndsAt) {
+		errs.field("/ends_at", models.CodeOutOfRange, "'starts_at' should be earlier than 'ends_at'!")
+	}
+	for i, day := range promotion.DaysOfWeek {
+		if day < 1 || day > 7 {
+			errs.field("/days_of_week/"+strconv.Itoa(i), models.CodeOutOfRange, "Invalid days_of_week! Use 1 (Monday) to 7 (Sunday)!")
+		}
+	}
+	if promotion.StartTime == nil && promotion.EndTime != nil {
+		errs.field("/start_time", models.CodeRequired, "Both start_time and end_time should be set!")
+	}
+	if promotion.EndTime == nil && promotion.StartTime != nil {
+		errs.field("/end_time", models.CodeRequired, "Both start_time and end_time should be set!")
+	}
+	checkTimeOfDay(&errs, "/start_time", promotion.StartTime)
+	checkTimeOfDay(&errs, "/end_time", promotion.EndTime)
+	if promotion.UsageLimit != nil && *promotion.UsageLimit <= 0 {
+		errs.field("/usage_limit", models.CodeOutOfRange, "Invalid usage_limit! It should be more than 0!")
+	}
+	return errs.err()
+}
+
+func checkTimeOfDay(errs *fieldErrors, pointer string, t *string) {
+	if t == nil {
+		return
+	}
+	if _, err := time.Parse("15:04", *t); err != nil {
+		errs.field(pointer, models.CodeInvalidValue, "Invalid time of day! Use 'HH:MM'.")
+	}
+}
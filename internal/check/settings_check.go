@@ -0,0 +1,35 @@
+package check
+
+import (
+	"frappuccino/models"
+	"sort"
+)
+
+func Check_Settings(settings map[string]string) error {
+	var errs fieldErrors
+	if len(settings) == 0 {
+		errs.field("", models.CodeRequired, "Empty list of settings!")
+		return errs.err()
+	}
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := settings[key]
+		switch key {
+		case "tax_inclusive_pricing":
+			if value != "true" && value != "false" {
+				errs.field("/"+key, models.CodeInvalidValue, "Invalid tax_inclusive_pricing! Allowed values: true, false.")
+			}
+		case "receipt_header", "receipt_footer":
+			if len(value) > 500 {
+				errs.field("/"+key, models.CodeOutOfRange, "Too long "+key+"! At most 500 characters.")
+			}
+		default:
+			errs.field("/"+key, models.CodeUnknownField, "Unknown setting: "+key+".")
+		}
+	}
+	return errs.err()
+}
@@ -0,0 +1,28 @@
+package check
+
+import (
+	"frappuccino/models"
+	"strconv"
+)
+
+func Check_StockCountLines(lines []models.StockCountSubmission) error {
+	var errs fieldErrors
+	if len(lines) == 0 {
+		errs.field("/lines", models.CodeRequired, "Empty list of counted ingredients!")
+		return errs.err()
+	}
+	seen := make(map[int]bool)
+	for i, line := range lines {
+		pointer := "/lines/" + strconv.Itoa(i)
+		if line.IngredientID <= 0 {
+			errs.field(pointer+"/ingredient_id", models.CodeRequired, "Invalid ingredient id in counted ingredients!")
+		} else if seen[line.IngredientID] {
+			errs.field(pointer+"/ingredient_id", models.CodeInvalidValue, "Ingredient is counted twice in one submission!")
+		}
+		if line.CountedQuantity < 0 {
+			errs.field(pointer+"/counted_quantity", models.CodeOutOfRange, "Invalid counted quantity! Quantity can't be less than 0!")
+		}
+		seen[line.IngredientID] = true
+	}
+	return errs.err()
+}
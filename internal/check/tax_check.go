@@ -0,0 +1,20 @@
+package check
+
+import (
+	"frappuccino/models"
+	"strings"
+)
+
+func Check_TaxRate(rate models.TaxRate) error {
+	var errs fieldErrors
+	if strings.TrimSpace(rate.Name) == "" {
+		errs.field("/name", models.CodeRequired, "Empty tax rate name!")
+	}
+	if rate.Rate < 0 || rate.Rate > 100 {
+		errs.field("/rate", models.CodeOutOfRange, "Invalid tax rate! Rate is a percentage from 0 to 100!")
+	}
+	if rate.OrderType != nil {
+		errs.enum("/order_type", EnumOrderType, *rate.OrderType, "order type")
+	}
+	return errs.err()
+}
@@ -0,0 +1,33 @@
+package check
+
+import (
+	"frappuccino/models"
+	"net/url"
+	"strconv"
+)
+
+var webhookEventTypes = []string{"*", "order.created", "order.ready", "order.completed", "order.cancelled", "inventory.stockout"}
+
+func Check_WebhookSubscription(sub models.WebhookSubscription) error {
+	var errs fieldErrors
+	parsed, err := url.Parse(sub.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		errs.field("/url", models.CodeInvalidValue, "Invalid webhook url! Absolute http or https url is required!")
+	}
+	if len(sub.EventTypes) == 0 {
+		errs.field("/event_types", models.CodeRequired, "Empty list of webhook event types!")
+	}
+	for i, eventType := range sub.EventTypes {
+		valid := false
+		for _, v := range webhookEventTypes {
+			if v == eventType {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			errs.field("/event_types/"+strconv.Itoa(i), models.CodeInvalidValue, "Invalid webhook event type! Allowed values: *, order.created, order.ready, order.completed, order.cancelled, inventory.stockout.")
+		}
+	}
+	return errs.err()
+}
@@ -0,0 +1,297 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileEnv names the variable holding the path of the config file, when the
+// --config flag isn't given.
+const FileEnv = "FRAPPUCCINO_CONFIG"
+
+type Config struct {
+	Port    string
+	LogFile string
+
+	DB DBConfig
+
+	AuthSecret     string
+	CORSOrigins    []string
+	RequestTimeout time.Duration
+
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	ShutdownTimeout   time.Duration
+}
+
+type DBConfig struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+	Name     string
+	SSLMode  string
+
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// DSN returns the connection string of the database for lib/pq.
+func (db DBConfig) DSN() string {
+	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		quote(db.Host), db.Port, quote(db.User), quote(db.Password), quote(db.Name), quote(db.SSLMode))
+}
+
+func quote(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
+
+func defaults() Config {
+	return Config{
+		Port:    "8080",
+		LogFile: "/log.log",
+		DB: DBConfig{
+			Host:            "db",
+			Port:            5432,
+			User:            "latte",
+			Password:        "latte",
+			Name:            "frappuccino",
+			SSLMode:         "disable",
+			MaxOpenConns:    25,
+			MaxIdleConns:    25,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
+		},
+		RequestTimeout:    30 * time.Second,
+		ReadTimeout:       15 * time.Second,
+		ReadHeaderTimeout: 5 * time.Second,
+		WriteTimeout:      60 * time.Second,
+		IdleTimeout:       2 * time.Minute,
+		ShutdownTimeout:   30 * time.Second,
+	}
+}
+
+// option ties a flag to the variable that sets it in the environment and
+// in the config file.
+type option struct {
+	flag string
+	env  string
+}
+
+var options = []option{
+	{"port", "FRAPPUCCINO_PORT"},
+	{"log-file", "FRAPPUCCINO_LOG_FILE"},
+	{"db-host", "DB_HOST"},
+	{"db-port", "DB_PORT"},
+	{"db-user", "DB_USER"},
+	{"db-password", "DB_PASSWORD"},
+	{"db-name", "DB_NAME"},
+	{"db-sslmode", "DB_SSLMODE"},
+	{"db-max-open-conns", "DB_MAX_OPEN_CONNS"},
+	{"db-max-idle-conns", "DB_MAX_IDLE_CONNS"},
+	{"db-conn-max-lifetime", "DB_CONN_MAX_LIFETIME"},
+	{"db-conn-max-idle-time", "DB_CONN_MAX_IDLE_TIME"},
+	{"auth-secret", "FRAPPUCCINO_AUTH_SECRET"},
+	{"cors-origins", "FRAPPUCCINO_CORS_ORIGINS"},
+	{"request-timeout", "FRAPPUCCINO_REQUEST_TIMEOUT"},
+	{"read-timeout", "FRAPPUCCINO_READ_TIMEOUT"},
+	{"read-header-timeout", "FRAPPUCCINO_READ_HEADER_TIMEOUT"},
+	{"write-timeout", "FRAPPUCCINO_WRITE_TIMEOUT"},
+	{"idle-timeout", "FRAPPUCCINO_IDLE_TIMEOUT"},
+	{"shutdown-timeout", "FRAPPUCCINO_SHUTDOWN_TIMEOUT"},
+}
+
+// Load reads the configuration from the command line arguments, the
+// environment and the config file, in that order of precedence, on top of
+// the defaults. It returns flag.ErrHelp when --help is given.
+func Load(args []string) (Config, error) {
+	cfg := defaults()
+	var file string
+
+	fs := flag.NewFlagSet("frappuccino", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&file, "config", os.Getenv(FileEnv), "")
+	fs.StringVar(&cfg.Port, "port", cfg.Port, "")
+	fs.StringVar(&cfg.LogFile, "log-file", cfg.LogFile, "")
+	fs.StringVar(&cfg.DB.Host, "db-host", cfg.DB.Host, "")
+	fs.IntVar(&cfg.DB.Port, "db-port", cfg.DB.Port, "")
+	fs.StringVar(&cfg.DB.User, "db-user", cfg.DB.User, "")
+	fs.StringVar(&cfg.DB.Password, "db-password", cfg.DB.Password, "")
+	fs.StringVar(&cfg.DB.Name, "db-name", cfg.DB.Name, "")
+	fs.StringVar(&cfg.DB.SSLMode, "db-sslmode", cfg.DB.SSLMode, "")
+	fs.IntVar(&cfg.DB.MaxOpenConns, "db-max-open-conns", cfg.DB.MaxOpenConns, "")
+	fs.IntVar(&cfg.DB.MaxIdleConns, "db-max-idle-conns", cfg.DB.MaxIdleConns, "")
+	fs.DurationVar(&cfg.DB.ConnMaxLifetime, "db-conn-max-lifetime", cfg.DB.ConnMaxLifetime, "")
+	fs.DurationVar(&cfg.DB.ConnMaxIdleTime, "db-conn-max-idle-time", cfg.DB.ConnMaxIdleTime, "")
+	fs.StringVar(&cfg.AuthSecret, "auth-secret", cfg.AuthSecret, "")
+	fs.Func("cors-origins", "", func(value string) error {
+		cfg.CORSOrigins = splitList(value)
+		return nil
+	})
+	fs.DurationVar(&cfg.RequestTimeout, "request-timeout", cfg.RequestTimeout, "")
+	fs.DurationVar(&cfg.ReadTimeout, "read-timeout", cfg.ReadTimeout, "")
+	fs.DurationVar(&cfg.ReadHeaderTimeout, "read-header-timeout", cfg.ReadHeaderTimeout, "")
+	fs.DurationVar(&cfg.WriteTimeout, "write-timeout", cfg.WriteTimeout, "")
+	fs.DurationVar(&cfg.IdleTimeout, "idle-timeout", cfg.IdleTimeout, "")
+	fs.DurationVar(&cfg.ShutdownTimeout, "shutdown-timeout", cfg.ShutdownTimeout, "")
+	if err := fs.Parse(args); err != nil {
+		return cfg, err
+	}
+	if fs.NArg() > 0 {
+		return cfg, fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+
+	values := make(map[string]string)
+	if file != "" {
+		fileValues, err := readFile(file)
+		if err != nil {
+			return cfg, err
+		}
+		values = fileValues
+	}
+	for _, opt := range options {
+		if value, ok := os.LookupEnv(opt.env); ok {
+			values[opt.env] = value
+		}
+	}
+
+	// Flags given on the command line win over the environment and the file
+	given := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { given[f.Name] = true })
+	var errs []error
+	for _, opt := range options {
+		value, ok := values[opt.env]
+		if !ok || given[opt.flag] {
+			continue
+		}
+		if err := fs.Set(opt.flag, value); err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s %q: %v", opt.env, value, err))
+		}
+	}
+	if len(errs) > 0 {
+		return cfg, errors.Join(errs...)
+	}
+
+	return cfg, cfg.validate()
+}
+
+// readFile reads a config file of KEY=value lines, with the keys of the
+// environment variables. Empty lines and lines starting with # are skipped.
+func readFile(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer file.Close()
+
+	known := make(map[string]bool, len(options))
+	for _, opt := range options {
+		known[opt.env] = true
+	}
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected KEY=value", path, lineNumber)
+		}
+		if !known[key] {
+			return nil, fmt.Errorf("%s:%d: unknown key %s", path, lineNumber, key)
+		}
+		value = strings.TrimSpace(value)
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		}
+		values[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	return values, nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// validate reports every invalid setting at once.
+func (cfg Config) validate() error {
+	var errs []error
+	if port, err := strconv.Atoi(cfg.Port); err != nil || port < 1 || port > 65535 {
+		errs = append(errs, fmt.Errorf("invalid port %q, expected a number from 1 to 65535", cfg.Port))
+	}
+	if cfg.LogFile == "" {
+		errs = append(errs, errors.New("empty log file path"))
+	}
+	if cfg.DB.Host == "" {
+		errs = append(errs, errors.New("empty database host"))
+	}
+	if cfg.DB.Port < 1 || cfg.DB.Port > 65535 {
+		errs = append(errs, fmt.Errorf("invalid database port %d", cfg.DB.Port))
+	}
+	if cfg.DB.User == "" {
+		errs = append(errs, errors.New("empty database user"))
+	}
+	if cfg.DB.Name == "" {
+		errs = append(errs, errors.New("empty database name"))
+	}
+	switch cfg.DB.SSLMode {
+	case "disable", "require", "verify-ca", "verify-full":
+	default:
+		errs = append(errs, fmt.Errorf("invalid database sslmode %q, expected disable, require, verify-ca or verify-full", cfg.DB.SSLMode))
+	}
+	if cfg.DB.MaxOpenConns < 0 {
+		errs = append(errs, errors.New("database max open connections can't be negative, 0 means no limit"))
+	}
+	if cfg.DB.MaxIdleConns < 0 {
+		errs = append(errs, errors.New("database max idle connections can't be negative"))
+	}
+	if cfg.DB.MaxOpenConns > 0 && cfg.DB.MaxIdleConns > cfg.DB.MaxOpenConns {
+		errs = append(errs, errors.New("database max idle connections can't be more than max open connections"))
+	}
+	for _, d := range []struct {
+		name     string
+		value    time.Duration
+		positive bool
+	}{
+		{"database connection max lifetime", cfg.DB.ConnMaxLifetime, false},
+		{"database connection max idle time", cfg.DB.ConnMaxIdleTime, false},
+		{"request timeout", cfg.RequestTimeout, true},
+		{"read timeout", cfg.ReadTimeout, false},
+		{"read header timeout", cfg.ReadHeaderTimeout, false},
+		{"write timeout", cfg.WriteTimeout, false},
+		{"idle timeout", cfg.IdleTimeout, false},
+		{"shutdown timeout", cfg.ShutdownTimeout, true},
+	} {
+		if d.value < 0 || d.positive && d.value == 0 {
+			errs = append(errs, fmt.Errorf("invalid %s %s", d.name, d.value))
+		}
+	}
+	if cfg.WriteTimeout > 0 && cfg.WriteTimeout < cfg.RequestTimeout {
+		errs = append(errs, fmt.Errorf("write timeout %s is shorter than the request timeout %s", cfg.WriteTimeout, cfg.RequestTimeout))
+	}
+	return errors.Join(errs...)
+}
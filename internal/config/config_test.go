@@ -0,0 +1,150 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clearEnv unsets every variable Load reads for the duration of the test.
+func clearEnv(t *testing.T) {
+	t.Helper()
+	for _, name := range append([]string{FileEnv}, envNames()...) {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+}
+
+func envNames() []string {
+	names := make([]string, len(options))
+	for i, opt := range options {
+		names[i] = opt.env
+	}
+	return names
+}
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "frappuccino.env")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadDefaults(t *testing.T) {
+	clearEnv(t)
+
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !reflect.DeepEqual(cfg, defaults()) {
+		t.Errorf("Load() = %+v, want the defaults", cfg)
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	clearEnv(t)
+	file := writeConfig(t, `# set in the file only, then overridden one level at a time
+FRAPPUCCINO_PORT=7000
+DB_HOST=file-host
+DB_USER=file-user
+DB_NAME="file db"
+FRAPPUCCINO_SHUTDOWN_TIMEOUT=10s
+`)
+	t.Setenv("DB_HOST", "env-host")
+	t.Setenv("DB_USER", "env-user")
+
+	cfg, err := Load([]string{"--config", file, "--db-user", "flag-user"})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{"flag over env and file", cfg.DB.User, "flag-user"},
+		{"env over file", cfg.DB.Host, "env-host"},
+		{"file over default", cfg.Port, "7000"},
+		{"quoted file value", cfg.DB.Name, "file db"},
+		{"file duration", cfg.ShutdownTimeout, 10 * time.Second},
+		{"default", cfg.DB.Password, "latte"},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestLoadConfigFileFromEnv(t *testing.T) {
+	clearEnv(t)
+	t.Setenv(FileEnv, writeConfig(t, "FRAPPUCCINO_CORS_ORIGINS=https://a.example, https://b.example\n"))
+
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := []string{"https://a.example", "https://b.example"}
+	if !reflect.DeepEqual(cfg.CORSOrigins, want) {
+		t.Errorf("CORSOrigins = %v, want %v", cfg.CORSOrigins, want)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		env     map[string]string
+		file    string
+		wantErr string
+	}{
+		{name: "unknown file key", file: "PORT=1\n", wantErr: "unknown key PORT"},
+		{name: "file line without value", file: "DB_HOST\n", wantErr: "expected KEY=value"},
+		{name: "invalid env value", env: map[string]string{"DB_PORT": "five"}, wantErr: `invalid DB_PORT "five"`},
+		{name: "invalid port", args: []string{"--port", "0"}, wantErr: `invalid port "0"`},
+		{name: "write timeout shorter than request timeout", args: []string{"--write-timeout", "1s"}, wantErr: "shorter than the request timeout"},
+		{name: "unexpected argument", args: []string{"serve"}, wantErr: `unexpected argument "serve"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			args := tt.args
+			if tt.file != "" {
+				args = append([]string{"--config", writeConfig(t, tt.file)}, args...)
+			}
+
+			_, err := Load(args)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadHelp(t *testing.T) {
+	clearEnv(t)
+	if _, err := Load([]string{"--help"}); !errors.Is(err, flag.ErrHelp) {
+		t.Errorf("Load(--help) error = %v, want flag.ErrHelp", err)
+	}
+}
+
+func TestDSN(t *testing.T) {
+	db := DBConfig{Host: "db", Port: 5432, User: "latte", Password: `it's a \secret`, Name: "frappuccino", SSLMode: "disable"}
+	want := `host='db' port=5432 user='latte' password='it\'s a \\secret' dbname='frappuccino' sslmode='disable'`
+	if got := db.DSN(); got != want {
+		t.Errorf("DSN() = %s, want %s", got, want)
+	}
+}
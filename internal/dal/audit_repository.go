@@ -0,0 +1,132 @@
+package dal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"frappuccino/internal/utils"
+	"frappuccino/models"
+)
+
+// Snapshots select an entity as JSON for the audit log, by its id.
+const (
+	menuItemSnapshot = `
+	SELECT to_jsonb(m) || jsonb_build_object('ingredients', COALESCE((
+		SELECT jsonb_agg(jsonb_build_object('ingredient_id', mii.inventory_id, 'quantity', mii.quantity) ORDER BY mii.inventory_id)
+		FROM menu_item_ingredients mii WHERE mii.menu_item_id = m.menu_item_id), '[]'::jsonb))
+	FROM menu_items m WHERE m.menu_item_id = $1`
+	ingredientSnapshot = `SELECT to_jsonb(i) FROM inventory i WHERE i.ingredient_id = $1`
+	orderSnapshot      = `
+	SELECT to_jsonb(o) || jsonb_build_object('items', COALESCE((
+		SELECT jsonb_agg(jsonb_build_object('menu_item_id', oi.menu_item_id, 'quantity', oi.quantity,
+			'price_at_order', oi.price_at_order, 'customization_options', oi.customization_options) ORDER BY oi.order_item_id)
+		FROM order_items oi WHERE oi.order_id = o.order_id), '[]'::jsonb))
+	FROM orders o WHERE o.order_id = $1`
+)
+
+type AuditRepository struct {
+	db *sql.DB
+}
+
+type AuditInterface interface {
+	List(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, error)
+}
+
+func NewAuditRepository(db *sql.DB) (*AuditRepository, error) {
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("unable to connect to database: %v", err)
+	}
+
+	return &AuditRepository{db: db}, nil
+}
+
+// List returns audit entries ordered from the newest, before the cursor
+// when it is set.
+func (repo *AuditRepository) List(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, error) {
+	query := `
+	SELECT audit_id, action, entity, entity_id, before_data, after_data,
+	       actor_kind, actor_id, actor_name, employee_id, request_id, created_at
+	FROM audit_log
+	WHERE ($1::text IS NULL OR entity = $1)
+	  AND ($2::int IS NULL OR entity_id = $2)
+	  AND ($3::audit_action IS NULL OR action = $3::audit_action)
+	  AND ($4::int IS NULL OR employee_id = $4)
+	  AND ($5::text IS NULL OR request_id = $5)
+	  AND ($6::date IS NULL OR created_at >= $6::date)
+	  AND ($7::date IS NULL OR created_at < $7::date + 1)
+	  AND ($8 = 0 OR audit_id < $8)
+	ORDER BY audit_id DESC
+	LIMIT $9`
+
+	rows, err := repo.db.QueryContext(ctx, query, filter.Entity, filter.EntityID, filter.Action, filter.EmployeeID,
+		filter.RequestID, filter.StartDate, filter.EndDate, filter.Cursor, filter.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit log: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.AuditEntry{}
+	for rows.Next() {
+		var e models.AuditEntry
+		var before, after []byte
+		var kind, name sql.NullString
+		var actorID sql.NullInt64
+		if err := rows.Scan(&e.ID, &e.Action, &e.Entity, &e.EntityID, &before, &after,
+			&kind, &actorID, &name, &e.Actor.EmployeeID, &e.RequestID, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		e.Before, e.After = nullJSON(before), nullJSON(after)
+		e.Actor.Kind, e.Actor.ID, e.Actor.Name = kind.String, int(actorID.Int64), name.String
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over audit log: %w", err)
+	}
+	return entries, nil
+}
+
+// snapshot returns the entity selected by query as JSON, nil when it
+// doesn't exist.
+func snapshot(ctx context.Context, tx *sql.Tx, query string, id int) ([]byte, error) {
+	var data []byte
+	if err := tx.QueryRowContext(ctx, query, id).Scan(&data); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to snapshot %d for the audit log: %w", id, err)
+	}
+	return data, nil
+}
+
+// recordAudit appends a change to the audit log in the transaction making
+// it, so the entry exists exactly when the change does.
+func recordAudit(ctx context.Context, tx *sql.Tx, action, entity string, entityID int, before, after []byte) error {
+	actor := utils.ActorFrom(ctx)
+	query := `
+	INSERT INTO audit_log (action, entity, entity_id, before_data, after_data,
+	                       actor_kind, actor_id, actor_name, employee_id, request_id)
+	VALUES ($1, $2, $3, $4::jsonb, $5::jsonb, NULLIF($6, ''), NULLIF($7, 0), NULLIF($8, ''), $9, NULLIF($10, ''))`
+	_, err := tx.ExecContext(ctx, query, action, entity, entityID, nullableJSON(before), nullableJSON(after),
+		actor.Kind, actor.ID, actor.Name, actor.EmployeeID, actor.RequestID)
+	if err != nil {
+		return fmt.Errorf("failed to record audit entry: %w", err)
+	}
+	return nil
+}
+
+// nullableJSON passes missing JSON to the database as NULL.
+func nullableJSON(data []byte) interface{} {
+	if data == nil {
+		return nil
+	}
+	return string(data)
+}
+
+// nullJSON turns a NULL column into a JSON null.
+func nullJSON(data []byte) []byte {
+	if data == nil {
+		return []byte("null")
+	}
+	return data
+}
@@ -0,0 +1,232 @@
+package dal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"frappuccino/models"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// lastUsedGranularity limits how often last_used_at is written, so that
+// authenticating a request doesn't update a row every time.
+const lastUsedGranularity = `INTERVAL '1 minute'`
+
+const apiTokenColumns = `token_id, name, role, created_by, created_at, expires_at, last_used_at, revoked_at`
+
+type AuthRepository struct {
+	db *sql.DB
+}
+
+type AuthInterface interface {
+	GetCredentials(ctx context.Context, username string) (models.StoredCredentials, error)
+	SetCredentials(ctx context.Context, employeeID int, username, passwordHash string) error
+	CreateSession(ctx context.Context, employeeID int, ttl time.Duration) (int, time.Time, error)
+	GetActiveSession(ctx context.Context, id int) (models.AuthSession, error)
+	RevokeSession(ctx context.Context, id int) error
+	CreateAPIToken(ctx context.Context, token *models.APIToken, hash string) error
+	GetActiveAPIToken(ctx context.Context, hash string) (models.APIToken, error)
+	ListAPITokens(ctx context.Context) ([]models.APIToken, error)
+	RevokeAPIToken(ctx context.Context, id int) error
+}
+
+func NewAuthRepository(db *sql.DB) (*AuthRepository, error) {
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("unable to connect to database: %v", err)
+	}
+
+	return &AuthRepository{db: db}, nil
+}
+
+func (repo *AuthRepository) GetCredentials(ctx context.Context, username string) (models.StoredCredentials, error) {
+	var credentials models.StoredCredentials
+	var hash sql.NullString
+	err := repo.db.QueryRowContext(ctx, `SELECT employee_id, password_hash, active FROM employees WHERE username = $1`, username).
+		Scan(&credentials.EmployeeID, &hash, &credentials.Active)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.StoredCredentials{}, models.NotFound("user %s not found", username)
+		}
+		return models.StoredCredentials{}, fmt.Errorf("failed to get credentials: %w", err)
+	}
+	credentials.PasswordHash = hash.String
+	return credentials, nil
+}
+
+// SetCredentials sets the login of an employee and revokes their sessions,
+// so a changed password logs out everywhere.
+func (repo *AuthRepository) SetCredentials(ctx context.Context, employeeID int, username, passwordHash string) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `UPDATE employees SET username = $1, password_hash = $2 WHERE employee_id = $3`,
+		username, passwordHash, employeeID)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return models.Conflict("employee with this username already exists")
+		}
+		return fmt.Errorf("failed to set credentials: %w", err)
+	}
+	numRows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if numRows == 0 {
+		return models.NotFound("employee with ID %d not found", employeeID)
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE auth_sessions SET revoked_at = LOCALTIMESTAMP WHERE employee_id = $1 AND revoked_at IS NULL`,
+		employeeID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// CreateSession records a login that expires after ttl and returns its id
+// and expiry.
+func (repo *AuthRepository) CreateSession(ctx context.Context, employeeID int, ttl time.Duration) (int, time.Time, error) {
+	var id int
+	var expiresAt time.Time
+	query := `
+	INSERT INTO auth_sessions (employee_id, expires_at, last_used_at)
+	VALUES ($1, LOCALTIMESTAMP + $2 * INTERVAL '1 second', LOCALTIMESTAMP)
+	RETURNING session_id, expires_at`
+	if err := repo.db.QueryRowContext(ctx, query, employeeID, int64(ttl.Seconds())).Scan(&id, &expiresAt); err != nil {
+		return 0, time.Time{}, fmt.Errorf("failed to create session: %w", err)
+	}
+	return id, expiresAt, nil
+}
+
+// GetActiveSession returns a session that is neither revoked nor expired
+// and records that it was used.
+func (repo *AuthRepository) GetActiveSession(ctx context.Context, id int) (models.AuthSession, error) {
+	var session models.AuthSession
+	query := `
+	SELECT s.session_id, s.employee_id, e.name, e.role::text, e.active, s.expires_at, s.last_used_at
+	FROM auth_sessions s
+	JOIN employees e ON e.employee_id = s.employee_id
+	WHERE s.session_id = $1 AND s.revoked_at IS NULL AND s.expires_at > LOCALTIMESTAMP`
+	err := repo.db.QueryRowContext(ctx, query, id).Scan(&session.ID, &session.EmployeeID, &session.EmployeeName, &session.Role,
+		&session.Active, &session.ExpiresAt, &session.LastUsedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.AuthSession{}, models.NotFound("session with ID %d not found", id)
+		}
+		return models.AuthSession{}, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	_, err = repo.db.ExecContext(ctx, `
+		UPDATE auth_sessions SET last_used_at = LOCALTIMESTAMP
+		WHERE session_id = $1 AND (last_used_at IS NULL OR last_used_at < LOCALTIMESTAMP - `+lastUsedGranularity+`)`, id)
+	if err != nil {
+		return models.AuthSession{}, fmt.Errorf("failed to record session use: %w", err)
+	}
+	return session, nil
+}
+
+func (repo *AuthRepository) RevokeSession(ctx context.Context, id int) error {
+	result, err := repo.db.ExecContext(ctx, `UPDATE auth_sessions SET revoked_at = LOCALTIMESTAMP WHERE session_id = $1 AND revoked_at IS NULL`, id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	numRows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if numRows == 0 {
+		return models.NotFound("session with ID %d not found", id)
+	}
+	return nil
+}
+
+func (repo *AuthRepository) CreateAPIToken(ctx context.Context, token *models.APIToken, hash string) error {
+	query := `
+	INSERT INTO api_tokens (name, token_hash, role, created_by, expires_at)
+	VALUES ($1, $2, $3, $4, LOCALTIMESTAMP + make_interval(days => $5::int))
+	RETURNING ` + apiTokenColumns
+	created, err := scanAPIToken(repo.db.QueryRowContext(ctx, query, token.Name, hash, token.Role, token.CreatedBy, token.ExpiresInDays))
+	if err != nil {
+		return fmt.Errorf("failed to create API token: %w", err)
+	}
+	created.Token = token.Token
+	*token = created
+	return nil
+}
+
+// GetActiveAPIToken looks a token up by its hash and records that it was
+// used. Revoked and expired tokens are not found.
+func (repo *AuthRepository) GetActiveAPIToken(ctx context.Context, hash string) (models.APIToken, error) {
+	query := `
+	SELECT ` + apiTokenColumns + `
+	FROM api_tokens
+	WHERE token_hash = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > LOCALTIMESTAMP)`
+	token, err := scanAPIToken(repo.db.QueryRowContext(ctx, query, hash))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.APIToken{}, models.NotFound("API token not found")
+		}
+		return models.APIToken{}, fmt.Errorf("failed to get API token: %w", err)
+	}
+
+	_, err = repo.db.ExecContext(ctx, `
+		UPDATE api_tokens SET last_used_at = LOCALTIMESTAMP
+		WHERE token_id = $1 AND (last_used_at IS NULL OR last_used_at < LOCALTIMESTAMP - `+lastUsedGranularity+`)`, token.ID)
+	if err != nil {
+		return models.APIToken{}, fmt.Errorf("failed to record API token use: %w", err)
+	}
+	return token, nil
+}
+
+func (repo *AuthRepository) ListAPITokens(ctx context.Context) ([]models.APIToken, error) {
+	rows, err := repo.db.QueryContext(ctx, `SELECT `+apiTokenColumns+` FROM api_tokens ORDER BY token_id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query API tokens: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := []models.APIToken{}
+	for rows.Next() {
+		token, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan API token: %w", err)
+		}
+		tokens = append(tokens, token)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over API tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+func (repo *AuthRepository) RevokeAPIToken(ctx context.Context, id int) error {
+	result, err := repo.db.ExecContext(ctx, `UPDATE api_tokens SET revoked_at = LOCALTIMESTAMP WHERE token_id = $1 AND revoked_at IS NULL`, id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke API token: %w", err)
+	}
+	numRows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if numRows == 0 {
+		return models.NotFound("API token with ID %d not found", id)
+	}
+	return nil
+}
+
+func scanAPIToken(row interface{ Scan(...interface{}) error }) (models.APIToken, error) {
+	var t models.APIToken
+	err := row.Scan(&t.ID, &t.Name, &t.Role, &t.CreatedBy, &t.CreatedAt, &t.ExpiresAt, &t.LastUsedAt, &t.RevokedAt)
+	return t, err
+}
@@ -0,0 +1,257 @@
+package dal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"frappuccino/models"
+
+	"github.com/lib/pq"
+)
+
+// Customers earn one loyalty point per full unit of currency spent on a
+// completed order.
+const loyaltyPointsPerUnit = 1
+
+type CustomerRepository struct {
+	db *sql.DB
+}
+
+type CustomerInterface interface {
+	Create(ctx context.Context, customer *models.Customer) error
+	GetByID(ctx context.Context, id int) (models.Customer, error)
+	List(ctx context.Context, search string) ([]models.Customer, error)
+	Update(ctx context.Context, customer models.Customer, id int) error
+	Delete(ctx context.Context, id int) error
+	GetStats(ctx context.Context, id int) (models.CustomerStats, error)
+}
+
+func NewCustomerRepository(db *sql.DB) (*CustomerRepository, error) {
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("unable to connect to database: %v", err)
+	}
+
+	return &CustomerRepository{db: db}, nil
+}
+
+func (repo *CustomerRepository) Create(ctx context.Context, customer *models.Customer) error {
+	query := `
+	INSERT INTO customers (name, phone, email, marketing_consent)
+	VALUES ($1, $2, $3, $4)
+	RETURNING customer_id, loyalty_points, created_at, updated_at`
+	err := repo.db.QueryRowContext(ctx, query, customer.Name, customer.Phone, customer.Email, customer.MarketingConsent).
+		Scan(&customer.ID, &customer.LoyaltyPoints, &customer.CreatedAt, &customer.UpdatedAt)
+	if err != nil {
+		return customerWriteError("failed to create customer", err)
+	}
+	return nil
+}
+
+func (repo *CustomerRepository) GetByID(ctx context.Context, id int) (models.Customer, error) {
+	var customer models.Customer
+	query := `
+	SELECT customer_id, name, phone, email, marketing_consent, loyalty_points, created_at, updated_at
+	FROM customers WHERE customer_id = $1`
+	err := repo.db.QueryRowContext(ctx, query, id).Scan(&customer.ID, &customer.Name, &customer.Phone, &customer.Email,
+		&customer.MarketingConsent, &customer.LoyaltyPoints, &customer.CreatedAt, &customer.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Customer{}, models.NotFound("customer with ID %d not found", id)
+		}
+		return models.Customer{}, fmt.Errorf("failed to scan customer: %w", err)
+	}
+	return customer, nil
+}
+
+// List returns customers whose name, phone or email contains search.
+func (repo *CustomerRepository) List(ctx context.Context, search string) ([]models.Customer, error) {
+	query := `
+	SELECT customer_id, name, phone, email, marketing_consent, loyalty_points, created_at, updated_at
+	FROM customers
+	WHERE $1 = ''
+	   OR name ILIKE '%' || $1 || '%' ESCAPE '\'
+	   OR phone ILIKE '%' || $1 || '%' ESCAPE '\'
+	   OR email ILIKE '%' || $1 || '%' ESCAPE '\'
+	ORDER BY name, customer_id`
+	rows, err := repo.db.QueryContext(ctx, query, likeEscaper.Replace(search))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query customers: %w", err)
+	}
+	defer rows.Close()
+
+	customers := []models.Customer{}
+	for rows.Next() {
+		var customer models.Customer
+		if err := rows.Scan(&customer.ID, &customer.Name, &customer.Phone, &customer.Email,
+			&customer.MarketingConsent, &customer.LoyaltyPoints, &customer.CreatedAt, &customer.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan customer: %w", err)
+		}
+		customers = append(customers, customer)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over customers: %w", err)
+	}
+	return customers, nil
+}
+
+// Update changes contact details and consent. Loyalty points only change
+// through orders.
+func (repo *CustomerRepository) Update(ctx context.Context, customer models.Customer, id int) error {
+	query := `
+	UPDATE customers
+	SET name = $1, phone = $2, email = $3, marketing_consent = $4, updated_at = CURRENT_TIMESTAMP
+	WHERE customer_id = $5`
+	result, err := repo.db.ExecContext(ctx, query, customer.Name, customer.Phone, customer.Email, customer.MarketingConsent, id)
+	if err != nil {
+		return customerWriteError("failed to update customer", err)
+	}
+	numRows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if numRows == 0 {
+		return models.NotFound("customer with ID %d not found", id)
+	}
+	return nil
+}
+
+func (repo *CustomerRepository) Delete(ctx context.Context, id int) error {
+	result, err := repo.db.ExecContext(ctx, `DELETE FROM customers WHERE customer_id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete customer: %w", err)
+	}
+	numRows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if numRows == 0 {
+		return models.NotFound("customer with ID %d not found", id)
+	}
+	return nil
+}
+
+func (repo *CustomerRepository) GetStats(ctx context.Context, id int) (models.CustomerStats, error) {
+	stats := models.CustomerStats{CustomerID: id}
+	query := `
+	SELECT c.loyalty_points,
+	       COUNT(o.order_id) FILTER (WHERE o.status NOT IN ('cancelled', 'rejected')),
+	       COUNT(o.order_id) FILTER (WHERE o.status = 'completed'),
+	       COALESCE(SUM(o.total_amount) FILTER (WHERE o.status = 'completed'), 0),
+	       MIN(o.created_at) FILTER (WHERE o.status NOT IN ('cancelled', 'rejected')),
+	       MAX(o.created_at) FILTER (WHERE o.status NOT IN ('cancelled', 'rejected')),
+	       COALESCE((SELECT SUM(points) FROM loyalty_transactions WHERE customer_id = c.customer_id AND reason = 'earn'), 0),
+	       COALESCE((SELECT -SUM(points) FROM loyalty_transactions WHERE customer_id = c.customer_id AND reason = 'redeem'), 0)
+	FROM customers c
+	LEFT JOIN orders o ON o.customer_id = c.customer_id
+	WHERE c.customer_id = $1
+	GROUP BY c.customer_id`
+	err := repo.db.QueryRowContext(ctx, query, id).Scan(&stats.LoyaltyPoints, &stats.Visits, &stats.CompletedOrders,
+		&stats.LifetimeValue, &stats.FirstVisit, &stats.LastVisit, &stats.PointsEarned, &stats.PointsRedeemed)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.CustomerStats{}, models.NotFound("customer with ID %d not found", id)
+		}
+		return models.CustomerStats{}, fmt.Errorf("failed to get customer stats: %w", err)
+	}
+
+	favoriteQuery := `
+	SELECT mi.name
+	FROM orders o
+	JOIN order_items oi ON oi.order_id = o.order_id
+	JOIN menu_items mi ON mi.menu_item_id = oi.menu_item_id
+	WHERE o.customer_id = $1 AND o.status NOT IN ('cancelled', 'rejected')
+	GROUP BY mi.menu_item_id, mi.name
+	ORDER BY SUM(oi.quantity) DESC, mi.name
+	LIMIT 1`
+	var favorite string
+	err = repo.db.QueryRowContext(ctx, favoriteQuery, id).Scan(&favorite)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return models.CustomerStats{}, fmt.Errorf("failed to get favorite item: %w", err)
+	}
+	if err == nil {
+		stats.FavoriteItem = &favorite
+	}
+
+	return stats, nil
+}
+
+func customerWriteError(message string, err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return models.Conflict("customer with this phone or email already exists")
+	}
+	return fmt.Errorf("%s: %w", message, err)
+}
+
+// redeemLoyaltyPoints takes points from the customer for a discount on the
+// order.
+func redeemLoyaltyPoints(ctx context.Context, tx *sql.Tx, customerID, orderID, points int) error {
+	query := `
+	UPDATE customers SET loyalty_points = loyalty_points - $1, updated_at = CURRENT_TIMESTAMP
+	WHERE customer_id = $2 AND loyalty_points >= $1`
+	result, err := tx.ExecContext(ctx, query, points, customerID)
+	if err != nil {
+		return fmt.Errorf("failed to redeem loyalty points: %w", err)
+	}
+	numRows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if numRows == 0 {
+		return models.Conflict("insufficient loyalty points")
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO loyalty_transactions (customer_id, order_id, points, reason) VALUES ($1, $2, $3, 'redeem')`,
+		customerID, orderID, -points)
+	if err != nil {
+		return fmt.Errorf("failed to insert loyalty transaction: %w", err)
+	}
+	return nil
+}
+
+// earnLoyaltyPoints credits the customer of a completed order. Orders
+// without a customer earn nothing.
+func earnLoyaltyPoints(ctx context.Context, tx *sql.Tx, orderID int) error {
+	query := `
+	WITH earned AS (
+		SELECT customer_id, FLOOR(total_amount * $2)::int AS points
+		FROM orders
+		WHERE order_id = $1 AND customer_id IS NOT NULL
+	), credited AS (
+		UPDATE customers c
+		SET loyalty_points = c.loyalty_points + e.points, updated_at = CURRENT_TIMESTAMP
+		FROM earned e
+		WHERE c.customer_id = e.customer_id AND e.points > 0
+	)
+	INSERT INTO loyalty_transactions (customer_id, order_id, points, reason)
+	SELECT customer_id, $1, points, 'earn' FROM earned WHERE points > 0`
+	if _, err := tx.ExecContext(ctx, query, orderID, loyaltyPointsPerUnit); err != nil {
+		return fmt.Errorf("failed to earn loyalty points: %w", err)
+	}
+	return nil
+}
+
+// reverseLoyaltyPoints undoes everything earned and redeemed on an order,
+// so a cancelled order gives the redeemed points back.
+func reverseLoyaltyPoints(ctx context.Context, tx *sql.Tx, orderID int) error {
+	query := `
+	WITH net AS (
+		SELECT customer_id, -SUM(points)::int AS points
+		FROM loyalty_transactions
+		WHERE order_id = $1
+		GROUP BY customer_id
+		HAVING SUM(points) <> 0
+	), reversed AS (
+		UPDATE customers c
+		SET loyalty_points = GREATEST(c.loyalty_points + n.points, 0), updated_at = CURRENT_TIMESTAMP
+		FROM net n
+		WHERE c.customer_id = n.customer_id
+	)
+	INSERT INTO loyalty_transactions (customer_id, order_id, points, reason)
+	SELECT customer_id, $1, points, 'reversal' FROM net`
+	if _, err := tx.ExecContext(ctx, query, orderID); err != nil {
+		return fmt.Errorf("failed to reverse loyalty points: %w", err)
+	}
+	return nil
+}
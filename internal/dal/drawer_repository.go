@@ -0,0 +1,292 @@
+package dal
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"frappuccino/models"
+
+	"github.com/lib/pq"
+)
+
+const drawerSessionColumns = `session_id, opened_by, opening_float, status, opened_at, closed_by, closed_at, counted_cash`
+
+type DrawerRepository struct {
+	db *sql.DB
+}
+
+type DrawerInterface interface {
+	Open(ctx context.Context, session *models.DrawerSession) error
+	List(ctx context.Context) ([]models.DrawerSession, error)
+	GetByID(ctx context.Context, id int) (models.DrawerSession, error)
+	AddMovement(ctx context.Context, movement *models.DrawerMovement) error
+	BeginTransaction(ctx context.Context) (*sql.Tx, error)
+	LockSession(ctx context.Context, tx *sql.Tx, id int) (models.DrawerSession, error)
+	BuildReport(ctx context.Context, tx *sql.Tx, session models.DrawerSession) (models.ZReport, error)
+	SaveClose(ctx context.Context, tx *sql.Tx, report models.ZReport) error
+	GetZReport(ctx context.Context, id int) (models.ZReport, error)
+}
+
+func NewDrawerRepository(db *sql.DB) (*DrawerRepository, error) {
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("unable to connect to database: %v", err)
+	}
+
+	return &DrawerRepository{db: db}, nil
+}
+
+func (repo *DrawerRepository) Open(ctx context.Context, session *models.DrawerSession) error {
+	query := `
+	INSERT INTO drawer_sessions (opened_by, opening_float)
+	VALUES ($1, $2)
+	RETURNING ` + drawerSessionColumns
+	created, err := scanDrawerSession(repo.db.QueryRowContext(ctx, query, session.OpenedBy, session.OpeningFloat))
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return models.Conflict("a drawer session is already open")
+		}
+		return fmt.Errorf("failed to open drawer session: %w", err)
+	}
+	*session = created
+	return nil
+}
+
+func (repo *DrawerRepository) List(ctx context.Context) ([]models.DrawerSession, error) {
+	rows, err := repo.db.QueryContext(ctx, `SELECT `+drawerSessionColumns+` FROM drawer_sessions ORDER BY session_id DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query drawer sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := []models.DrawerSession{}
+	for rows.Next() {
+		session, err := scanDrawerSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan drawer session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over drawer sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+func (repo *DrawerRepository) GetByID(ctx context.Context, id int) (models.DrawerSession, error) {
+	row := repo.db.QueryRowContext(ctx, `SELECT `+drawerSessionColumns+` FROM drawer_sessions WHERE session_id = $1`, id)
+	session, err := scanDrawerSession(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.DrawerSession{}, models.NotFound("drawer session with ID %d not found", id)
+		}
+		return models.DrawerSession{}, fmt.Errorf("failed to scan drawer session: %w", err)
+	}
+
+	rows, err := repo.db.QueryContext(ctx, `
+		SELECT movement_id, session_id, type, amount, reason, created_at
+		FROM drawer_movements WHERE session_id = $1 ORDER BY movement_id`, id)
+	if err != nil {
+		return models.DrawerSession{}, fmt.Errorf("failed to query drawer movements: %w", err)
+	}
+	defer rows.Close()
+
+	session.Movements = []models.DrawerMovement{}
+	for rows.Next() {
+		var m models.DrawerMovement
+		if err := rows.Scan(&m.ID, &m.SessionID, &m.Type, &m.Amount, &m.Reason, &m.CreatedAt); err != nil {
+			return models.DrawerSession{}, fmt.Errorf("failed to scan drawer movement: %w", err)
+		}
+		session.Movements = append(session.Movements, m)
+	}
+	if err := rows.Err(); err != nil {
+		return models.DrawerSession{}, fmt.Errorf("error iterating over drawer movements: %w", err)
+	}
+	return session, nil
+}
+
+// AddMovement records cash put into or taken out of an open drawer.
+func (repo *DrawerRepository) AddMovement(ctx context.Context, movement *models.DrawerMovement) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	session, err := repo.LockSession(ctx, tx, movement.SessionID)
+	if err != nil {
+		return err
+	}
+	if session.Status != "open" {
+		return models.Conflict("drawer session %d is closed", session.ID)
+	}
+
+	query := `
+	INSERT INTO drawer_movements (session_id, type, amount, reason)
+	VALUES ($1, $2, $3, $4)
+	RETURNING movement_id, created_at`
+	err = tx.QueryRowContext(ctx, query, movement.SessionID, movement.Type, movement.Amount, movement.Reason).
+		Scan(&movement.ID, &movement.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert drawer movement: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (repo *DrawerRepository) BeginTransaction(ctx context.Context) (*sql.Tx, error) {
+	return repo.db.BeginTx(ctx, nil)
+}
+
+func (repo *DrawerRepository) LockSession(ctx context.Context, tx *sql.Tx, id int) (models.DrawerSession, error) {
+	row := tx.QueryRowContext(ctx, `SELECT `+drawerSessionColumns+` FROM drawer_sessions WHERE session_id = $1 FOR UPDATE`, id)
+	session, err := scanDrawerSession(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.DrawerSession{}, models.NotFound("drawer session with ID %d not found", id)
+		}
+		return models.DrawerSession{}, fmt.Errorf("failed to lock drawer session: %w", err)
+	}
+	return session, nil
+}
+
+// BuildReport collects the raw totals of a session up to now: orders
+// completed while it was open and the payments, refunds and movements
+// recorded against it.
+func (repo *DrawerRepository) BuildReport(ctx context.Context, tx *sql.Tx, session models.DrawerSession) (models.ZReport, error) {
+	report := models.ZReport{
+		SessionID:    session.ID,
+		OpenedBy:     session.OpenedBy,
+		OpenedAt:     session.OpenedAt,
+		OpeningFloat: session.OpeningFloat,
+	}
+	if err := tx.QueryRowContext(ctx, `SELECT LOCALTIMESTAMP`).Scan(&report.ClosedAt); err != nil {
+		return models.ZReport{}, fmt.Errorf("failed to get closing time: %w", err)
+	}
+
+	ordersQuery := `
+	SELECT COUNT(*), COALESCE(SUM(o.subtotal), 0), COALESCE(SUM(o.discount_amount), 0),
+	       COALESCE(SUM(o.tax_amount), 0), COALESCE(SUM(o.total_amount), 0)
+	FROM orders o
+	WHERE o.status = 'completed'
+	  AND EXISTS (SELECT 1 FROM order_status_history h
+	              WHERE h.order_id = o.order_id AND h.status = 'completed'
+	                AND h.changed_at >= $1 AND h.changed_at <= $2)`
+	err := tx.QueryRowContext(ctx, ordersQuery, session.OpenedAt, report.ClosedAt).Scan(&report.Orders, &report.GrossSales,
+		&report.Discounts, &report.Tax, &report.TotalSales)
+	if err != nil {
+		return models.ZReport{}, fmt.Errorf("failed to total drawer orders: %w", err)
+	}
+
+	tendersQuery := `
+	SELECT m.method::text, COALESCE(p.payments, 0), COALESCE(p.amount, 0), COALESCE(p.tips, 0), COALESCE(r.amount, 0)
+	FROM unnest(enum_range(NULL::payment_method)) AS m(method)
+	LEFT JOIN (SELECT method, COUNT(*) AS payments, SUM(amount) AS amount, SUM(tip) AS tips
+	           FROM payments WHERE drawer_session_id = $1 GROUP BY method) p ON p.method = m.method
+	LEFT JOIN (SELECT pm.method, SUM(rf.amount) AS amount
+	           FROM refunds rf JOIN payments pm ON pm.payment_id = rf.payment_id
+	           WHERE rf.drawer_session_id = $1 GROUP BY pm.method) r ON r.method = m.method
+	ORDER BY m.method`
+	rows, err := tx.QueryContext(ctx, tendersQuery, session.ID)
+	if err != nil {
+		return models.ZReport{}, fmt.Errorf("failed to total drawer tenders: %w", err)
+	}
+	defer rows.Close()
+
+	report.Tenders = []models.ZReportTender{}
+	for rows.Next() {
+		var tender models.ZReportTender
+		if err := rows.Scan(&tender.Method, &tender.Payments, &tender.Amount, &tender.Tips, &tender.Refunds); err != nil {
+			return models.ZReport{}, fmt.Errorf("failed to scan drawer tender: %w", err)
+		}
+		report.Tenders = append(report.Tenders, tender)
+	}
+	if err := rows.Err(); err != nil {
+		return models.ZReport{}, fmt.Errorf("error iterating over drawer tenders: %w", err)
+	}
+
+	movementsQuery := `
+	SELECT COUNT(*),
+	       COALESCE(SUM(amount) FILTER (WHERE type = 'cash_in'), 0),
+	       COALESCE(SUM(amount) FILTER (WHERE type = 'cash_out'), 0)
+	FROM drawer_movements WHERE session_id = $1`
+	err = tx.QueryRowContext(ctx, movementsQuery, session.ID).Scan(&report.MovementsCount, &report.CashIn, &report.CashOut)
+	if err != nil {
+		return models.ZReport{}, fmt.Errorf("failed to total drawer movements: %w", err)
+	}
+
+	return report, nil
+}
+
+// SaveClose closes the session and stores its Z-report. After that the
+// session can't be changed anymore.
+func (repo *DrawerRepository) SaveClose(ctx context.Context, tx *sql.Tx, report models.ZReport) error {
+	snapshot, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal Z-report: %w", err)
+	}
+
+	query := `
+	UPDATE drawer_sessions
+	SET status = 'closed', closed_by = $1, closed_at = $2, counted_cash = $3, z_report = $4::jsonb
+	WHERE session_id = $5 AND status = 'open'`
+	result, err := tx.ExecContext(ctx, query, report.ClosedBy, report.ClosedAt, report.CountedCash, string(snapshot), report.SessionID)
+	if err != nil {
+		return fmt.Errorf("failed to close drawer session: %w", err)
+	}
+	numRows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if numRows == 0 {
+		return models.Conflict("drawer session %d is closed", report.SessionID)
+	}
+	return nil
+}
+
+func (repo *DrawerRepository) GetZReport(ctx context.Context, id int) (models.ZReport, error) {
+	var status string
+	var snapshot []byte
+	err := repo.db.QueryRowContext(ctx, `SELECT status, z_report FROM drawer_sessions WHERE session_id = $1`, id).Scan(&status, &snapshot)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ZReport{}, models.NotFound("drawer session with ID %d not found", id)
+		}
+		return models.ZReport{}, fmt.Errorf("failed to get Z-report: %w", err)
+	}
+	if status != "closed" {
+		return models.ZReport{}, models.Conflict("drawer session %d is still open", id)
+	}
+
+	var report models.ZReport
+	if err := json.Unmarshal(snapshot, &report); err != nil {
+		return models.ZReport{}, fmt.Errorf("failed to unmarshal Z-report: %w", err)
+	}
+	return report, nil
+}
+
+func scanDrawerSession(row interface{ Scan(...interface{}) error }) (models.DrawerSession, error) {
+	var s models.DrawerSession
+	err := row.Scan(&s.ID, &s.OpenedBy, &s.OpeningFloat, &s.Status, &s.OpenedAt, &s.ClosedBy, &s.ClosedAt, &s.CountedCash)
+	return s, err
+}
+
+// currentDrawerSession returns the open drawer session, if any, so payments
+// and refunds are counted in its Z-report. The share lock waits for a
+// session being closed, which then no longer matches.
+func currentDrawerSession(ctx context.Context, tx *sql.Tx) (*int, error) {
+	var id int
+	err := tx.QueryRowContext(ctx, `SELECT session_id FROM drawer_sessions WHERE status = 'open' FOR SHARE`).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get open drawer session: %w", err)
+	}
+	return &id, nil
+}
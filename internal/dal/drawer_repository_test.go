@@ -0,0 +1,104 @@
+package dal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"os"
+	"testing"
+
+	"frappuccino/models"
+)
+
+// testDB connects to a database loaded with init.sql, named by the DSN in
+// FRAPPUCCINO_TEST_DSN. Tests that need it are skipped without it.
+func testDB(t *testing.T) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv("FRAPPUCCINO_TEST_DSN")
+	if dsn == "" {
+		t.Skip("FRAPPUCCINO_TEST_DSN isn't set")
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestZReportCountsClosedOrders(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	drawers, err := NewDrawerRepository(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	orders, _ := NewOrderRepository(db)
+	payments, _ := NewPaymentRepository(db)
+
+	var sessionID int
+	err = db.QueryRowContext(ctx, `SELECT session_id FROM drawer_sessions WHERE status = 'open'`).Scan(&sessionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		session := models.DrawerSession{OpenedBy: "Z-report test", OpeningFloat: 100}
+		if err := drawers.Open(ctx, &session); err != nil {
+			t.Fatal(err)
+		}
+		sessionID = session.ID
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	session, err := drawers.GetByID(ctx, sessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var orderID int
+	err = db.QueryRowContext(ctx, `
+		INSERT INTO orders (customer_name, subtotal, discount_amount, tax_amount, total_amount, status)
+		VALUES ('Z-report test', 10, 1, 0.9, 9.9, 'ready') RETURNING order_id`).Scan(&orderID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := payments.CreatePayment(ctx, &models.Payment{OrderID: orderID, Method: "card", Amount: 9.9}); err != nil {
+		t.Fatal(err)
+	}
+
+	report := func() models.ZReport {
+		t.Helper()
+		tx, err := drawers.BeginTransaction(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer tx.Rollback()
+		report, err := drawers.BuildReport(ctx, tx, session)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return report
+	}
+
+	before := report()
+	if err := orders.Close(ctx, orderID, false); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	after := report()
+
+	if after.Orders != before.Orders+1 {
+		t.Errorf("orders = %d, want %d", after.Orders, before.Orders+1)
+	}
+	for _, amount := range []struct {
+		name          string
+		before, after float64
+		want          float64
+	}{
+		{"gross sales", before.GrossSales, after.GrossSales, 10},
+		{"discounts", before.Discounts, after.Discounts, 1},
+		{"tax", before.Tax, after.Tax, 0.9},
+		{"total sales", before.TotalSales, after.TotalSales, 9.9},
+	} {
+		if got := amount.after - amount.before; math.Abs(got-amount.want) > 0.005 {
+			t.Errorf("%s grew by %.2f, want %.2f", amount.name, got, amount.want)
+		}
+	}
+}
@@ -0,0 +1,339 @@
+package dal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"frappuccino/models"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+const employeeColumns = `employee_id, name, email, phone, role, hourly_rate, active, created_at`
+
+// entryHours is the length of a time entry in hours; an open entry counts
+// up to now.
+const entryHours = `EXTRACT(EPOCH FROM COALESCE(t.clock_out, LOCALTIMESTAMP) - t.clock_in) / 3600`
+
+type EmployeeRepository struct {
+	db *sql.DB
+}
+
+type EmployeeInterface interface {
+	Create(ctx context.Context, employee *models.Employee) error
+	GetByID(ctx context.Context, id int) (models.Employee, error)
+	List(ctx context.Context, includeInactive bool) ([]models.Employee, error)
+	Update(ctx context.Context, employee models.Employee, id int) error
+	Deactivate(ctx context.Context, id int) error
+	CreateShift(ctx context.Context, shift *models.Shift) error
+	GetShift(ctx context.Context, id int) (models.Shift, error)
+	ListShifts(ctx context.Context, filter models.ShiftFilter) ([]models.Shift, error)
+	UpdateShift(ctx context.Context, shift models.Shift, id int) error
+	DeleteShift(ctx context.Context, id int) error
+	ClockIn(ctx context.Context, employeeID int) (models.TimeEntry, error)
+	ClockOut(ctx context.Context, employeeID int) (models.TimeEntry, error)
+	ListTimeEntries(ctx context.Context, employeeID int, startDate, endDate *string) ([]models.TimeEntry, error)
+}
+
+func NewEmployeeRepository(db *sql.DB) (*EmployeeRepository, error) {
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("unable to connect to database: %v", err)
+	}
+
+	return &EmployeeRepository{db: db}, nil
+}
+
+func (repo *EmployeeRepository) Create(ctx context.Context, employee *models.Employee) error {
+	query := `
+	INSERT INTO employees (name, email, phone, role, hourly_rate, active)
+	VALUES ($1, $2, $3, $4, $5, COALESCE($6, TRUE))
+	RETURNING ` + employeeColumns
+	created, err := scanEmployee(repo.db.QueryRowContext(ctx, query, employee.Name, employee.Email, employee.Phone, employee.Role,
+		employee.HourlyRate, employee.Active))
+	if err != nil {
+		return employeeWriteError("failed to create employee", err, nil)
+	}
+	*employee = created
+	return nil
+}
+
+func (repo *EmployeeRepository) GetByID(ctx context.Context, id int) (models.Employee, error) {
+	employee, err := scanEmployee(repo.db.QueryRowContext(ctx, `SELECT `+employeeColumns+` FROM employees WHERE employee_id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Employee{}, models.NotFound("employee with ID %d not found", id)
+		}
+		return models.Employee{}, fmt.Errorf("failed to scan employee: %w", err)
+	}
+	return employee, nil
+}
+
+func (repo *EmployeeRepository) List(ctx context.Context, includeInactive bool) ([]models.Employee, error) {
+	rows, err := repo.db.QueryContext(ctx, `SELECT `+employeeColumns+` FROM employees WHERE $1 OR active ORDER BY name, employee_id`,
+		includeInactive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query employees: %w", err)
+	}
+	defer rows.Close()
+
+	employees := []models.Employee{}
+	for rows.Next() {
+		employee, err := scanEmployee(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan employee: %w", err)
+		}
+		employees = append(employees, employee)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over employees: %w", err)
+	}
+	return employees, nil
+}
+
+// Update replaces the employee details. Active is kept when it is not given.
+func (repo *EmployeeRepository) Update(ctx context.Context, employee models.Employee, id int) error {
+	query := `
+	UPDATE employees
+	SET name = $1, email = $2, phone = $3, role = $4, hourly_rate = $5, active = COALESCE($6, active)
+	WHERE employee_id = $7`
+	result, err := repo.db.ExecContext(ctx, query, employee.Name, employee.Email, employee.Phone, employee.Role, employee.HourlyRate,
+		employee.Active, id)
+	if err != nil {
+		return employeeWriteError("failed to update employee", err, nil)
+	}
+	numRows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if numRows == 0 {
+		return models.NotFound("employee with ID %d not found", id)
+	}
+	return nil
+}
+
+// Deactivate keeps the employee, so the orders and movements they made stay
+// attributed to them.
+func (repo *EmployeeRepository) Deactivate(ctx context.Context, id int) error {
+	result, err := repo.db.ExecContext(ctx, `UPDATE employees SET active = FALSE WHERE employee_id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to deactivate employee: %w", err)
+	}
+	numRows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if numRows == 0 {
+		return models.NotFound("employee with ID %d not found", id)
+	}
+	return nil
+}
+
+func (repo *EmployeeRepository) CreateShift(ctx context.Context, shift *models.Shift) error {
+	query := `
+	INSERT INTO shifts (employee_id, starts_at, ends_at, note)
+	VALUES ($1, $2, $3, $4)
+	RETURNING shift_id, created_at`
+	err := repo.db.QueryRowContext(ctx, query, shift.EmployeeID, shift.StartsAt, shift.EndsAt, shift.Note).
+		Scan(&shift.ID, &shift.CreatedAt)
+	if err != nil {
+		return employeeWriteError("failed to create shift", err, &shift.EmployeeID)
+	}
+	return nil
+}
+
+func (repo *EmployeeRepository) GetShift(ctx context.Context, id int) (models.Shift, error) {
+	query := `
+	SELECT s.shift_id, s.employee_id, e.name, s.starts_at, s.ends_at, s.note, s.created_at
+	FROM shifts s JOIN employees e ON e.employee_id = s.employee_id
+	WHERE s.shift_id = $1`
+	shift, err := scanShift(repo.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Shift{}, models.NotFound("shift with ID %d not found", id)
+		}
+		return models.Shift{}, fmt.Errorf("failed to scan shift: %w", err)
+	}
+	return shift, nil
+}
+
+// ListShifts returns the shifts starting in the period, earliest first.
+func (repo *EmployeeRepository) ListShifts(ctx context.Context, filter models.ShiftFilter) ([]models.Shift, error) {
+	query := `
+	SELECT s.shift_id, s.employee_id, e.name, s.starts_at, s.ends_at, s.note, s.created_at
+	FROM shifts s JOIN employees e ON e.employee_id = s.employee_id
+	WHERE ($1::int IS NULL OR s.employee_id = $1::int)
+	  AND ($2::date IS NULL OR s.starts_at >= $2::date)
+	  AND ($3::date IS NULL OR s.starts_at < $3::date + 1)
+	ORDER BY s.starts_at, s.shift_id`
+	rows, err := repo.db.QueryContext(ctx, query, filter.EmployeeID, filter.StartDate, filter.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query shifts: %w", err)
+	}
+	defer rows.Close()
+
+	shifts := []models.Shift{}
+	for rows.Next() {
+		shift, err := scanShift(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan shift: %w", err)
+		}
+		shifts = append(shifts, shift)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over shifts: %w", err)
+	}
+	return shifts, nil
+}
+
+func (repo *EmployeeRepository) UpdateShift(ctx context.Context, shift models.Shift, id int) error {
+	query := `UPDATE shifts SET employee_id = $1, starts_at = $2, ends_at = $3, note = $4 WHERE shift_id = $5`
+	result, err := repo.db.ExecContext(ctx, query, shift.EmployeeID, shift.StartsAt, shift.EndsAt, shift.Note, id)
+	if err != nil {
+		return employeeWriteError("failed to update shift", err, &shift.EmployeeID)
+	}
+	numRows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if numRows == 0 {
+		return models.NotFound("shift with ID %d not found", id)
+	}
+	return nil
+}
+
+// DeleteShift removes a scheduled shift; time entries worked against it are
+// kept and only lose the link.
+func (repo *EmployeeRepository) DeleteShift(ctx context.Context, id int) error {
+	result, err := repo.db.ExecContext(ctx, `DELETE FROM shifts WHERE shift_id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete shift: %w", err)
+	}
+	numRows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if numRows == 0 {
+		return models.NotFound("shift with ID %d not found", id)
+	}
+	return nil
+}
+
+// ClockIn opens a time entry for the employee. It is linked to the shift
+// they are scheduled for now, counting from an hour before it starts.
+func (repo *EmployeeRepository) ClockIn(ctx context.Context, employeeID int) (models.TimeEntry, error) {
+	employee, err := repo.GetByID(ctx, employeeID)
+	if err != nil {
+		return models.TimeEntry{}, err
+	}
+	if employee.Active != nil && !*employee.Active {
+		return models.TimeEntry{}, models.Conflict("employee with ID %d is inactive", employeeID)
+	}
+
+	query := `
+	INSERT INTO time_entries (employee_id, shift_id, clock_in)
+	VALUES ($1, (SELECT s.shift_id FROM shifts s
+	             WHERE s.employee_id = $1
+	               AND LOCALTIMESTAMP >= s.starts_at - INTERVAL '1 hour' AND LOCALTIMESTAMP < s.ends_at
+	             ORDER BY s.starts_at LIMIT 1), LOCALTIMESTAMP)
+	RETURNING entry_id, employee_id, shift_id, clock_in, clock_out, 0`
+	entry, err := scanTimeEntry(repo.db.QueryRowContext(ctx, query, employeeID))
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return models.TimeEntry{}, models.Conflict("employee is already clocked in")
+		}
+		return models.TimeEntry{}, fmt.Errorf("failed to clock in: %w", err)
+	}
+	return entry, nil
+}
+
+func (repo *EmployeeRepository) ClockOut(ctx context.Context, employeeID int) (models.TimeEntry, error) {
+	query := `
+	UPDATE time_entries t
+	SET clock_out = LOCALTIMESTAMP
+	WHERE t.employee_id = $1 AND t.clock_out IS NULL
+	RETURNING t.entry_id, t.employee_id, t.shift_id, t.clock_in, t.clock_out, ` + entryHours
+	entry, err := scanTimeEntry(repo.db.QueryRowContext(ctx, query, employeeID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			if _, err := repo.GetByID(ctx, employeeID); err != nil {
+				return models.TimeEntry{}, err
+			}
+			return models.TimeEntry{}, models.Conflict("employee is not clocked in")
+		}
+		return models.TimeEntry{}, fmt.Errorf("failed to clock out: %w", err)
+	}
+	return entry, nil
+}
+
+// ListTimeEntries returns the entries of an employee clocked in during the
+// period, latest first.
+func (repo *EmployeeRepository) ListTimeEntries(ctx context.Context, employeeID int, startDate, endDate *string) ([]models.TimeEntry, error) {
+	if _, err := repo.GetByID(ctx, employeeID); err != nil {
+		return nil, err
+	}
+
+	query := `
+	SELECT t.entry_id, t.employee_id, t.shift_id, t.clock_in, t.clock_out, ` + entryHours + `
+	FROM time_entries t
+	WHERE t.employee_id = $1
+	  AND ($2::date IS NULL OR t.clock_in >= $2::date)
+	  AND ($3::date IS NULL OR t.clock_in < $3::date + 1)
+	ORDER BY t.clock_in DESC, t.entry_id DESC`
+	rows, err := repo.db.QueryContext(ctx, query, employeeID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query time entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.TimeEntry{}
+	for rows.Next() {
+		entry, err := scanTimeEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan time entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over time entries: %w", err)
+	}
+	return entries, nil
+}
+
+func scanEmployee(row interface{ Scan(...interface{}) error }) (models.Employee, error) {
+	var e models.Employee
+	var active bool
+	err := row.Scan(&e.ID, &e.Name, &e.Email, &e.Phone, &e.Role, &e.HourlyRate, &active, &e.CreatedAt)
+	e.Active = &active
+	return e, err
+}
+
+func scanShift(row interface{ Scan(...interface{}) error }) (models.Shift, error) {
+	var s models.Shift
+	err := row.Scan(&s.ID, &s.EmployeeID, &s.EmployeeName, &s.StartsAt, &s.EndsAt, &s.Note, &s.CreatedAt)
+	return s, err
+}
+
+func scanTimeEntry(row interface{ Scan(...interface{}) error }) (models.TimeEntry, error) {
+	var t models.TimeEntry
+	err := row.Scan(&t.ID, &t.EmployeeID, &t.ShiftID, &t.ClockIn, &t.ClockOut, &t.Hours)
+	return t, err
+}
+
+// employeeWriteError translates constraint violations on employees and on
+// the rows attributed to them. employeeID is the employee being referenced,
+// if any.
+func employeeWriteError(message string, err error, employeeID *int) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch {
+		case pqErr.Code == "23505" && pqErr.Table == "employees":
+			return models.Conflict("employee with this email already exists")
+		case pqErr.Code == "23503" && strings.HasSuffix(pqErr.Constraint, "employee_id_fkey") && employeeID != nil:
+			return models.NotFound("employee with ID %d not found", *employeeID)
+		}
+	}
+	return fmt.Errorf("%s: %w", message, err)
+}
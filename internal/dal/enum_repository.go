@@ -0,0 +1,50 @@
+package dal
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+type EnumRepository struct {
+	db *sql.DB
+}
+
+type EnumInterface interface {
+	List(ctx context.Context) (map[string][]string, error)
+}
+
+func NewEnumRepository(db *sql.DB) (*EnumRepository, error) {
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("unable to connect to database: %v", err)
+	}
+
+	return &EnumRepository{db: db}, nil
+}
+
+// List returns the values of every enum type of the database, by type name,
+// in the order they were declared.
+func (repo *EnumRepository) List(ctx context.Context) (map[string][]string, error) {
+	rows, err := repo.db.QueryContext(ctx, `
+	SELECT t.typname, e.enumlabel
+	FROM pg_type t
+	JOIN pg_enum e ON e.enumtypid = t.oid
+	ORDER BY t.typname, e.enumsortorder`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query enums: %w", err)
+	}
+	defer rows.Close()
+
+	enums := make(map[string][]string)
+	for rows.Next() {
+		var name, value string
+		if err := rows.Scan(&name, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan enum value: %w", err)
+		}
+		enums[name] = append(enums[name], value)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over enums: %w", err)
+	}
+	return enums, nil
+}
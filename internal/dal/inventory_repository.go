@@ -1,10 +1,16 @@
 package dal
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"frappuccino/internal/utils"
 	"frappuccino/models"
+	"math"
+	"strings"
+
+	"github.com/lib/pq"
 )
 
 type InventoryRepository struct {
@@ -12,14 +18,23 @@ type InventoryRepository struct {
 }
 
 type InventoryInterface interface {
-	Create(ingredient models.InventoryItem) (int, error)
-	GetByID(ingID int) (models.InventoryItem, error)
-	Update(ingredient models.InventoryItem, id int) error
-	Delete(ingID int) error
-	List() ([]models.InventoryItem, error)
-	CheckAndReserveInventory(tx *sql.Tx, items []models.OrderItem) (float64, bool, []models.InventoryUpdate, error)
+	Create(ctx context.Context, ingredient models.InventoryItem) (int, error)
+	GetByID(ctx context.Context, ingID int) (models.InventoryItem, error)
+	Update(ctx context.Context, ingredient models.InventoryItem, id int) error
+	Delete(ctx context.Context, ingID int) error
+	List(ctx context.Context) ([]models.InventoryItem, error)
+	ListPage(ctx context.Context, filter models.InventoryFilter) ([]models.InventoryItem, int, error)
+	CheckAndReserveInventory(ctx context.Context, tx *sql.Tx, items []models.OrderItem, employeeID *int) ([]models.InventoryUpdate, []models.StockShortage, error)
+	AdjustQuantity(ctx context.Context, tx *sql.Tx, ingredientID int, delta float64, reason string, employeeID *int) error
+	ListTransactions(ctx context.Context, filter models.TransactionFilter) ([]models.InventoryTransaction, error)
+	LedgerBalance(ctx context.Context, ingredientID int) (float64, error)
+	Existing(ctx context.Context, ids []int) (map[int]bool, error)
 }
 
+// signedChange turns a ledger row into a signed quantity; quantity_change is
+// stored as an absolute value and transaction_type carries the direction.
+const signedChange = `CASE WHEN it.transaction_type = 'deduction' THEN -ABS(it.quantity_change) ELSE ABS(it.quantity_change) END`
+
 func NewInventoryRepository(db *sql.DB) (*InventoryRepository, error) {
 	if err := db.Ping(); err != nil {
 		return nil, fmt.Errorf("unable to connect to database: %v", err)
@@ -28,42 +43,64 @@ func NewInventoryRepository(db *sql.DB) (*InventoryRepository, error) {
 	return &InventoryRepository{db: db}, nil
 }
 
-func (repo *InventoryRepository) Create(ingredient models.InventoryItem) (int, error) {
+func (repo *InventoryRepository) Create(ctx context.Context, ingredient models.InventoryItem) (int, error) {
 	var exists bool
 	queryCheck := `SELECT EXISTS(SELECT 1 FROM inventory WHERE name = $1)`
-	err := repo.db.QueryRow(queryCheck, ingredient.Name).Scan(&exists)
+	err := repo.db.QueryRowContext(ctx, queryCheck, ingredient.Name).Scan(&exists)
 	if err != nil {
 		return 0, fmt.Errorf("failed to check ingredient existence: %w", err)
 	}
 	if exists {
-		return 0, errors.New("ingredient with this name already exists")
+		return 0, models.Conflict("ingredient with this name already exists")
+	}
+
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
 
 	var id int
-	queryInsert := `INSERT INTO inventory (name, quantity, unit) VALUES ($1, $2, $3) RETURNING ingredient_id`
-	err = repo.db.QueryRow(queryInsert, ingredient.Name, ingredient.Quantity, ingredient.Unit).Scan(&id)
+	queryInsert := `INSERT INTO inventory (name, quantity, unit, cost_per_unit) VALUES ($1, $2, $3, $4) RETURNING ingredient_id`
+	err = tx.QueryRowContext(ctx, queryInsert, ingredient.Name, ingredient.Quantity, ingredient.Unit, ingredient.Price).Scan(&id)
 	if err != nil {
 		return 0, fmt.Errorf("failed to create ingredient: %w", err)
 	}
 
+	if err := insertTransaction(ctx, tx, id, ingredient.Quantity, "opening_balance", utils.ActorFrom(ctx).EmployeeID); err != nil {
+		return 0, err
+	}
+
+	after, err := snapshot(ctx, tx, ingredientSnapshot, id)
+	if err != nil {
+		return 0, err
+	}
+	if err := recordAudit(ctx, tx, models.AuditCreate, models.AuditIngredient, id, nil, after); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return id, nil
 }
 
-func (repo *InventoryRepository) GetByID(ingID int) (models.InventoryItem, error) {
+func (repo *InventoryRepository) GetByID(ctx context.Context, ingID int) (models.InventoryItem, error) {
 	var ingredient models.InventoryItem
-	query := `SELECT ingredient_id, name, quantity, unit FROM inventory WHERE ingredient_id = $1`
-	row := repo.db.QueryRow(query, ingID)
-	if err := row.Scan(&ingredient.IngredientID, &ingredient.Name, &ingredient.Quantity, &ingredient.Unit); err != nil {
+	query := `SELECT ingredient_id, name, quantity, unit, cost_per_unit, last_updated FROM inventory WHERE ingredient_id = $1`
+	row := repo.db.QueryRowContext(ctx, query, ingID)
+	if err := row.Scan(&ingredient.IngredientID, &ingredient.Name, &ingredient.Quantity, &ingredient.Unit, &ingredient.Price, &ingredient.LastUpdated); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return models.InventoryItem{}, errors.New("ingredient not found")
+			return models.InventoryItem{}, models.NotFound("ingredient with ID %d not found", ingID)
 		}
 		return models.InventoryItem{}, err
 	}
 	return ingredient, nil
 }
 
-func (repo *InventoryRepository) Update(ingredient models.InventoryItem, id int) error {
-	tx, err := repo.db.Begin()
+func (repo *InventoryRepository) Update(ctx context.Context, ingredient models.InventoryItem, id int) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
@@ -71,16 +108,21 @@ func (repo *InventoryRepository) Update(ingredient models.InventoryItem, id int)
 
 	var oldQuantity float64
 	queryGet := `SELECT quantity FROM inventory WHERE ingredient_id = $1`
-	err = tx.QueryRow(queryGet, id).Scan(&oldQuantity)
+	err = tx.QueryRowContext(ctx, queryGet, id).Scan(&oldQuantity)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return errors.New("ingredient not found")
+			return models.NotFound("ingredient not found")
 		}
 		return fmt.Errorf("failed to get current quantity: %w", err)
 	}
 
-	queryUpdate := `UPDATE inventory SET name = $1, quantity = $2, unit = $3 WHERE ingredient_id = $4`
-	result, err := tx.Exec(queryUpdate, ingredient.Name, ingredient.Quantity, ingredient.Unit, id)
+	before, err := snapshot(ctx, tx, ingredientSnapshot, id)
+	if err != nil {
+		return err
+	}
+
+	queryUpdate := `UPDATE inventory SET name = $1, quantity = $2, unit = $3, cost_per_unit = $4, last_updated = CURRENT_TIMESTAMP WHERE ingredient_id = $5`
+	result, err := tx.ExecContext(ctx, queryUpdate, ingredient.Name, ingredient.Quantity, ingredient.Unit, ingredient.Price, id)
 	if err != nil {
 		return fmt.Errorf("failed to update inventory: %w", err)
 	}
@@ -90,19 +132,24 @@ func (repo *InventoryRepository) Update(ingredient models.InventoryItem, id int)
 		return fmt.Errorf("failed to get affected rows: %w", err)
 	}
 	if numRows == 0 {
-		return errors.New("ingredient not found")
+		return models.NotFound("ingredient not found")
 	}
 
-	quantityChange := ingredient.Quantity - oldQuantity
-	transactionType := "addition"
-	if quantityChange < 0 {
-		transactionType = "deduction"
+	if quantityChange := ingredient.Quantity - oldQuantity; quantityChange != 0 {
+		if err := insertTransaction(ctx, tx, id, quantityChange, "manual_adjustment", utils.ActorFrom(ctx).EmployeeID); err != nil {
+			return err
+		}
+	}
+	if err := enqueueStockout(ctx, tx, id, ingredient.Name, oldQuantity, ingredient.Quantity); err != nil {
+		return err
 	}
 
-	queryInsert := `INSERT INTO inventory_transactions (ingredient_id, quantity_change, transaction_type) VALUES ($1, $2, $3)`
-	_, err = tx.Exec(queryInsert, id, quantityChange, transactionType)
+	after, err := snapshot(ctx, tx, ingredientSnapshot, id)
 	if err != nil {
-		return fmt.Errorf("failed to insert transaction record: %w", err)
+		return err
+	}
+	if err := recordAudit(ctx, tx, models.AuditUpdate, models.AuditIngredient, id, before, after); err != nil {
+		return err
 	}
 
 	if err := tx.Commit(); err != nil {
@@ -112,9 +159,20 @@ func (repo *InventoryRepository) Update(ingredient models.InventoryItem, id int)
 	return nil
 }
 
-func (repo *InventoryRepository) Delete(ingID int) error {
+func (repo *InventoryRepository) Delete(ctx context.Context, ingID int) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	before, err := snapshot(ctx, tx, ingredientSnapshot, ingID)
+	if err != nil {
+		return err
+	}
+
 	query := `DELETE FROM inventory WHERE ingredient_id = $1`
-	result, err := repo.db.Exec(query, ingID)
+	result, err := tx.ExecContext(ctx, query, ingID)
 	if err != nil {
 		return err
 	}
@@ -124,14 +182,22 @@ func (repo *InventoryRepository) Delete(ingID int) error {
 		return err
 	}
 	if numRows == 0 {
-		return errors.New("ingredient not found")
+		return models.NotFound("ingredient not found")
+	}
+
+	if err := recordAudit(ctx, tx, models.AuditDelete, models.AuditIngredient, ingID, before, nil); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 	return nil
 }
 
-func (repo *InventoryRepository) List() ([]models.InventoryItem, error) {
-	query := `SELECT ingredient_id, name, quantity, unit FROM inventory`
-	rows, err := repo.db.Query(query)
+func (repo *InventoryRepository) List(ctx context.Context) ([]models.InventoryItem, error) {
+	query := `SELECT ingredient_id, name, quantity, unit, cost_per_unit, last_updated FROM inventory ORDER BY ingredient_id`
+	rows, err := repo.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -140,7 +206,7 @@ func (repo *InventoryRepository) List() ([]models.InventoryItem, error) {
 	var ingredients []models.InventoryItem
 	for rows.Next() {
 		var ingredient models.InventoryItem
-		if err := rows.Scan(&ingredient.IngredientID, &ingredient.Name, &ingredient.Quantity, &ingredient.Unit); err != nil {
+		if err := rows.Scan(&ingredient.IngredientID, &ingredient.Name, &ingredient.Quantity, &ingredient.Unit, &ingredient.Price, &ingredient.LastUpdated); err != nil {
 			return nil, err
 		}
 		ingredients = append(ingredients, ingredient)
@@ -151,73 +217,277 @@ func (repo *InventoryRepository) List() ([]models.InventoryItem, error) {
 	return ingredients, nil
 }
 
+// inventorySortColumns maps public sort keys to columns, so that only known
+// identifiers are ever put into ORDER BY.
+var inventorySortColumns = map[string]string{
+	"name":         "name",
+	"quantity":     "quantity",
+	"cost":         "cost_per_unit",
+	"last_updated": "last_updated",
+}
+
+// ListPage returns one page of inventory items and the number of items
+// matching the filter. Ties are broken by ingredient_id so pages are stable.
+func (repo *InventoryRepository) ListPage(ctx context.Context, filter models.InventoryFilter) ([]models.InventoryItem, int, error) {
+	where := `
+	WHERE ($1 = '' OR name ILIKE '%' || $1 || '%' ESCAPE '\')
+	  AND ($2 = '' OR unit::text = $2)
+	  AND ($3::numeric IS NULL OR quantity < $3::numeric)
+	  AND (NOT $4 OR quantity > 0)`
+	search := likeEscaper.Replace(filter.Search)
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM inventory` + where
+	err := repo.db.QueryRowContext(ctx, countQuery, search, filter.Unit, filter.BelowThreshold, filter.InStockOnly).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count inventory items: %w", err)
+	}
+
+	column, ok := inventorySortColumns[filter.SortBy]
+	if !ok {
+		column = "name"
+	}
+	direction := "ASC"
+	if filter.Descending {
+		direction = "DESC"
+	}
+
+	query := `SELECT ingredient_id, name, quantity, unit, cost_per_unit, last_updated FROM inventory` + where +
+		fmt.Sprintf(` ORDER BY %s %s NULLS LAST, ingredient_id %s LIMIT $5 OFFSET $6`, column, direction, direction)
+	rows, err := repo.db.QueryContext(ctx, query, search, filter.Unit, filter.BelowThreshold, filter.InStockOnly,
+		filter.PageSize, (filter.Page-1)*filter.PageSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query inventory items: %w", err)
+	}
+	defer rows.Close()
+
+	ingredients := []models.InventoryItem{}
+	for rows.Next() {
+		var ingredient models.InventoryItem
+		if err := rows.Scan(&ingredient.IngredientID, &ingredient.Name, &ingredient.Quantity, &ingredient.Unit, &ingredient.Price, &ingredient.LastUpdated); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan inventory item: %w", err)
+		}
+		ingredients = append(ingredients, ingredient)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating over inventory items: %w", err)
+	}
+	return ingredients, total, nil
+}
+
+// Existing returns which of the ingredient ids are in the inventory.
+func (repo *InventoryRepository) Existing(ctx context.Context, ids []int) (map[int]bool, error) {
+	rows, err := repo.db.QueryContext(ctx, `SELECT ingredient_id FROM inventory WHERE ingredient_id = ANY($1)`, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ingredients: %w", err)
+	}
+	defer rows.Close()
+
+	existing := make(map[int]bool)
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan ingredient id: %w", err)
+		}
+		existing[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over ingredients: %w", err)
+	}
+	return existing, nil
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
 func (repo *InventoryRepository) Close() error {
 	return repo.db.Close()
 }
 
-func (r *InventoryRepository) CheckAndReserveInventory(tx *sql.Tx, items []models.OrderItem) (float64, bool, []models.InventoryUpdate, error) {
-	var total float64
-	inventoryMap := make(map[int]*models.InventoryUpdate) // Map для агрегации ингредиентов
-
+// CheckAndReserveInventory deducts the ingredients of the items within tx.
+// The inventory rows are locked in ingredient_id order first, so concurrent
+// orders wait for each other instead of both taking the last of the stock.
+// When anything is short nothing is deducted and the shortages are returned.
+func (r *InventoryRepository) CheckAndReserveInventory(ctx context.Context, tx *sql.Tx, items []models.OrderItem, employeeID *int) ([]models.InventoryUpdate, []models.StockShortage, error) {
+	menuItemIDs := make([]int64, 0, len(items))
+	quantities := make([]int64, 0, len(items))
 	for _, item := range items {
-		if item.ProductID == 0 {
-			return 0, false, nil, fmt.Errorf("ProductID is empty")
-		}
+		menuItemIDs = append(menuItemIDs, int64(item.ProductID))
+		quantities = append(quantities, int64(item.Quantity))
+	}
 
-		var availableQuantity, ingredientQuantity int
-		var price float64
-		var ingredientID int
-		var name string
-
-		err := tx.QueryRow(`
-			SELECT i.ingredient_id, i.name, i.quantity, mii.quantity AS ingredient_quantity, mi.price
-			FROM inventory i
-			JOIN menu_item_ingredients mii ON i.ingredient_id = mii.inventory_id
-			JOIN menu_items mi ON mi.menu_item_id = mii.menu_item_id
-			WHERE mi.menu_item_id = $1;
-		`, item.ProductID).Scan(&ingredientID, &name, &availableQuantity, &ingredientQuantity, &price)
-
-		if err == sql.ErrNoRows {
-			return 0, false, nil, fmt.Errorf("no inventory found for ProductID: %d", item.ProductID)
-		} else if err != nil {
-			return 0, false, nil, fmt.Errorf("error fetching inventory: %w", err)
+	rows, err := tx.QueryContext(ctx, `
+		WITH required AS (
+			SELECT mii.inventory_id, SUM(mii.quantity * item.quantity) AS quantity
+			FROM unnest($1::int[], $2::int[]) AS item(menu_item_id, quantity)
+			JOIN menu_item_ingredients mii ON mii.menu_item_id = item.menu_item_id
+			GROUP BY mii.inventory_id
+		)
+		SELECT i.ingredient_id, i.name, i.quantity, required.quantity
+		FROM inventory i
+		JOIN required ON required.inventory_id = i.ingredient_id
+		ORDER BY i.ingredient_id
+		FOR UPDATE OF i`, pq.Array(menuItemIDs), pq.Array(quantities))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to lock inventory: %w", err)
+	}
+	defer rows.Close()
+
+	var updates []models.InventoryUpdate
+	var shortages []models.StockShortage
+	for rows.Next() {
+		var update models.InventoryUpdate
+		var available float64
+		if err := rows.Scan(&update.IngredientID, &update.Name, &available, &update.QuantityUsed); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan inventory: %w", err)
+		}
+		if available < update.QuantityUsed {
+			shortages = append(shortages, models.StockShortage{
+				IngredientID: update.IngredientID,
+				Name:         update.Name,
+				Required:     update.QuantityUsed,
+				Available:    available,
+			})
+			continue
 		}
+		update.Remaining = available - update.QuantityUsed
+		updates = append(updates, update)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error iterating over inventory: %w", err)
+	}
+	rows.Close()
+	if len(shortages) > 0 {
+		return nil, shortages, nil
+	}
 
-		requiredQuantity := item.Quantity * ingredientQuantity
-		if availableQuantity < requiredQuantity {
-			return 0, false, nil, nil // Недостаточно ингредиентов
+	for _, update := range updates {
+		if err := adjustQuantity(ctx, tx, update.IngredientID, -update.QuantityUsed, "order", employeeID); err != nil {
+			return nil, nil, err
 		}
+	}
+	return updates, nil, nil
+}
 
-		_, err = tx.Exec(`
-			UPDATE inventory 
-			SET quantity = quantity - $1
-			WHERE ingredient_id = $2;
-		`, requiredQuantity, ingredientID)
+// AdjustQuantity changes the stock of an ingredient by delta and records the
+// movement in the ledger within the given transaction, attributed to
+// employeeID when it is set.
+func (r *InventoryRepository) AdjustQuantity(ctx context.Context, tx *sql.Tx, ingredientID int, delta float64, reason string, employeeID *int) error {
+	return adjustQuantity(ctx, tx, ingredientID, delta, reason, employeeID)
+}
 
-		if err != nil {
-			return 0, false, nil, fmt.Errorf("error updating inventory: %w", err)
-		}
+// adjustQuantity writes the movement to the ledger and the audit log, so
+// every change of stock is attributed to whoever made it.
+func adjustQuantity(ctx context.Context, tx *sql.Tx, ingredientID int, delta float64, reason string, employeeID *int) error {
+	before, err := snapshot(ctx, tx, ingredientSnapshot, ingredientID)
+	if err != nil {
+		return err
+	}
 
-		// Агрегируем данные по ingredient_id
-		if update, exists := inventoryMap[ingredientID]; exists {
-			update.QuantityUsed += requiredQuantity // Увеличиваем использованное количество
-		} else {
-			inventoryMap[ingredientID] = &models.InventoryUpdate{
-				IngredientID: ingredientID,
-				Name:         name,
-				QuantityUsed: requiredQuantity,
-				Remaining:    availableQuantity - requiredQuantity, // Фиксируем остаток при первой встрече ингредиента
-			}
+	var name string
+	var quantity float64
+	err = tx.QueryRowContext(ctx, `
+		UPDATE inventory
+		SET quantity = quantity + $1, last_updated = CURRENT_TIMESTAMP
+		WHERE ingredient_id = $2
+		RETURNING name, quantity;
+	`, delta, ingredientID).Scan(&name, &quantity)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.NotFound("ingredient not found")
 		}
+		return fmt.Errorf("error updating inventory: %w", err)
+	}
+
+	if err := insertTransaction(ctx, tx, ingredientID, delta, reason, employeeID); err != nil {
+		return err
+	}
+	if err := enqueueStockout(ctx, tx, ingredientID, name, quantity-delta, quantity); err != nil {
+		return err
+	}
 
-		total += price * float64(item.Quantity)
+	after, err := snapshot(ctx, tx, ingredientSnapshot, ingredientID)
+	if err != nil {
+		return err
 	}
+	return recordAudit(ctx, tx, models.AuditUpdate, models.AuditIngredient, ingredientID, before, after)
+}
 
-	// Конвертируем map в slice
-	var inventoryUpdates []models.InventoryUpdate
-	for _, update := range inventoryMap {
-		inventoryUpdates = append(inventoryUpdates, *update)
+// insertTransaction records a ledger row. Additions keep the unit cost of the
+// ingredient at that moment, which the valuation report averages over.
+func insertTransaction(ctx context.Context, tx *sql.Tx, ingredientID int, delta float64, reason string, employeeID *int) error {
+	transactionType := "addition"
+	if delta < 0 {
+		transactionType = "deduction"
 	}
 
-	return total, true, inventoryUpdates, nil
+	query := `
+	INSERT INTO inventory_transactions (ingredient_id, quantity_change, transaction_type, reason, unit_cost, employee_id)
+	SELECT $1, $2, $3::type_of_transaction, $4::transaction_reason, CASE WHEN $5 THEN cost_per_unit END, $6::int
+	FROM inventory WHERE ingredient_id = $1`
+	if _, err := tx.ExecContext(ctx, query, ingredientID, math.Abs(delta), transactionType, reason, delta > 0, employeeID); err != nil {
+		return employeeWriteError("failed to insert transaction record", err, employeeID)
+	}
+	return nil
+}
+
+// ListTransactions returns ledger rows ordered by transaction_id. The running
+// balance of a row is summed over the ledger of its ingredient up to that
+// row, ignoring the filters, so it always equals the stock right after that
+// movement. Only the rows of the page are summed.
+func (repo *InventoryRepository) ListTransactions(ctx context.Context, filter models.TransactionFilter) ([]models.InventoryTransaction, error) {
+	query := `
+	WITH page AS (
+		SELECT it.transaction_id, it.ingredient_id, ABS(it.quantity_change) AS quantity_change,
+		       it.transaction_type, it.reason, it.employee_id, it.transaction_date
+		FROM inventory_transactions it
+		WHERE ($1::int IS NULL OR it.ingredient_id = $1)
+		  AND ($2::date IS NULL OR it.transaction_date >= $2::date)
+		  AND ($3::date IS NULL OR it.transaction_date < $3::date + 1)
+		  AND ($4::type_of_transaction IS NULL OR it.transaction_type = $4::type_of_transaction)
+		  AND ($5::transaction_reason IS NULL OR it.reason = $5::transaction_reason)
+		  AND it.transaction_id > $6
+		ORDER BY it.transaction_id
+		LIMIT $7
+	)
+	SELECT p.transaction_id, p.ingredient_id, i.name, p.quantity_change, p.transaction_type, p.reason,
+	       p.employee_id, p.transaction_date, b.balance
+	FROM page p
+	JOIN inventory i ON i.ingredient_id = p.ingredient_id
+	CROSS JOIN LATERAL (
+		SELECT SUM(` + signedChange + `) AS balance
+		FROM inventory_transactions it
+		WHERE it.ingredient_id = p.ingredient_id AND it.transaction_id <= p.transaction_id
+	) b
+	ORDER BY p.transaction_id`
+
+	rows, err := repo.db.QueryContext(ctx, query, filter.IngredientID, filter.StartDate, filter.EndDate,
+		filter.Type, filter.Reason, filter.Cursor, filter.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query inventory transactions: %w", err)
+	}
+	defer rows.Close()
+
+	transactions := []models.InventoryTransaction{}
+	for rows.Next() {
+		var t models.InventoryTransaction
+		if err := rows.Scan(&t.ID, &t.IngredientID, &t.IngredientName, &t.QuantityChange, &t.Type,
+			&t.Reason, &t.EmployeeID, &t.CreatedAt, &t.Balance); err != nil {
+			return nil, fmt.Errorf("failed to scan inventory transaction: %w", err)
+		}
+		transactions = append(transactions, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over inventory transactions: %w", err)
+	}
+	return transactions, nil
+}
+
+// LedgerBalance replays the whole ledger of an ingredient from zero.
+func (repo *InventoryRepository) LedgerBalance(ctx context.Context, ingredientID int) (float64, error) {
+	var balance float64
+	query := `SELECT COALESCE(SUM(` + signedChange + `), 0) FROM inventory_transactions it WHERE it.ingredient_id = $1`
+	if err := repo.db.QueryRowContext(ctx, query, ingredientID).Scan(&balance); err != nil {
+		return 0, fmt.Errorf("failed to compute ledger balance: %w", err)
+	}
+	return balance, nil
 }
@@ -0,0 +1,50 @@
+package dal
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// listen subscribes to a notification channel on a dedicated connection.
+// The returned channel is signalled on every notification and after every
+// reconnect, when notifications may have been missed. It is closed when ctx
+// is done. Connection errors are logged to logger.
+func listen(ctx context.Context, dsn, channel string, logger *slog.Logger) (<-chan struct{}, error) {
+	listener := pq.NewListener(dsn, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			logger.Error("Notification listener failed", slog.String("channel", channel), slog.Any("error", err))
+		}
+	})
+	if err := listener.Listen(channel); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to listen to %s: %w", channel, err)
+	}
+
+	signals := make(chan struct{}, 1)
+	go func() {
+		defer close(signals)
+		defer listener.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-listener.Notify:
+				// A nil notification means the connection was re-established
+			case <-time.After(time.Minute):
+				go listener.Ping()
+				continue
+			}
+			select {
+			case signals <- struct{}{}:
+			default:
+				// A signal is already pending, the receiver will read everything
+			}
+		}
+	}()
+
+	return signals, nil
+}
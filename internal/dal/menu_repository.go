@@ -1,6 +1,7 @@
 package dal
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
@@ -15,11 +16,11 @@ type MenuRepository struct {
 }
 
 type MenuInterface interface {
-	Create(menuItem models.MenuItem) (int, error)
-	GetByID(ingID int) (models.MenuItem, error)
-	Update(item models.MenuItem, id int) error
-	Delete(menuID int) error
-	List() ([]models.MenuItem, error)
+	Create(ctx context.Context, menuItem models.MenuItem) (int, error)
+	GetByID(ctx context.Context, ingID int) (models.MenuItem, error)
+	Update(ctx context.Context, item models.MenuItem, id int) error
+	Delete(ctx context.Context, menuID int) error
+	List(ctx context.Context) ([]models.MenuItem, error)
 }
 
 func NewMenuRepository(db *sql.DB) (*MenuRepository, error) {
@@ -30,29 +31,29 @@ func NewMenuRepository(db *sql.DB) (*MenuRepository, error) {
 	return &MenuRepository{db: db}, nil
 }
 
-func (repo *MenuRepository) Create(menuItem models.MenuItem) (int, error) {
-	tx, err := repo.db.Begin()
+func (repo *MenuRepository) Create(ctx context.Context, menuItem models.MenuItem) (int, error) {
+	tx, err := repo.db.BeginTx(ctx, nil)
 	if err != nil {
 		return 0, fmt.Errorf("failed to start create transaction: %w", err)
 	}
 
 	var exists bool
 	checkQuery := `SELECT EXISTS(SELECT 1 FROM menu_items WHERE name = $1)`
-	err = tx.QueryRow(checkQuery, menuItem.Name).Scan(&exists)
+	err = tx.QueryRowContext(ctx, checkQuery, menuItem.Name).Scan(&exists)
 	if err != nil {
 		tx.Rollback()
 		return 0, fmt.Errorf("failed to check menu item existence: %w", err)
 	}
 	if exists {
 		tx.Rollback()
-		return 0, fmt.Errorf("menu item already exists")
+		return 0, models.Conflict("menu item already exists")
 	}
 
 	query := `INSERT INTO menu_items (name, description, price, categories, allergens) 
 			  VALUES ($1, $2, $3, $4, $5) RETURNING menu_item_id`
 
 	var menuItemID int
-	err = tx.QueryRow(query, menuItem.Name, menuItem.Description, menuItem.Price,
+	err = tx.QueryRowContext(ctx, query, menuItem.Name, menuItem.Description, menuItem.Price,
 		pq.Array(menuItem.Category), pq.Array(menuItem.Allergens)).Scan(&menuItemID)
 	if err != nil {
 		tx.Rollback()
@@ -62,13 +63,23 @@ func (repo *MenuRepository) Create(menuItem models.MenuItem) (int, error) {
 	ingredientQuery := `INSERT INTO menu_item_ingredients (menu_item_id, inventory_id, quantity) 
 						VALUES ($1, $2, $3)`
 	for _, ingredient := range menuItem.Ingredients {
-		_, err = tx.Exec(ingredientQuery, menuItemID, ingredient.IngredientID, ingredient.Quantity)
+		_, err = tx.ExecContext(ctx, ingredientQuery, menuItemID, ingredient.IngredientID, ingredient.Quantity)
 		if err != nil {
 			tx.Rollback()
 			return 0, fmt.Errorf("error adding ingredient %d: %w", ingredient.IngredientID, err)
 		}
 	}
 
+	after, err := snapshot(ctx, tx, menuItemSnapshot, menuItemID)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	if err := recordAudit(ctx, tx, models.AuditCreate, models.AuditMenuItem, menuItemID, nil, after); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return 0, fmt.Errorf("transaction commit failed: %w", err)
 	}
@@ -76,7 +87,7 @@ func (repo *MenuRepository) Create(menuItem models.MenuItem) (int, error) {
 	return menuItemID, nil
 }
 
-func (repo *MenuRepository) GetByID(menuID int) (models.MenuItem, error) {
+func (repo *MenuRepository) GetByID(ctx context.Context, menuID int) (models.MenuItem, error) {
 	query := `
 	SELECT 
 	    mi.menu_item_id, 
@@ -111,10 +122,10 @@ func (repo *MenuRepository) GetByID(menuID int) (models.MenuItem, error) {
 		allergens       []string
 	)
 
-	row := repo.db.QueryRow(query, menuID)
+	row := repo.db.QueryRowContext(ctx, query, menuID)
 	if err := row.Scan(&id, &name, &description, &price, &ingredientsJSON, pq.Array(&categories), pq.Array(&allergens)); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return models.MenuItem{}, errors.New("menu item not found")
+			return models.MenuItem{}, models.NotFound("menu item with ID %d not found", menuID)
 		}
 		return models.MenuItem{}, fmt.Errorf("failed to scan row: %w", err)
 	}
@@ -137,8 +148,8 @@ func (repo *MenuRepository) GetByID(menuID int) (models.MenuItem, error) {
 	return item, nil
 }
 
-func (repo *MenuRepository) Update(item models.MenuItem, id int) error {
-	tx, err := repo.db.Begin()
+func (repo *MenuRepository) Update(ctx context.Context, item models.MenuItem, id int) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to begin update transaction: %w", err)
 	}
@@ -146,14 +157,19 @@ func (repo *MenuRepository) Update(item models.MenuItem, id int) error {
 
 	var oldPrice float64
 	getPriceQuery := `SELECT price FROM menu_items WHERE menu_item_id = $1`
-	err = tx.QueryRow(getPriceQuery, id).Scan(&oldPrice)
+	err = tx.QueryRowContext(ctx, getPriceQuery, id).Scan(&oldPrice)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return errors.New("menu item not found")
+			return models.NotFound("menu item not found")
 		}
 		return fmt.Errorf("failed to get current price: %w", err)
 	}
 
+	before, err := snapshot(ctx, tx, menuItemSnapshot, id)
+	if err != nil {
+		return err
+	}
+
 	updateMenuQuery := `
 	UPDATE menu_items 
 	SET 
@@ -164,7 +180,7 @@ func (repo *MenuRepository) Update(item models.MenuItem, id int) error {
 		allergens = $5 
 	WHERE 
 	    menu_item_id = $6`
-	_, err = tx.Exec(updateMenuQuery, item.Name, item.Price, item.Description, pq.Array(item.Category), pq.Array(item.Allergens), id)
+	_, err = tx.ExecContext(ctx, updateMenuQuery, item.Name, item.Price, item.Description, pq.Array(item.Category), pq.Array(item.Allergens), id)
 	if err != nil {
 		return fmt.Errorf("failed to update menu item: %w", err)
 	}
@@ -173,14 +189,14 @@ func (repo *MenuRepository) Update(item models.MenuItem, id int) error {
 		insertPriceHistoryQuery := `
 		INSERT INTO price_history (menu_item_id, old_price, new_price, changed_at)
 		VALUES ($1, $2, $3, NOW())`
-		_, err = tx.Exec(insertPriceHistoryQuery, id, oldPrice, item.Price)
+		_, err = tx.ExecContext(ctx, insertPriceHistoryQuery, id, oldPrice, item.Price)
 		if err != nil {
 			return fmt.Errorf("failed to insert price history record: %w", err)
 		}
 	}
 
 	deleteIngredientsQuery := `DELETE FROM menu_item_ingredients WHERE menu_item_id = $1`
-	_, err = tx.Exec(deleteIngredientsQuery, id)
+	_, err = tx.ExecContext(ctx, deleteIngredientsQuery, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete old ingredients: %w", err)
 	}
@@ -192,12 +208,20 @@ func (repo *MenuRepository) Update(item models.MenuItem, id int) error {
 		if ingredient.Quantity == 0 {
 			fmt.Println("Warning: Quantity is zero for ingredient:", ingredient.IngredientID)
 		}
-		_, err := tx.Exec(insertIngredientsQuery, id, ingredient.IngredientID, ingredient.Quantity)
+		_, err := tx.ExecContext(ctx, insertIngredientsQuery, id, ingredient.IngredientID, ingredient.Quantity)
 		if err != nil {
 			return fmt.Errorf("failed to insert ingredient: %w", err)
 		}
 	}
 
+	after, err := snapshot(ctx, tx, menuItemSnapshot, id)
+	if err != nil {
+		return err
+	}
+	if err := recordAudit(ctx, tx, models.AuditUpdate, models.AuditMenuItem, id, before, after); err != nil {
+		return err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
@@ -205,26 +229,26 @@ func (repo *MenuRepository) Update(item models.MenuItem, id int) error {
 	return nil
 }
 
-func (repo *MenuRepository) Delete(menuID int) error {
-	tx, err := repo.db.Begin()
+func (repo *MenuRepository) Delete(ctx context.Context, menuID int) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to begin delete transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		}
-	}()
+	before, err := snapshot(ctx, tx, menuItemSnapshot, menuID)
+	if err != nil {
+		return err
+	}
 
 	ingredientQuery := `DELETE FROM menu_item_ingredients WHERE menu_item_id = $1`
-	_, err = tx.Exec(ingredientQuery, menuID)
+	_, err = tx.ExecContext(ctx, ingredientQuery, menuID)
 	if err != nil {
 		return fmt.Errorf("failed to delete ingredients: %w", err)
 	}
 
 	menuQuery := `DELETE FROM menu_items WHERE menu_item_id = $1`
-	result, err := tx.Exec(menuQuery, menuID)
+	result, err := tx.ExecContext(ctx, menuQuery, menuID)
 	if err != nil {
 		return fmt.Errorf("failed to delete menu_item: %w", err)
 	}
@@ -235,7 +259,11 @@ func (repo *MenuRepository) Delete(menuID int) error {
 	}
 
 	if numRows == 0 {
-		return errors.New("menu item not found")
+		return models.NotFound("menu item not found")
+	}
+
+	if err := recordAudit(ctx, tx, models.AuditDelete, models.AuditMenuItem, menuID, before, nil); err != nil {
+		return err
 	}
 
 	if err := tx.Commit(); err != nil {
@@ -244,7 +272,7 @@ func (repo *MenuRepository) Delete(menuID int) error {
 	return nil
 }
 
-func (repo *MenuRepository) List() ([]models.MenuItem, error) {
+func (repo *MenuRepository) List(ctx context.Context) ([]models.MenuItem, error) {
 	query := `
 	SELECT 
 	    mi.menu_item_id, 
@@ -266,7 +294,7 @@ func (repo *MenuRepository) List() ([]models.MenuItem, error) {
 	GROUP BY 
 	    mi.menu_item_id
 	`
-	rows, err := repo.db.Query(query)
+	rows, err := repo.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,94 @@
+package dal
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"frappuccino/models"
+	"log/slog"
+
+	"github.com/lib/pq"
+)
+
+const orderEventsChannel = "order_events"
+
+type OrderEventRepository struct {
+	db     *sql.DB
+	dsn    string
+	logger *slog.Logger
+}
+
+type OrderEventInterface interface {
+	ListAfter(ctx context.Context, afterID int64, limit int) ([]models.OrderEvent, error)
+	ListByIDs(ctx context.Context, ids []int64) ([]models.OrderEvent, error)
+	LatestID(ctx context.Context) (int64, error)
+	Listen(ctx context.Context) (<-chan struct{}, error)
+}
+
+func NewOrderEventRepository(db *sql.DB, dsn string, logger *slog.Logger) (*OrderEventRepository, error) {
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("unable to connect to database: %v", err)
+	}
+
+	return &OrderEventRepository{db: db, dsn: dsn, logger: logger}, nil
+}
+
+func (repo *OrderEventRepository) ListAfter(ctx context.Context, afterID int64, limit int) ([]models.OrderEvent, error) {
+	query := `
+	SELECT event_id, order_id, event_type, payload, created_at
+	FROM order_events
+	WHERE event_id > $1
+	ORDER BY event_id
+	LIMIT $2`
+	return repo.list(ctx, query, afterID, limit)
+}
+
+// ListByIDs returns the events with the given ids that are visible, in id
+// order.
+func (repo *OrderEventRepository) ListByIDs(ctx context.Context, ids []int64) ([]models.OrderEvent, error) {
+	query := `
+	SELECT event_id, order_id, event_type, payload, created_at
+	FROM order_events
+	WHERE event_id = ANY($1)
+	ORDER BY event_id`
+	return repo.list(ctx, query, pq.Array(ids))
+}
+
+func (repo *OrderEventRepository) list(ctx context.Context, query string, args ...interface{}) ([]models.OrderEvent, error) {
+	rows, err := repo.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query order events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []models.OrderEvent
+	for rows.Next() {
+		var event models.OrderEvent
+		var payload []byte
+		if err := rows.Scan(&event.ID, &event.OrderID, &event.Type, &payload, &event.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan order event: %w", err)
+		}
+		event.Payload = payload
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over order events: %w", err)
+	}
+	return events, nil
+}
+
+func (repo *OrderEventRepository) LatestID(ctx context.Context) (int64, error) {
+	var id int64
+	if err := repo.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(event_id), 0) FROM order_events`).Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to get latest order event: %w", err)
+	}
+	return id, nil
+}
+
+// Listen subscribes to order event notifications on a dedicated connection.
+// The returned channel is signalled on every notification and after every
+// reconnect, when notifications may have been missed; receivers are expected
+// to read new events with ListAfter. It is closed when ctx is done.
+func (repo *OrderEventRepository) Listen(ctx context.Context) (<-chan struct{}, error) {
+	return listen(ctx, repo.dsn, orderEventsChannel, repo.logger)
+}
@@ -1,12 +1,15 @@
 package dal
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"frappuccino/models"
-	"time"
+	"strings"
+
+	"github.com/lib/pq"
 )
 
 type OrderRepository struct {
@@ -14,14 +17,14 @@ type OrderRepository struct {
 }
 
 type OrderInterface interface {
-	Create(order *models.Order) (int, error)
-	GetByID(orderID int) (models.Order, error)
-	Update(order models.Order, id int) error
-	Delete(orderID int) error
-	List() ([]models.Order, error)
-	GetOrderedItemsCount(startDate, endDate *string) (map[string]int, error)
-	CreateOrder(tx *sql.Tx, order models.Order, total float64) (int, error)
-	BeginTransaction() (*sql.Tx, error)
+	GetByID(ctx context.Context, orderID int) (models.Order, error)
+	Update(ctx context.Context, order models.Order, id int) error
+	Close(ctx context.Context, orderID int, override bool) error
+	Delete(ctx context.Context, orderID int) error
+	List(ctx context.Context, filter models.OrderFilter) ([]models.Order, error)
+	GetOrderedItemsCount(ctx context.Context, startDate, endDate *string) (map[string]int, error)
+	CreateOrder(ctx context.Context, tx *sql.Tx, order models.Order) (int, error)
+	BeginTransaction(ctx context.Context) (*sql.Tx, error)
 }
 
 func NewOrderRepository(db *sql.DB) (*OrderRepository, error) {
@@ -32,90 +35,20 @@ func NewOrderRepository(db *sql.DB) (*OrderRepository, error) {
 	return &OrderRepository{db: db}, nil
 }
 
-func (repo *OrderRepository) Create(order *models.Order) (int, error) {
-	tx, err := repo.db.Begin()
-	if err != nil {
-		return 0, fmt.Errorf("failed to begin transaction: %w", err)
-	}
-
-	specialInstructionsJSON, err := json.Marshal(order.SpecialInstructions)
-	if err != nil {
-		tx.Rollback()
-		return 0, fmt.Errorf("failed to marshal special instructions: %w", err)
-	}
-
-	existsQuery := `
-		SELECT order_id FROM orders 
-		WHERE customer_name = $1 AND total_amount = $2 
-		AND special_instructions = $3::jsonb AND status = $4`
-
-	var existingOrderID int
-	err = tx.QueryRow(existsQuery, order.CustomerName, order.TotalAmount, specialInstructionsJSON, order.Status).Scan(&existingOrderID)
-	if err == nil {
-		tx.Rollback()
-		return 0, fmt.Errorf("order already exists with ID %d", existingOrderID)
-	} else if err != sql.ErrNoRows {
-		tx.Rollback()
-		return 0, fmt.Errorf("failed to check existing order: %w", err)
-	}
-
-	orderQuery := `
-		INSERT INTO orders (customer_name, total_amount, special_instructions, status) 
-		VALUES ($1, $2, $3::jsonb, $4) 
-		RETURNING order_id, created_at, updated_at`
-
-	var orderID int
-	var createdAt, updatedAt time.Time
-
-	err = tx.QueryRow(orderQuery, order.CustomerName, order.TotalAmount, specialInstructionsJSON, order.Status).
-		Scan(&orderID, &createdAt, &updatedAt)
-	if err != nil {
-		tx.Rollback()
-		return 0, fmt.Errorf("failed to insert order: %w", err)
-	}
-
-	orderItemQuery := `
-		INSERT INTO order_items (order_id, menu_item_id, quantity, price_at_order) 
-		VALUES ($1, $2, $3, 
-			(SELECT price FROM menu_items WHERE menu_item_id = $2))`
-
-	for _, item := range order.Items {
-		_, err := tx.Exec(orderItemQuery, orderID, item.ProductID, item.Quantity)
-		if err != nil {
-			tx.Rollback()
-			return 0, fmt.Errorf("failed to insert order item %d: %w", item.ProductID, err)
-		}
-	}
-
-	statusHistoryQuery := `
-		INSERT INTO order_status_history (order_id, status) 
-		VALUES ($1, $2)`
-
-	_, err = tx.Exec(statusHistoryQuery, orderID, order.Status)
-	if err != nil {
-		tx.Rollback()
-		return 0, fmt.Errorf("failed to insert order status history: %w", err)
-	}
-
-	if err := tx.Commit(); err != nil {
-		return 0, fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
-	return orderID, nil
-}
-
-func (repo *OrderRepository) GetByID(orderID int) (models.Order, error) {
+func (repo *OrderRepository) GetByID(ctx context.Context, orderID int) (models.Order, error) {
 	var order models.Order
 	var specialInstructionsJSON []byte
 
-	query := `SELECT order_id, customer_name, total_amount, special_instructions, status, created_at, updated_at 
+	query := `SELECT order_id, customer_name, customer_id, subtotal, discount_amount, tax_amount, total_amount, order_type,
+	                 employee_id, special_instructions, status, priority, created_at, updated_at 
 	          FROM orders WHERE order_id = $1`
-	row := repo.db.QueryRow(query, orderID)
+	row := repo.db.QueryRowContext(ctx, query, orderID)
 
-	if err := row.Scan(&order.ID, &order.CustomerName, &order.TotalAmount, &specialInstructionsJSON,
-		&order.Status, &order.CreatedAt, &order.UpdatedAt); err != nil {
+	if err := row.Scan(&order.ID, &order.CustomerName, &order.CustomerID, &order.Subtotal, &order.DiscountAmount,
+		&order.TaxAmount, &order.TotalAmount, &order.OrderType, &order.EmployeeID, &specialInstructionsJSON,
+		&order.Status, &order.Priority, &order.CreatedAt, &order.UpdatedAt); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return models.Order{}, fmt.Errorf("order with ID %d not found", orderID)
+			return models.Order{}, models.NotFound("order with ID %d not found", orderID)
 		}
 		return models.Order{}, fmt.Errorf("failed to scan order: %w", err)
 	}
@@ -126,8 +59,11 @@ func (repo *OrderRepository) GetByID(orderID int) (models.Order, error) {
 		}
 	}
 
-	orderItemsQuery := `SELECT menu_item_id, quantity FROM order_items WHERE order_id = $1`
-	rows, err := repo.db.Query(orderItemsQuery, orderID)
+	orderItemsQuery := `
+		SELECT oi.menu_item_id, mi.name, oi.quantity, oi.price_at_order, oi.customization_options
+		FROM order_items oi JOIN menu_items mi ON mi.menu_item_id = oi.menu_item_id
+		WHERE oi.order_id = $1 ORDER BY oi.order_item_id`
+	rows, err := repo.db.QueryContext(ctx, orderItemsQuery, orderID)
 	if err != nil {
 		return models.Order{}, fmt.Errorf("failed to fetch order items: %w", err)
 	}
@@ -135,9 +71,15 @@ func (repo *OrderRepository) GetByID(orderID int) (models.Order, error) {
 
 	for rows.Next() {
 		var item models.OrderItem
-		if err := rows.Scan(&item.ProductID, &item.Quantity); err != nil {
+		var modifiers []byte
+		if err := rows.Scan(&item.ProductID, &item.Name, &item.Quantity, &item.PriceAtOrder, &modifiers); err != nil {
 			return models.Order{}, fmt.Errorf("failed to scan order item: %w", err)
 		}
+		if len(modifiers) > 0 {
+			if err := json.Unmarshal(modifiers, &item.Modifiers); err != nil {
+				return models.Order{}, fmt.Errorf("failed to unmarshal modifiers: %w", err)
+			}
+		}
 		order.Items = append(order.Items, item)
 	}
 
@@ -145,19 +87,81 @@ func (repo *OrderRepository) GetByID(orderID int) (models.Order, error) {
 		return models.Order{}, fmt.Errorf("error iterating over order items: %w", err)
 	}
 
+	promotionsQuery := `
+		SELECT op.promotion_id, p.name, op.discount_amount
+		FROM order_promotions op JOIN promotions p ON p.promotion_id = op.promotion_id
+		WHERE op.order_id = $1 ORDER BY op.promotion_id`
+	promotionRows, err := repo.db.QueryContext(ctx, promotionsQuery, orderID)
+	if err != nil {
+		return models.Order{}, fmt.Errorf("failed to fetch order promotions: %w", err)
+	}
+	defer promotionRows.Close()
+
+	for promotionRows.Next() {
+		var promotion models.OrderPromotion
+		if err := promotionRows.Scan(&promotion.PromotionID, &promotion.Name, &promotion.DiscountAmount); err != nil {
+			return models.Order{}, fmt.Errorf("failed to scan order promotion: %w", err)
+		}
+		order.Promotions = append(order.Promotions, promotion)
+	}
+	if err := promotionRows.Err(); err != nil {
+		return models.Order{}, fmt.Errorf("error iterating over order promotions: %w", err)
+	}
+
+	taxesQuery := `
+		SELECT COALESCE(t.menu_item_id, 0), t.tax_rate_id, tr.name, t.rate, t.taxable_amount, t.tax_amount
+		FROM order_item_taxes t JOIN tax_rates tr ON tr.tax_rate_id = t.tax_rate_id
+		WHERE t.order_id = $1 ORDER BY t.menu_item_id, t.tax_rate_id`
+	taxRows, err := repo.db.QueryContext(ctx, taxesQuery, orderID)
+	if err != nil {
+		return models.Order{}, fmt.Errorf("failed to fetch order taxes: %w", err)
+	}
+	defer taxRows.Close()
+
+	for taxRows.Next() {
+		var tax models.OrderTax
+		if err := taxRows.Scan(&tax.ProductID, &tax.TaxRateID, &tax.Name, &tax.Rate, &tax.TaxableAmount, &tax.TaxAmount); err != nil {
+			return models.Order{}, fmt.Errorf("failed to scan order tax: %w", err)
+		}
+		order.Taxes = append(order.Taxes, tax)
+	}
+	if err := taxRows.Err(); err != nil {
+		return models.Order{}, fmt.Errorf("error iterating over order taxes: %w", err)
+	}
+
 	return order, nil
 }
 
-func (repo *OrderRepository) Update(order models.Order, id int) error {
-	tx, err := repo.db.Begin()
+func (repo *OrderRepository) Update(ctx context.Context, order models.Order, id int) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 
+	var oldStatus string
+	err = tx.QueryRowContext(ctx, `SELECT status FROM orders WHERE order_id = $1 FOR UPDATE`, id).Scan(&oldStatus)
+	if err != nil {
+		tx.Rollback()
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.NotFound("order with ID %d not found", id)
+		}
+		return fmt.Errorf("failed to get order status: %w", err)
+	}
+	if err := checkUpdate(oldStatus, order.Status); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	before, err := snapshot(ctx, tx, orderSnapshot, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	for _, item := range order.Items {
 		if item.Quantity == 0 {
 			deleteQuery := `DELETE FROM order_items WHERE order_id = $1 AND menu_item_id = $2`
-			_, err := tx.Exec(deleteQuery, id, item.ProductID)
+			_, err := tx.ExecContext(ctx, deleteQuery, id, item.ProductID)
 			if err != nil {
 				tx.Rollback()
 				return fmt.Errorf("failed to delete order item %d: %w", item.ProductID, err)
@@ -165,9 +169,10 @@ func (repo *OrderRepository) Update(order models.Order, id int) error {
 		} else {
 			updateQuery := `
 				UPDATE order_items 
-				SET quantity = $1, price_at_order = (SELECT price FROM menu_items WHERE menu_item_id = $2)
+				SET quantity = $1, price_at_order = (SELECT price FROM menu_items WHERE menu_item_id = $2),
+				    customization_options = $4::jsonb
 				WHERE order_id = $3 AND menu_item_id = $2`
-			result, err := tx.Exec(updateQuery, item.Quantity, item.ProductID, id)
+			result, err := tx.ExecContext(ctx, updateQuery, item.Quantity, item.ProductID, id, modifiersJSON(item.Modifiers))
 			if err != nil {
 				tx.Rollback()
 				return fmt.Errorf("failed to update order item %d: %w", item.ProductID, err)
@@ -176,9 +181,9 @@ func (repo *OrderRepository) Update(order models.Order, id int) error {
 			rowsAffected, _ := result.RowsAffected()
 			if rowsAffected == 0 {
 				insertQuery := `
-					INSERT INTO order_items (order_id, menu_item_id, quantity, price_at_order)
-					VALUES ($1, $2, $3, (SELECT price FROM menu_items WHERE menu_item_id = $2))`
-				_, err := tx.Exec(insertQuery, id, item.ProductID, item.Quantity)
+					INSERT INTO order_items (order_id, menu_item_id, quantity, price_at_order, customization_options)
+					VALUES ($1, $2, $3, (SELECT price FROM menu_items WHERE menu_item_id = $2), $4::jsonb)`
+				_, err := tx.ExecContext(ctx, insertQuery, id, item.ProductID, item.Quantity, modifiersJSON(item.Modifiers))
 				if err != nil {
 					tx.Rollback()
 					return fmt.Errorf("failed to insert order item %d: %w", item.ProductID, err)
@@ -195,23 +200,42 @@ func (repo *OrderRepository) Update(order models.Order, id int) error {
 
 	orderQuery := `
 		UPDATE orders
-		SET customer_name = $1, total_amount = $2, special_instructions = $3, status = $4, updated_at = CURRENT_TIMESTAMP
-		WHERE order_id = $5`
-	_, err = tx.Exec(orderQuery, order.CustomerName, order.TotalAmount, specialInstructionsJSON, order.Status, id)
+		SET customer_name = $1, subtotal = $2, discount_amount = $3, tax_amount = $4, total_amount = $5,
+		    special_instructions = $6, status = $7, priority = $8, updated_at = CURRENT_TIMESTAMP
+		WHERE order_id = $9`
+	_, err = tx.ExecContext(ctx, orderQuery, order.CustomerName, order.Subtotal, order.DiscountAmount, order.TaxAmount,
+		order.TotalAmount, specialInstructionsJSON, order.Status, order.Priority, id)
 	if err != nil {
 		tx.Rollback()
 		return fmt.Errorf("failed to update order: %w", err)
 	}
 
-	if order.Status != "pending" && order.Status != "completed" && order.Status != "canceled" {
-		statusHistoryQuery := `INSERT INTO order_status_history (order_id, status) VALUES ($1, $2)`
-		_, err := tx.Exec(statusHistoryQuery, id, order.Status)
-		if err != nil {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM order_item_taxes WHERE order_id = $1`, id); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to delete order taxes: %w", err)
+	}
+	if err := insertOrderTaxes(ctx, tx, id, order.Taxes); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if order.Status != oldStatus {
+		if err := insertStatusHistory(ctx, tx, id, order.Status); err != nil {
 			tx.Rollback()
-			return fmt.Errorf("failed to insert order status history: %w", err)
+			return err
 		}
 	}
 
+	after, err := snapshot(ctx, tx, orderSnapshot, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := recordAudit(ctx, tx, models.AuditUpdate, models.AuditOrder, id, before, after); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
@@ -219,25 +243,128 @@ func (repo *OrderRepository) Update(order models.Order, id int) error {
 	return nil
 }
 
-func (repo *OrderRepository) Delete(orderID int) error {
-	tx, err := repo.db.Begin()
+// checkUpdate refuses changes to an order that is over, and any status
+// change other than accepting a pending order.
+func checkUpdate(from, to string) error {
+	switch from {
+	case "completed", "cancelled", "rejected":
+		return models.Conflict("order is %s and can't be changed", from)
+	}
+	if to != from && !(from == "pending" && to == "accepted") {
+		return models.Conflict("order can't go from %s to %s with an update", from, to)
+	}
+	return nil
+}
+
+// Close completes an order. The order is locked while its status and
+// balance are checked, and only its status changes, so the amounts stay as
+// they were charged.
+func (repo *OrderRepository) Close(ctx context.Context, orderID int, override bool) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
+
+	due, status, err := lockOrderBalance(ctx, tx, orderID)
+	if err != nil {
+		return err
+	}
+	if err := checkClose(status, due, override); err != nil {
+		return err
+	}
+
+	before, err := snapshot(ctx, tx, orderSnapshot, orderID)
+	if err != nil {
+		return err
+	}
+	query := `UPDATE orders SET status = 'completed', updated_at = CURRENT_TIMESTAMP WHERE order_id = $1`
+	if _, err := tx.ExecContext(ctx, query, orderID); err != nil {
+		return fmt.Errorf("failed to close order: %w", err)
+	}
+	if err := insertStatusHistory(ctx, tx, orderID, "completed"); err != nil {
+		return err
+	}
+	if err := earnLoyaltyPoints(ctx, tx, orderID); err != nil {
+		return err
+	}
+	if err := enqueueOrderEvent(ctx, tx, "order.completed", orderID); err != nil {
+		return err
+	}
+	after, err := snapshot(ctx, tx, orderSnapshot, orderID)
+	if err != nil {
+		return err
+	}
+	if err := recordAudit(ctx, tx, models.AuditUpdate, models.AuditOrder, orderID, before, after); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// checkClose refuses to close an order that isn't being served, or that
+// has a balance due unless override is set.
+func checkClose(status string, due float64, override bool) error {
+	switch status {
+	case "accepted", "processing", "ready":
+	default:
+		return models.Conflict("order is %s and can't be closed", status)
+	}
+	if due > 0.005 && !override {
+		return models.Conflict("order is not paid: balance due %.2f. Take the payment or close with override=true.", due)
+	}
+	return nil
+}
+
+func (repo *OrderRepository) Delete(ctx context.Context, orderID int) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	before, err := snapshot(ctx, tx, orderSnapshot, orderID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
 
 	statusHistoryQuery := `
 		INSERT INTO order_status_history (order_id, status) 
 		VALUES ($1, 'cancelled')`
-	_, err = tx.Exec(statusHistoryQuery, orderID)
+	_, err = tx.ExecContext(ctx, statusHistoryQuery, orderID)
 	if err != nil {
 		tx.Rollback()
 		return fmt.Errorf("failed to insert cancelled status history: %w", err)
 	}
 
+	if err := enqueueOrderEvent(ctx, tx, "order.cancelled", orderID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := reverseLoyaltyPoints(ctx, tx, orderID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var hasPayments bool
+	err = tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM payments WHERE order_id = $1)`, orderID).Scan(&hasPayments)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to check order payments: %w", err)
+	}
+	if hasPayments {
+		tx.Rollback()
+		return models.Conflict("order has payments and can't be deleted, refund and cancel it instead")
+	}
+
 	deleteOrderItemsQuery := `
 		DELETE FROM order_items 
 		WHERE order_id = $1`
-	_, err = tx.Exec(deleteOrderItemsQuery, orderID)
+	_, err = tx.ExecContext(ctx, deleteOrderItemsQuery, orderID)
 	if err != nil {
 		tx.Rollback()
 		return fmt.Errorf("failed to delete order items: %w", err)
@@ -246,7 +373,7 @@ func (repo *OrderRepository) Delete(orderID int) error {
 	deleteOrderQuery := `
 		DELETE FROM orders 
 		WHERE order_id = $1`
-	result, err := tx.Exec(deleteOrderQuery, orderID)
+	result, err := tx.ExecContext(ctx, deleteOrderQuery, orderID)
 	if err != nil {
 		tx.Rollback()
 		return fmt.Errorf("failed to delete order: %w", err)
@@ -259,7 +386,12 @@ func (repo *OrderRepository) Delete(orderID int) error {
 	}
 	if numRows == 0 {
 		tx.Rollback()
-		return errors.New("order not found")
+		return models.NotFound("order not found")
+	}
+
+	if err := recordAudit(ctx, tx, models.AuditDelete, models.AuditOrder, orderID, before, nil); err != nil {
+		tx.Rollback()
+		return err
 	}
 
 	if err := tx.Commit(); err != nil {
@@ -269,45 +401,84 @@ func (repo *OrderRepository) Delete(orderID int) error {
 	return nil
 }
 
-func (repo *OrderRepository) List() ([]models.Order, error) {
-	query := `SELECT order_id, customer_name, total_amount, special_instructions, status, created_at, updated_at FROM orders`
-	rows, err := repo.db.Query(query)
+// orderSortColumns maps public sort keys to columns and the type of their
+// keyset value.
+var orderSortColumns = map[string][2]string{
+	"created_at":   {"o.created_at", "timestamp"},
+	"total_amount": {"o.total_amount", "numeric"},
+}
+
+// List returns one page of orders using keyset pagination on the sort column
+// and order_id. Items of the whole page are aggregated in the same query.
+func (repo *OrderRepository) List(ctx context.Context, filter models.OrderFilter) ([]models.Order, error) {
+	sort, ok := orderSortColumns[filter.SortBy]
+	if !ok {
+		sort = orderSortColumns["created_at"]
+	}
+	direction, operator := "ASC", ">"
+	if filter.Descending {
+		direction, operator = "DESC", "<"
+	}
+
+	query := fmt.Sprintf(`
+	WITH page AS (
+		SELECT o.order_id, o.customer_name, o.customer_id, o.subtotal, o.discount_amount, o.tax_amount, o.total_amount,
+		       o.order_type, o.employee_id, o.special_instructions, o.status, o.priority, o.created_at, o.updated_at
+		FROM orders o
+		WHERE (cardinality($1::text[]) = 0 OR o.status::text = ANY($1::text[]))
+		  AND ($2 = '' OR o.customer_name ILIKE '%%' || $2 || '%%' ESCAPE '\')
+		  AND ($3::date IS NULL OR o.created_at >= $3::date)
+		  AND ($4::date IS NULL OR o.created_at < $4::date + 1)
+		  AND ($5::int IS NULL OR EXISTS (
+		      SELECT 1 FROM order_items f WHERE f.order_id = o.order_id AND f.menu_item_id = $5::int))
+		  AND ($6::numeric IS NULL OR o.total_amount >= $6::numeric)
+		  AND ($7::numeric IS NULL OR o.total_amount <= $7::numeric)
+		  AND ($8::text IS NULL OR (%[1]s, o.order_id) %[3]s ($8::%[2]s, $9::int))
+		  AND ($11::int IS NULL OR o.customer_id = $11::int)
+		ORDER BY %[1]s %[4]s, o.order_id %[4]s
+		LIMIT $10
+	)
+	SELECT p.order_id, p.customer_name, p.customer_id, p.subtotal, p.discount_amount, p.tax_amount, p.total_amount,
+	       p.order_type, p.employee_id, p.special_instructions, p.status, p.priority, p.created_at, p.updated_at,
+	       COALESCE(i.items, '[]'::jsonb)
+	FROM page p
+	LEFT JOIN (
+		SELECT oi.order_id,
+		       jsonb_agg(jsonb_build_object('product_id', oi.menu_item_id, 'quantity', oi.quantity,
+		           'modifiers', COALESCE(oi.customization_options, '[]'::jsonb)) ORDER BY oi.order_item_id) AS items
+		FROM order_items oi
+		WHERE oi.order_id IN (SELECT order_id FROM page)
+		GROUP BY oi.order_id
+	) i ON i.order_id = p.order_id
+	ORDER BY %[5]s %[4]s, p.order_id %[4]s`,
+		sort[0], sort[1], operator, direction, strings.Replace(sort[0], "o.", "p.", 1))
+
+	rows, err := repo.db.QueryContext(ctx, query, pq.Array(filter.Statuses), likeEscaper.Replace(filter.Customer),
+		filter.StartDate, filter.EndDate, filter.MenuItemID, filter.MinTotal, filter.MaxTotal,
+		filter.AfterValue, filter.AfterID, filter.Limit, filter.CustomerID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query orders: %w", err)
 	}
 	defer rows.Close()
 
-	var orders []models.Order
+	orders := []models.Order{}
 	for rows.Next() {
 		var order models.Order
-		var specialInstructionsJSON []byte
+		var specialInstructionsJSON, itemsJSON []byte
 
-		if err := rows.Scan(&order.ID, &order.CustomerName, &order.TotalAmount, &specialInstructionsJSON,
-			&order.Status, &order.CreatedAt, &order.UpdatedAt); err != nil {
+		if err := rows.Scan(&order.ID, &order.CustomerName, &order.CustomerID, &order.Subtotal, &order.DiscountAmount,
+			&order.TaxAmount, &order.TotalAmount, &order.OrderType, &order.EmployeeID, &specialInstructionsJSON, &order.Status, &order.Priority, &order.CreatedAt, &order.UpdatedAt,
+			&itemsJSON); err != nil {
 			return nil, fmt.Errorf("failed to scan order: %w", err)
 		}
 
-		if err := json.Unmarshal(specialInstructionsJSON, &order.SpecialInstructions); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal special instructions: %w", err)
-		}
-
-		orderItemsQuery := `SELECT menu_item_id, quantity FROM order_items WHERE order_id = $1`
-		orderItemsRows, err := repo.db.Query(orderItemsQuery, order.ID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to query order items: %w", err)
-		}
-		defer orderItemsRows.Close()
-
-		for orderItemsRows.Next() {
-			var item models.OrderItem
-			if err := orderItemsRows.Scan(&item.ProductID, &item.Quantity); err != nil {
-				return nil, fmt.Errorf("failed to scan order item: %w", err)
+		if len(specialInstructionsJSON) > 0 {
+			if err := json.Unmarshal(specialInstructionsJSON, &order.SpecialInstructions); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal special instructions: %w", err)
 			}
-			order.Items = append(order.Items, item)
 		}
-
-		if err := orderItemsRows.Err(); err != nil {
-			return nil, fmt.Errorf("error iterating over order items: %w", err)
+		if err := json.Unmarshal(itemsJSON, &order.Items); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal order items: %w", err)
 		}
 
 		orders = append(orders, order)
@@ -321,7 +492,7 @@ func (repo *OrderRepository) List() ([]models.Order, error) {
 }
 
 // new endpoint
-func (repo *OrderRepository) GetOrderedItemsCount(startDate, endDate *string) (map[string]int, error) {
+func (repo *OrderRepository) GetOrderedItemsCount(ctx context.Context, startDate, endDate *string) (map[string]int, error) {
 	query := `
 		SELECT mi.name, COALESCE(SUM(oi.quantity), 0) 
 		FROM order_items oi
@@ -344,7 +515,7 @@ func (repo *OrderRepository) GetOrderedItemsCount(startDate, endDate *string) (m
 		end = nil
 	}
 
-	rows, err := repo.db.Query(query, start, end)
+	rows, err := repo.db.QueryContext(ctx, query, start, end)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query ordered items count: %w", err)
 	}
@@ -365,34 +536,103 @@ func (repo *OrderRepository) GetOrderedItemsCount(startDate, endDate *string) (m
 	return result, nil
 }
 
-func (r *OrderRepository) BeginTransaction() (*sql.Tx, error) {
-	return r.db.Begin()
+func (r *OrderRepository) BeginTransaction(ctx context.Context) (*sql.Tx, error) {
+	return r.db.BeginTx(ctx, nil)
 }
 
-func (r *OrderRepository) CreateOrder(tx *sql.Tx, order models.Order, total float64) (int, error) {
+// CreateOrder stores a priced order with its items, promotions, taxes and
+// redeemed loyalty points within tx. An order without a status is pending.
+func (r *OrderRepository) CreateOrder(ctx context.Context, tx *sql.Tx, order models.Order) (int, error) {
+	if len(order.Items) == 0 {
+		return 0, models.Invalid("order must contain at least one item")
+	}
+
+	specialInstructionsJSON, err := json.Marshal(order.SpecialInstructions)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal special instructions: %w", err)
+	}
+
+	existsQuery := `
+		SELECT order_id FROM orders 
+		WHERE customer_name = $1 AND total_amount = $2 
+		AND special_instructions = $3::jsonb AND status = COALESCE(NULLIF($4, '')::order_status, 'pending')`
+
+	var existingOrderID int
+	err = tx.QueryRowContext(ctx, existsQuery, order.CustomerName, order.TotalAmount, specialInstructionsJSON, order.Status).Scan(&existingOrderID)
+	if err == nil {
+		return 0, models.Conflict("order already exists with ID %d", existingOrderID)
+	} else if err != sql.ErrNoRows {
+		return 0, fmt.Errorf("failed to check existing order: %w", err)
+	}
+
+	orderQuery := `
+		INSERT INTO orders (customer_name, customer_id, subtotal, discount_amount, tax_amount, total_amount, order_type,
+		                    special_instructions, status, priority, employee_id) 
+		VALUES ($1, $2, $3, $4, $5, $6, COALESCE(NULLIF($7, '')::order_type, 'dine_in'), $8::jsonb,
+		        COALESCE(NULLIF($9, '')::order_status, 'pending'), $10, $11) 
+		RETURNING order_id`
+
 	var orderID int
-	query := `
-		INSERT INTO orders (customer_name, total_amount, status) 
-		VALUES ($1, $2, 'accepted') RETURNING order_id;
-	`
-	err := tx.QueryRow(query, order.CustomerName, total).Scan(&orderID)
+	err = tx.QueryRowContext(ctx, orderQuery, order.CustomerName, order.CustomerID, order.Subtotal, order.DiscountAmount, order.TaxAmount,
+		order.TotalAmount, order.OrderType, specialInstructionsJSON, order.Status, order.Priority, order.EmployeeID).Scan(&orderID)
 	if err != nil {
-		return 0, fmt.Errorf("failed to create order: %w", err)
+		return 0, employeeWriteError("failed to insert order", err, order.EmployeeID)
 	}
 
-	if len(order.Items) == 0 {
-		return 0, fmt.Errorf("order must contain at least one item")
+	if err := recordOrderPromotions(ctx, tx, orderID, order.Promotions); err != nil {
+		return 0, err
+	}
+
+	if err := insertOrderTaxes(ctx, tx, orderID, order.Taxes); err != nil {
+		return 0, err
 	}
 
+	if order.CustomerID != nil && order.RedeemPoints > 0 {
+		if err := redeemLoyaltyPoints(ctx, tx, *order.CustomerID, orderID, order.RedeemPoints); err != nil {
+			return 0, err
+		}
+	}
+
+	orderItemQuery := `
+		INSERT INTO order_items (order_id, menu_item_id, quantity, price_at_order, customization_options) 
+		VALUES ($1, $2, $3, 
+			(SELECT price FROM menu_items WHERE menu_item_id = $2), $4::jsonb)`
+
 	for _, item := range order.Items {
-		_, err := tx.Exec(`
-			INSERT INTO order_items (order_id, menu_item_id, quantity, price_at_order) 
-			VALUES ($1, $2, $3, (SELECT price FROM menu_items WHERE menu_item_id = $2));
-		`, orderID, item.ProductID, item.Quantity)
-		if err != nil {
-			return 0, fmt.Errorf("failed to insert order item: %w", err)
+		if _, err := tx.ExecContext(ctx, orderItemQuery, orderID, item.ProductID, item.Quantity, modifiersJSON(item.Modifiers)); err != nil {
+			return 0, fmt.Errorf("failed to insert order item %d: %w", item.ProductID, err)
 		}
 	}
 
+	statusHistoryQuery := `
+		INSERT INTO order_status_history (order_id, status) 
+		SELECT order_id, status FROM orders WHERE order_id = $1`
+
+	if _, err := tx.ExecContext(ctx, statusHistoryQuery, orderID); err != nil {
+		return 0, fmt.Errorf("failed to insert order status history: %w", err)
+	}
+
+	if err := enqueueOrderEvent(ctx, tx, "order.created", orderID); err != nil {
+		return 0, err
+	}
+
+	after, err := snapshot(ctx, tx, orderSnapshot, orderID)
+	if err != nil {
+		return 0, err
+	}
+	if err := recordAudit(ctx, tx, models.AuditCreate, models.AuditOrder, orderID, nil, after); err != nil {
+		return 0, err
+	}
+
 	return orderID, nil
 }
+
+// modifiersJSON returns the value stored in order_items.customization_options,
+// NULL when the item has no modifiers.
+func modifiersJSON(modifiers []string) interface{} {
+	if len(modifiers) == 0 {
+		return nil
+	}
+	data, _ := json.Marshal(modifiers)
+	return string(data)
+}
@@ -0,0 +1,66 @@
+package dal
+
+import (
+	"errors"
+	"testing"
+
+	"frappuccino/models"
+)
+
+func TestCheckClose(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   string
+		due      float64
+		override bool
+		wantErr  bool
+	}{
+		{name: "paid", status: "ready"},
+		{name: "accepted", status: "accepted"},
+		{name: "processing", status: "processing"},
+		{name: "overpaid", status: "ready", due: -2},
+		{name: "rounding leftover", status: "ready", due: 0.004},
+		{name: "balance due", status: "ready", due: 4.5, wantErr: true},
+		{name: "balance due with override", status: "ready", due: 4.5, override: true},
+		{name: "pending", status: "pending", wantErr: true},
+		{name: "already completed", status: "completed", wantErr: true},
+		{name: "cancelled", status: "cancelled", override: true, wantErr: true},
+		{name: "rejected", status: "rejected", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkClose(tt.status, tt.due, tt.override)
+			if tt.wantErr != errors.Is(err, models.ErrConflict) {
+				t.Errorf("checkClose(%s, %v, %v) = %v, want conflict %v", tt.status, tt.due, tt.override, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckUpdate(t *testing.T) {
+	tests := []struct {
+		from, to string
+		wantErr  bool
+	}{
+		{"pending", "pending", false},
+		{"pending", "accepted", false},
+		{"accepted", "accepted", false},
+		{"processing", "processing", false},
+		{"ready", "ready", false},
+		{"accepted", "pending", true},
+		{"accepted", "processing", true},
+		{"processing", "ready", true},
+		{"ready", "cancelled", true},
+		{"completed", "completed", true},
+		{"cancelled", "cancelled", true},
+		{"rejected", "accepted", true},
+	}
+
+	for _, tt := range tests {
+		err := checkUpdate(tt.from, tt.to)
+		if tt.wantErr != errors.Is(err, models.ErrConflict) {
+			t.Errorf("checkUpdate(%s, %s) = %v, want conflict %v", tt.from, tt.to, err, tt.wantErr)
+		}
+	}
+}
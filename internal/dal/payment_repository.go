@@ -0,0 +1,202 @@
+package dal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"frappuccino/models"
+	"math"
+)
+
+type PaymentRepository struct {
+	db *sql.DB
+}
+
+type PaymentInterface interface {
+	CreatePayment(ctx context.Context, payment *models.Payment) error
+	CreateRefund(ctx context.Context, refund *models.Refund) error
+	GetBalance(ctx context.Context, orderID int) (models.OrderBalance, error)
+}
+
+func NewPaymentRepository(db *sql.DB) (*PaymentRepository, error) {
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("unable to connect to database: %v", err)
+	}
+
+	return &PaymentRepository{db: db}, nil
+}
+
+// CreatePayment records a payment. The order is locked while the balance is
+// checked, so concurrent payments can't pay it more than once.
+func (repo *PaymentRepository) CreatePayment(ctx context.Context, payment *models.Payment) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	due, status, err := lockOrderBalance(ctx, tx, payment.OrderID)
+	if err != nil {
+		return err
+	}
+	if status == "cancelled" || status == "rejected" {
+		return models.Conflict("order is %s and can't take payments", status)
+	}
+	if payment.Amount > due+0.005 {
+		return models.Conflict("payment exceeds balance due of %.2f", math.Max(due, 0))
+	}
+
+	sessionID, err := currentDrawerSession(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO payments (order_id, method, amount, tip, tendered, change_given, reference, drawer_session_id)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	RETURNING payment_id, created_at`
+	err = tx.QueryRowContext(ctx, query, payment.OrderID, payment.Method, payment.Amount, payment.Tip, payment.Tendered,
+		payment.Change, payment.Reference, sessionID).Scan(&payment.ID, &payment.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert payment: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// CreateRefund gives back part or all of a payment. Tips are not refunded.
+func (repo *PaymentRepository) CreateRefund(ctx context.Context, refund *models.Refund) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, _, err := lockOrderBalance(ctx, tx, refund.OrderID); err != nil {
+		return err
+	}
+
+	var refundable float64
+	err = tx.QueryRowContext(ctx, `
+		SELECT p.amount - COALESCE((SELECT SUM(r.amount) FROM refunds r WHERE r.payment_id = p.payment_id), 0)
+		FROM payments p WHERE p.payment_id = $1 AND p.order_id = $2`, refund.PaymentID, refund.OrderID).Scan(&refundable)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.NotFound("payment with ID %d not found", refund.PaymentID)
+		}
+		return fmt.Errorf("failed to get payment: %w", err)
+	}
+	if refund.Amount > refundable+0.005 {
+		return models.Conflict("refund exceeds refundable amount of %.2f", refundable)
+	}
+
+	sessionID, err := currentDrawerSession(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO refunds (order_id, payment_id, amount, reason, drawer_session_id)
+	VALUES ($1, $2, $3, $4, $5)
+	RETURNING refund_id, created_at`
+	err = tx.QueryRowContext(ctx, query, refund.OrderID, refund.PaymentID, refund.Amount, refund.Reason, sessionID).
+		Scan(&refund.ID, &refund.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert refund: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (repo *PaymentRepository) GetBalance(ctx context.Context, orderID int) (models.OrderBalance, error) {
+	balance := models.OrderBalance{OrderID: orderID}
+	err := repo.db.QueryRowContext(ctx, `SELECT total_amount FROM orders WHERE order_id = $1`, orderID).Scan(&balance.TotalAmount)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.OrderBalance{}, models.NotFound("order with ID %d not found", orderID)
+		}
+		return models.OrderBalance{}, fmt.Errorf("failed to get order: %w", err)
+	}
+
+	paymentsQuery := `
+	SELECT p.payment_id, p.order_id, p.method, p.amount, p.tip, p.tendered, p.change_given, p.reference,
+	       COALESCE((SELECT SUM(r.amount) FROM refunds r WHERE r.payment_id = p.payment_id), 0), p.created_at
+	FROM payments p
+	WHERE p.order_id = $1
+	ORDER BY p.payment_id`
+	rows, err := repo.db.QueryContext(ctx, paymentsQuery, orderID)
+	if err != nil {
+		return models.OrderBalance{}, fmt.Errorf("failed to query payments: %w", err)
+	}
+	defer rows.Close()
+
+	balance.Payments = []models.Payment{}
+	for rows.Next() {
+		var p models.Payment
+		if err := rows.Scan(&p.ID, &p.OrderID, &p.Method, &p.Amount, &p.Tip, &p.Tendered, &p.Change, &p.Reference,
+			&p.Refunded, &p.CreatedAt); err != nil {
+			return models.OrderBalance{}, fmt.Errorf("failed to scan payment: %w", err)
+		}
+		balance.Paid += p.Amount
+		balance.Tips += p.Tip
+		balance.Payments = append(balance.Payments, p)
+	}
+	if err := rows.Err(); err != nil {
+		return models.OrderBalance{}, fmt.Errorf("error iterating over payments: %w", err)
+	}
+
+	refundRows, err := repo.db.QueryContext(ctx, `
+		SELECT refund_id, order_id, payment_id, amount, reason, created_at
+		FROM refunds WHERE order_id = $1 ORDER BY refund_id`, orderID)
+	if err != nil {
+		return models.OrderBalance{}, fmt.Errorf("failed to query refunds: %w", err)
+	}
+	defer refundRows.Close()
+
+	balance.Refunds = []models.Refund{}
+	for refundRows.Next() {
+		var r models.Refund
+		if err := refundRows.Scan(&r.ID, &r.OrderID, &r.PaymentID, &r.Amount, &r.Reason, &r.CreatedAt); err != nil {
+			return models.OrderBalance{}, fmt.Errorf("failed to scan refund: %w", err)
+		}
+		balance.Refunded += r.Amount
+		balance.Refunds = append(balance.Refunds, r)
+	}
+	if err := refundRows.Err(); err != nil {
+		return models.OrderBalance{}, fmt.Errorf("error iterating over refunds: %w", err)
+	}
+
+	balance.BalanceDue = balance.TotalAmount - balance.Paid + balance.Refunded
+	return balance, nil
+}
+
+// lockOrderBalance locks the order and returns what is left to pay and its
+// status.
+func lockOrderBalance(ctx context.Context, tx *sql.Tx, orderID int) (float64, string, error) {
+	var total float64
+	var status string
+	err := tx.QueryRowContext(ctx, `SELECT total_amount, status FROM orders WHERE order_id = $1 FOR UPDATE`, orderID).
+		Scan(&total, &status)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, "", models.NotFound("order with ID %d not found", orderID)
+		}
+		return 0, "", fmt.Errorf("failed to lock order: %w", err)
+	}
+
+	var paid float64
+	err = tx.QueryRowContext(ctx, `
+		SELECT COALESCE((SELECT SUM(amount) FROM payments WHERE order_id = $1), 0)
+		     - COALESCE((SELECT SUM(amount) FROM refunds WHERE order_id = $1), 0)`, orderID).Scan(&paid)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to get paid amount: %w", err)
+	}
+	return total - paid, status, nil
+}
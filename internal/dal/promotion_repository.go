@@ -0,0 +1,211 @@
+package dal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"frappuccino/models"
+
+	"github.com/lib/pq"
+)
+
+const promotionColumns = `
+	p.promotion_id, p.name, p.type, p.value, p.code, p.menu_item_id, p.category, p.buy_quantity, p.get_quantity,
+	p.min_subtotal, p.starts_at, p.ends_at, p.days_of_week, to_char(p.start_time, 'HH24:MI'), to_char(p.end_time, 'HH24:MI'),
+	p.usage_limit, p.usage_count, p.active, p.created_at`
+
+type PromotionRepository struct {
+	db *sql.DB
+}
+
+type PromotionInterface interface {
+	Create(ctx context.Context, promotion *models.Promotion) error
+	GetByID(ctx context.Context, id int) (models.Promotion, error)
+	List(ctx context.Context) ([]models.Promotion, error)
+	Update(ctx context.Context, promotion models.Promotion, id int) error
+	Deactivate(ctx context.Context, id int) error
+	ListApplicable(ctx context.Context, code string) ([]models.Promotion, error)
+}
+
+func NewPromotionRepository(db *sql.DB) (*PromotionRepository, error) {
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("unable to connect to database: %v", err)
+	}
+
+	return &PromotionRepository{db: db}, nil
+}
+
+func (repo *PromotionRepository) Create(ctx context.Context, promotion *models.Promotion) error {
+	query := `
+	INSERT INTO promotions (name, type, value, code, menu_item_id, category, buy_quantity, get_quantity, min_subtotal,
+	                        starts_at, ends_at, days_of_week, start_time, end_time, usage_limit, active)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13::time, $14::time, $15, COALESCE($16, TRUE))
+	RETURNING promotion_id`
+	err := repo.db.QueryRowContext(ctx, query, promotion.Name, promotion.Type, promotion.Value, promotion.Code, promotion.MenuItemID,
+		promotion.Category, promotion.BuyQuantity, promotion.GetQuantity, promotion.MinSubtotal, promotion.StartsAt,
+		promotion.EndsAt, daysOfWeek(promotion.DaysOfWeek), promotion.StartTime, promotion.EndTime, promotion.UsageLimit,
+		promotion.Active).Scan(&promotion.ID)
+	if err != nil {
+		return promotionWriteError("failed to create promotion", err)
+	}
+	return nil
+}
+
+func (repo *PromotionRepository) GetByID(ctx context.Context, id int) (models.Promotion, error) {
+	row := repo.db.QueryRowContext(ctx, `SELECT `+promotionColumns+` FROM promotions p WHERE p.promotion_id = $1`, id)
+	promotion, err := scanPromotion(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Promotion{}, models.NotFound("promotion with ID %d not found", id)
+		}
+		return models.Promotion{}, fmt.Errorf("failed to scan promotion: %w", err)
+	}
+	return promotion, nil
+}
+
+func (repo *PromotionRepository) List(ctx context.Context) ([]models.Promotion, error) {
+	return repo.query(ctx, `SELECT `+promotionColumns+` FROM promotions p ORDER BY p.promotion_id`)
+}
+
+// Update replaces the rule. Active is kept when it is not given.
+func (repo *PromotionRepository) Update(ctx context.Context, promotion models.Promotion, id int) error {
+	query := `
+	UPDATE promotions
+	SET name = $1, type = $2, value = $3, code = $4, menu_item_id = $5, category = $6, buy_quantity = $7,
+	    get_quantity = $8, min_subtotal = $9, starts_at = $10, ends_at = $11, days_of_week = $12,
+	    start_time = $13::time, end_time = $14::time, usage_limit = $15, active = COALESCE($16, active)
+	WHERE promotion_id = $17`
+	result, err := repo.db.ExecContext(ctx, query, promotion.Name, promotion.Type, promotion.Value, promotion.Code, promotion.MenuItemID,
+		promotion.Category, promotion.BuyQuantity, promotion.GetQuantity, promotion.MinSubtotal, promotion.StartsAt,
+		promotion.EndsAt, daysOfWeek(promotion.DaysOfWeek), promotion.StartTime, promotion.EndTime, promotion.UsageLimit,
+		promotion.Active, id)
+	if err != nil {
+		return promotionWriteError("failed to update promotion", err)
+	}
+	numRows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if numRows == 0 {
+		return models.NotFound("promotion with ID %d not found", id)
+	}
+	return nil
+}
+
+// Deactivate switches a promotion off. Promotions are never deleted, so
+// discounts already given stay in the reports.
+func (repo *PromotionRepository) Deactivate(ctx context.Context, id int) error {
+	result, err := repo.db.ExecContext(ctx, `UPDATE promotions SET active = FALSE WHERE promotion_id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to deactivate promotion: %w", err)
+	}
+	numRows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if numRows == 0 {
+		return models.NotFound("promotion with ID %d not found", id)
+	}
+	return nil
+}
+
+// ListApplicable returns the automatic promotions that are valid right now
+// and the promotion with the given code, automatic ones first. A time window
+// whose start is after its end wraps past midnight.
+func (repo *PromotionRepository) ListApplicable(ctx context.Context, code string) ([]models.Promotion, error) {
+	query := `
+	SELECT ` + promotionColumns + `
+	FROM promotions p
+	WHERE p.active
+	  AND (p.code IS NULL OR ($1 <> '' AND p.code = $1))
+	  AND (p.starts_at IS NULL OR p.starts_at <= LOCALTIMESTAMP)
+	  AND (p.ends_at IS NULL OR p.ends_at > LOCALTIMESTAMP)
+	  AND (p.days_of_week IS NULL OR EXTRACT(ISODOW FROM LOCALTIMESTAMP)::int = ANY(p.days_of_week))
+	  AND (p.start_time IS NULL OR p.end_time IS NULL
+	       OR (p.start_time <= p.end_time AND LOCALTIME >= p.start_time AND LOCALTIME < p.end_time)
+	       OR (p.start_time > p.end_time AND (LOCALTIME >= p.start_time OR LOCALTIME < p.end_time)))
+	  AND (p.usage_limit IS NULL OR p.usage_count < p.usage_limit)
+	ORDER BY p.code NULLS FIRST, p.promotion_id`
+	return repo.query(ctx, query, code)
+}
+
+func (repo *PromotionRepository) query(ctx context.Context, query string, args ...interface{}) ([]models.Promotion, error) {
+	rows, err := repo.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query promotions: %w", err)
+	}
+	defer rows.Close()
+
+	promotions := []models.Promotion{}
+	for rows.Next() {
+		promotion, err := scanPromotion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan promotion: %w", err)
+		}
+		promotions = append(promotions, promotion)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over promotions: %w", err)
+	}
+	return promotions, nil
+}
+
+func scanPromotion(row interface{ Scan(...interface{}) error }) (models.Promotion, error) {
+	var p models.Promotion
+	var active bool
+	err := row.Scan(&p.ID, &p.Name, &p.Type, &p.Value, &p.Code, &p.MenuItemID, &p.Category, &p.BuyQuantity,
+		&p.GetQuantity, &p.MinSubtotal, &p.StartsAt, &p.EndsAt, pq.Array(&p.DaysOfWeek), &p.StartTime, &p.EndTime,
+		&p.UsageLimit, &p.UsageCount, &active, &p.CreatedAt)
+	p.Active = &active
+	return p, err
+}
+
+// daysOfWeek stores an empty list as NULL, meaning every day.
+func daysOfWeek(days []int64) interface{} {
+	if len(days) == 0 {
+		return nil
+	}
+	return pq.Array(days)
+}
+
+func promotionWriteError(message string, err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case "23505":
+			return models.Conflict("promotion with this code already exists")
+		case "23503":
+			return models.Invalid("menu item of the promotion not found")
+		}
+	}
+	return fmt.Errorf("%s: %w", message, err)
+}
+
+// recordOrderPromotions stores the discounts of an order and counts the
+// usage. The limit is checked again under the row lock, so concurrent
+// orders can't exceed it.
+func recordOrderPromotions(ctx context.Context, tx *sql.Tx, orderID int, promotions []models.OrderPromotion) error {
+	for _, promotion := range promotions {
+		result, err := tx.ExecContext(ctx, `
+			UPDATE promotions SET usage_count = usage_count + 1
+			WHERE promotion_id = $1 AND (usage_limit IS NULL OR usage_count < usage_limit)`, promotion.PromotionID)
+		if err != nil {
+			return fmt.Errorf("failed to count promotion usage: %w", err)
+		}
+		numRows, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get affected rows: %w", err)
+		}
+		if numRows == 0 {
+			return models.Conflict("promotion usage limit reached: %s", promotion.Name)
+		}
+
+		_, err = tx.ExecContext(ctx, `INSERT INTO order_promotions (order_id, promotion_id, discount_amount) VALUES ($1, $2, $3)`,
+			orderID, promotion.PromotionID, promotion.DiscountAmount)
+		if err != nil {
+			return fmt.Errorf("failed to insert order promotion: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,242 @@
+package dal
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"frappuccino/models"
+)
+
+type QueueRepository struct {
+	db *sql.DB
+}
+
+type QueueInterface interface {
+	ListActive(ctx context.Context, station string) ([]models.QueueOrder, error)
+	Claim(ctx context.Context, orderID int, claim models.QueueClaim) error
+	CompleteItem(ctx context.Context, orderID, orderItemID int) error
+	Bump(ctx context.Context, orderID int) error
+}
+
+func NewQueueRepository(db *sql.DB) (*QueueRepository, error) {
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("unable to connect to database: %v", err)
+	}
+
+	return &QueueRepository{db: db}, nil
+}
+
+// ListActive returns orders that still have to be made, highest priority
+// first and then oldest first. Unclaimed orders are shown to every station.
+// Items are aggregated for each active order only, not the whole history.
+func (repo *QueueRepository) ListActive(ctx context.Context, station string) ([]models.QueueOrder, error) {
+	query := `
+	SELECT o.order_id, o.customer_name, o.status, o.priority, o.special_instructions,
+	       o.station, o.handled_by, o.claimed_at, o.created_at,
+	       EXTRACT(EPOCH FROM (CURRENT_TIMESTAMP - o.created_at))::int AS age_seconds,
+	       COALESCE(i.items, '[]'::jsonb)
+	FROM orders o
+	LEFT JOIN LATERAL (
+		SELECT jsonb_agg(jsonb_build_object(
+		           'order_item_id', oi.order_item_id,
+		           'product_id', oi.menu_item_id,
+		           'name', mi.name,
+		           'quantity', oi.quantity,
+		           'modifiers', COALESCE(oi.customization_options, '[]'::jsonb),
+		           'done', oi.done_at IS NOT NULL,
+		           'done_at', oi.done_at::timestamptz) ORDER BY oi.order_item_id) AS items
+		FROM order_items oi
+		JOIN menu_items mi ON mi.menu_item_id = oi.menu_item_id
+		WHERE oi.order_id = o.order_id
+	) i ON true
+	WHERE o.status IN ('pending', 'accepted', 'processing')
+	  AND ($1 = '' OR o.station IS NULL OR o.station = $1)
+	ORDER BY o.priority DESC, o.created_at, o.order_id`
+
+	rows, err := repo.db.QueryContext(ctx, query, station)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query queue: %w", err)
+	}
+	defer rows.Close()
+
+	queue := []models.QueueOrder{}
+	for rows.Next() {
+		var order models.QueueOrder
+		var instructions, items []byte
+		if err := rows.Scan(&order.OrderID, &order.CustomerName, &order.Status, &order.Priority, &instructions,
+			&order.Station, &order.HandledBy, &order.ClaimedAt, &order.CreatedAt, &order.AgeSeconds, &items); err != nil {
+			return nil, fmt.Errorf("failed to scan queue order: %w", err)
+		}
+		if len(instructions) > 0 {
+			if err := json.Unmarshal(instructions, &order.SpecialInstructions); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal special instructions: %w", err)
+			}
+		}
+		if err := json.Unmarshal(items, &order.Items); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal queue items: %w", err)
+		}
+		queue = append(queue, order)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over queue: %w", err)
+	}
+	return queue, nil
+}
+
+// Claim assigns an unclaimed order to a station and barista and moves it to
+// processing.
+func (repo *QueueRepository) Claim(ctx context.Context, orderID int, claim models.QueueClaim) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	status, claimed, err := lockQueueOrder(ctx, tx, orderID)
+	if err != nil {
+		return err
+	}
+	if status != "pending" && status != "accepted" && status != "processing" {
+		return models.Conflict("order is not active: %s", status)
+	}
+	if claimed {
+		return models.Conflict("order is already claimed")
+	}
+
+	before, err := snapshot(ctx, tx, orderSnapshot, orderID)
+	if err != nil {
+		return err
+	}
+
+	query := `
+	UPDATE orders
+	SET status = 'processing', station = NULLIF($1, ''), handled_by = $2,
+	    claimed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+	WHERE order_id = $3`
+	if _, err := tx.ExecContext(ctx, query, claim.Station, claim.HandledBy, orderID); err != nil {
+		return fmt.Errorf("failed to claim order: %w", err)
+	}
+	if status != "processing" {
+		if err := insertStatusHistory(ctx, tx, orderID, "processing"); err != nil {
+			return err
+		}
+	}
+	if err := auditQueueChange(ctx, tx, orderID, before); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (repo *QueueRepository) CompleteItem(ctx context.Context, orderID, orderItemID int) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	status, claimed, err := lockQueueOrder(ctx, tx, orderID)
+	if err != nil {
+		return err
+	}
+	if status != "processing" || !claimed {
+		return models.Conflict("order must be claimed first")
+	}
+
+	query := `UPDATE order_items SET done_at = COALESCE(done_at, CURRENT_TIMESTAMP) WHERE order_id = $1 AND order_item_id = $2`
+	result, err := tx.ExecContext(ctx, query, orderID, orderItemID)
+	if err != nil {
+		return fmt.Errorf("failed to mark order item as done: %w", err)
+	}
+	numRows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if numRows == 0 {
+		return models.NotFound("order item not found")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Bump marks the remaining items as done and the order as ready for pickup.
+func (repo *QueueRepository) Bump(ctx context.Context, orderID int) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	status, claimed, err := lockQueueOrder(ctx, tx, orderID)
+	if err != nil {
+		return err
+	}
+	if status != "processing" || !claimed {
+		return models.Conflict("order must be claimed first")
+	}
+
+	before, err := snapshot(ctx, tx, orderSnapshot, orderID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE order_items SET done_at = CURRENT_TIMESTAMP WHERE order_id = $1 AND done_at IS NULL`, orderID); err != nil {
+		return fmt.Errorf("failed to mark order items as done: %w", err)
+	}
+	query := `UPDATE orders SET status = 'ready', ready_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP WHERE order_id = $1`
+	if _, err := tx.ExecContext(ctx, query, orderID); err != nil {
+		return fmt.Errorf("failed to bump order: %w", err)
+	}
+	if err := insertStatusHistory(ctx, tx, orderID, "ready"); err != nil {
+		return err
+	}
+	if err := enqueueOrderEvent(ctx, tx, "order.ready", orderID); err != nil {
+		return err
+	}
+	if err := auditQueueChange(ctx, tx, orderID, before); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func lockQueueOrder(ctx context.Context, tx *sql.Tx, orderID int) (string, bool, error) {
+	var status string
+	var claimed bool
+	err := tx.QueryRowContext(ctx, `SELECT status, claimed_at IS NOT NULL FROM orders WHERE order_id = $1 FOR UPDATE`, orderID).Scan(&status, &claimed)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", false, models.NotFound("order with ID %d not found", orderID)
+		}
+		return "", false, fmt.Errorf("failed to get order: %w", err)
+	}
+	return status, claimed, nil
+}
+
+func insertStatusHistory(ctx context.Context, tx *sql.Tx, orderID int, status string) error {
+	if _, err := tx.ExecContext(ctx, `INSERT INTO order_status_history (order_id, status) VALUES ($1, $2)`, orderID, status); err != nil {
+		return fmt.Errorf("failed to insert order status history: %w", err)
+	}
+	return nil
+}
+
+// auditQueueChange records the change a station made to an order, from the
+// snapshot taken before it.
+func auditQueueChange(ctx context.Context, tx *sql.Tx, orderID int, before []byte) error {
+	after, err := snapshot(ctx, tx, orderSnapshot, orderID)
+	if err != nil {
+		return err
+	}
+	return recordAudit(ctx, tx, models.AuditUpdate, models.AuditOrder, orderID, before, after)
+}
@@ -3,6 +3,7 @@ package dal
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"frappuccino/models"
 	"log"
@@ -247,3 +248,259 @@ func (r *ReportRepository) GetOrderedItems(ctx context.Context) ([]models.Order,
 
 	return orders, nil
 }
+
+// GetInventoryValuation returns stock quantities with the data needed to cost
+// them. With a nil date the current inventory quantity is used, otherwise the
+// quantity is reconstructed from the ledger up to the end of that day.
+func (r *ReportRepository) GetInventoryValuation(ctx context.Context, date *string) ([]models.InventoryValuationItem, error) {
+	query := `
+	SELECT i.ingredient_id, i.name, i.unit,
+	       CASE WHEN $1::date IS NULL THEN i.quantity ELSE COALESCE(l.quantity, 0) END,
+	       i.cost_per_unit,
+	       COALESCE(l.purchased_quantity, 0),
+	       COALESCE(l.purchased_value, 0)
+	FROM inventory i
+	LEFT JOIN (
+		SELECT it.ingredient_id,
+		       SUM(` + signedChange + `) AS quantity,
+		       SUM(ABS(it.quantity_change)) FILTER (WHERE it.transaction_type = 'addition' AND it.unit_cost IS NOT NULL
+		           AND it.reason IN ('opening_balance', 'restock', 'manual_adjustment')) AS purchased_quantity,
+		       SUM(ABS(it.quantity_change) * it.unit_cost) FILTER (WHERE it.transaction_type = 'addition' AND it.unit_cost IS NOT NULL
+		           AND it.reason IN ('opening_balance', 'restock', 'manual_adjustment')) AS purchased_value
+		FROM inventory_transactions it
+		WHERE $1::date IS NULL OR it.transaction_date < $1::date + 1
+		GROUP BY it.ingredient_id
+	) l ON l.ingredient_id = i.ingredient_id
+	WHERE $1::date IS NULL OR l.ingredient_id IS NOT NULL
+	ORDER BY i.name`
+
+	rows, err := r.db.QueryContext(ctx, query, date)
+	if err != nil {
+		return nil, fmt.Errorf("could not load inventory valuation: %w", err)
+	}
+	defer rows.Close()
+
+	items := []models.InventoryValuationItem{}
+	for rows.Next() {
+		var item models.InventoryValuationItem
+		if err := rows.Scan(&item.IngredientID, &item.Name, &item.Unit, &item.Quantity, &item.UnitCost,
+			&item.PurchasedQuantity, &item.PurchasedValue); err != nil {
+			return nil, fmt.Errorf("could not scan inventory valuation row: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over inventory valuation: %w", err)
+	}
+
+	return items, nil
+}
+
+// GetIngredientUsage returns theoretical usage from completed orders and
+// actual usage from order deductions and count corrections in the ledger.
+func (r *ReportRepository) GetIngredientUsage(ctx context.Context, startDate, endDate *string) ([]models.IngredientUsage, error) {
+	query := `
+	WITH theoretical AS (
+		SELECT mii.inventory_id AS ingredient_id, SUM(oi.quantity * mii.quantity) AS quantity
+		FROM orders o
+		JOIN order_items oi ON oi.order_id = o.order_id
+		JOIN menu_item_ingredients mii ON mii.menu_item_id = oi.menu_item_id
+		WHERE o.status = 'completed'
+		  AND ($1::date IS NULL OR o.created_at >= $1::date)
+		  AND ($2::date IS NULL OR o.created_at < $2::date + 1)
+		GROUP BY mii.inventory_id
+	), actual AS (
+		SELECT it.ingredient_id,
+		       -SUM(` + signedChange + `) FILTER (WHERE it.reason = 'order') AS order_usage,
+		       -SUM(` + signedChange + `) FILTER (WHERE it.reason = 'count_correction') AS correction_usage
+		FROM inventory_transactions it
+		WHERE it.reason IN ('order', 'count_correction')
+		  AND ($1::date IS NULL OR it.transaction_date >= $1::date)
+		  AND ($2::date IS NULL OR it.transaction_date < $2::date + 1)
+		GROUP BY it.ingredient_id
+	)
+	SELECT i.ingredient_id, i.name, i.unit, i.cost_per_unit,
+	       COALESCE(t.quantity, 0), COALESCE(a.order_usage, 0), COALESCE(a.correction_usage, 0)
+	FROM inventory i
+	LEFT JOIN theoretical t ON t.ingredient_id = i.ingredient_id
+	LEFT JOIN actual a ON a.ingredient_id = i.ingredient_id
+	WHERE t.ingredient_id IS NOT NULL OR a.ingredient_id IS NOT NULL
+	ORDER BY i.name`
+
+	rows, err := r.db.QueryContext(ctx, query, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("could not load ingredient usage: %w", err)
+	}
+	defer rows.Close()
+
+	usage := []models.IngredientUsage{}
+	for rows.Next() {
+		var item models.IngredientUsage
+		if err := rows.Scan(&item.IngredientID, &item.Name, &item.Unit, &item.UnitCost,
+			&item.TheoreticalUsage, &item.OrderUsage, &item.CountCorrections); err != nil {
+			return nil, fmt.Errorf("could not scan ingredient usage row: %w", err)
+		}
+		usage = append(usage, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over ingredient usage: %w", err)
+	}
+
+	return usage, nil
+}
+
+// GetSalesSummary totals completed orders in the period and the discounts
+// given by each promotion.
+func (r *ReportRepository) GetSalesSummary(ctx context.Context, startDate, endDate *string) (models.SalesSummary, error) {
+	summary := models.SalesSummary{StartDate: startDate, EndDate: endDate}
+	query := `
+	WITH period AS (
+		SELECT order_id, subtotal, discount_amount, tax_amount, total_amount
+		FROM orders
+		WHERE status = 'completed'
+		  AND ($1::date IS NULL OR created_at >= $1::date)
+		  AND ($2::date IS NULL OR created_at < $2::date + 1)
+	)
+	SELECT COUNT(*), COALESCE(SUM(subtotal), 0), COALESCE(SUM(discount_amount), 0), COALESCE(SUM(tax_amount), 0),
+	       COALESCE(SUM(total_amount), 0),
+	       COALESCE((SELECT SUM(op.discount_amount) FROM order_promotions op JOIN period p ON p.order_id = op.order_id), 0)
+	FROM period`
+	err := r.db.QueryRowContext(ctx, query, startDate, endDate).Scan(&summary.Orders, &summary.GrossSales,
+		&summary.TotalDiscounts, &summary.Tax, &summary.TotalCharged, &summary.PromotionDiscounts)
+	if err != nil {
+		return models.SalesSummary{}, fmt.Errorf("could not load sales summary: %w", err)
+	}
+
+	promotionsQuery := `
+	SELECT pr.promotion_id, pr.name, pr.code, COUNT(*), SUM(op.discount_amount)
+	FROM order_promotions op
+	JOIN orders o ON o.order_id = op.order_id
+	JOIN promotions pr ON pr.promotion_id = op.promotion_id
+	WHERE o.status = 'completed'
+	  AND ($1::date IS NULL OR o.created_at >= $1::date)
+	  AND ($2::date IS NULL OR o.created_at < $2::date + 1)
+	GROUP BY pr.promotion_id, pr.name, pr.code
+	ORDER BY SUM(op.discount_amount) DESC, pr.promotion_id`
+	rows, err := r.db.QueryContext(ctx, promotionsQuery, startDate, endDate)
+	if err != nil {
+		return models.SalesSummary{}, fmt.Errorf("could not load promotion sales: %w", err)
+	}
+	defer rows.Close()
+
+	summary.Promotions = []models.PromotionSales{}
+	for rows.Next() {
+		var promotion models.PromotionSales
+		if err := rows.Scan(&promotion.PromotionID, &promotion.Name, &promotion.Code, &promotion.Orders,
+			&promotion.DiscountAmount); err != nil {
+			return models.SalesSummary{}, fmt.Errorf("could not scan promotion sales row: %w", err)
+		}
+		summary.Promotions = append(summary.Promotions, promotion)
+	}
+	if err := rows.Err(); err != nil {
+		return models.SalesSummary{}, fmt.Errorf("error iterating over promotion sales: %w", err)
+	}
+
+	return summary, nil
+}
+
+// GetTaxReport totals the tax collected on completed orders in the period
+// per rate and order type.
+func (r *ReportRepository) GetTaxReport(ctx context.Context, startDate, endDate *string) (models.TaxReport, error) {
+	report := models.TaxReport{StartDate: startDate, EndDate: endDate}
+
+	var inclusive sql.NullString
+	err := r.db.QueryRowContext(ctx, `SELECT value FROM settings WHERE key = 'tax_inclusive_pricing'`).Scan(&inclusive)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return models.TaxReport{}, fmt.Errorf("could not load pricing mode: %w", err)
+	}
+	report.PricesInclTax = inclusive.String == "true"
+
+	query := `
+	SELECT t.tax_rate_id, tr.name, t.rate, o.order_type::text, COUNT(DISTINCT o.order_id),
+	       SUM(t.taxable_amount), SUM(t.tax_amount)
+	FROM order_item_taxes t
+	JOIN orders o ON o.order_id = t.order_id
+	JOIN tax_rates tr ON tr.tax_rate_id = t.tax_rate_id
+	WHERE o.status = 'completed'
+	  AND ($1::date IS NULL OR o.created_at >= $1::date)
+	  AND ($2::date IS NULL OR o.created_at < $2::date + 1)
+	GROUP BY t.tax_rate_id, tr.name, t.rate, o.order_type
+	ORDER BY t.tax_rate_id, t.rate, o.order_type`
+	rows, err := r.db.QueryContext(ctx, query, startDate, endDate)
+	if err != nil {
+		return models.TaxReport{}, fmt.Errorf("could not load tax report: %w", err)
+	}
+	defer rows.Close()
+
+	report.Rates = []models.TaxReportLine{}
+	for rows.Next() {
+		var line models.TaxReportLine
+		if err := rows.Scan(&line.TaxRateID, &line.Name, &line.Rate, &line.OrderType, &line.Orders,
+			&line.TaxableAmount, &line.TaxAmount); err != nil {
+			return models.TaxReport{}, fmt.Errorf("could not scan tax report row: %w", err)
+		}
+		report.Rates = append(report.Rates, line)
+	}
+	if err := rows.Err(); err != nil {
+		return models.TaxReport{}, fmt.Errorf("error iterating over tax report: %w", err)
+	}
+
+	return report, nil
+}
+
+// GetLaborReport totals the scheduled and worked hours of each employee in
+// the period, by the day shifts start and entries are clocked in, and the
+// net sales of completed orders in the same period.
+func (r *ReportRepository) GetLaborReport(ctx context.Context, startDate, endDate *string) (models.LaborReport, error) {
+	report := models.LaborReport{StartDate: startDate, EndDate: endDate}
+
+	query := `
+	SELECT e.employee_id, e.name, e.role::text, e.hourly_rate, COALESCE(s.hours, 0), COALESCE(w.hours, 0)
+	FROM employees e
+	LEFT JOIN (
+		SELECT employee_id, SUM(EXTRACT(EPOCH FROM ends_at - starts_at) / 3600) AS hours
+		FROM shifts
+		WHERE ($1::date IS NULL OR starts_at >= $1::date)
+		  AND ($2::date IS NULL OR starts_at < $2::date + 1)
+		GROUP BY employee_id
+	) s ON s.employee_id = e.employee_id
+	LEFT JOIN (
+		SELECT t.employee_id, SUM(` + entryHours + `) AS hours
+		FROM time_entries t
+		WHERE ($1::date IS NULL OR t.clock_in >= $1::date)
+		  AND ($2::date IS NULL OR t.clock_in < $2::date + 1)
+		GROUP BY t.employee_id
+	) w ON w.employee_id = e.employee_id
+	WHERE s.hours IS NOT NULL OR w.hours IS NOT NULL
+	ORDER BY e.name, e.employee_id`
+	rows, err := r.db.QueryContext(ctx, query, startDate, endDate)
+	if err != nil {
+		return models.LaborReport{}, fmt.Errorf("could not load labor hours: %w", err)
+	}
+	defer rows.Close()
+
+	report.Employees = []models.LaborLine{}
+	for rows.Next() {
+		var line models.LaborLine
+		if err := rows.Scan(&line.EmployeeID, &line.Name, &line.Role, &line.HourlyRate, &line.ScheduledHours,
+			&line.WorkedHours); err != nil {
+			return models.LaborReport{}, fmt.Errorf("could not scan labor row: %w", err)
+		}
+		report.Employees = append(report.Employees, line)
+	}
+	if err := rows.Err(); err != nil {
+		return models.LaborReport{}, fmt.Errorf("error iterating over labor hours: %w", err)
+	}
+
+	salesQuery := `
+	SELECT COALESCE(SUM(subtotal - discount_amount), 0)
+	FROM orders
+	WHERE status = 'completed'
+	  AND ($1::date IS NULL OR created_at >= $1::date)
+	  AND ($2::date IS NULL OR created_at < $2::date + 1)`
+	if err := r.db.QueryRowContext(ctx, salesQuery, startDate, endDate).Scan(&report.NetSales); err != nil {
+		return models.LaborReport{}, fmt.Errorf("could not load net sales: %w", err)
+	}
+
+	return report, nil
+}
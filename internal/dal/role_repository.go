@@ -0,0 +1,99 @@
+package dal
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+
+	"github.com/lib/pq"
+)
+
+const rolePermissionsChannel = "role_permissions"
+
+type RoleRepository struct {
+	db     *sql.DB
+	dsn    string
+	logger *slog.Logger
+}
+
+type RoleInterface interface {
+	ListPermissions(ctx context.Context) (map[string][]string, error)
+	SetPermissions(ctx context.Context, role string, permissions []string) error
+	Listen(ctx context.Context) (<-chan struct{}, error)
+}
+
+func NewRoleRepository(db *sql.DB, dsn string, logger *slog.Logger) (*RoleRepository, error) {
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("unable to connect to database: %v", err)
+	}
+
+	return &RoleRepository{db: db, dsn: dsn, logger: logger}, nil
+}
+
+// ListPermissions returns the permissions of every role except the owner,
+// including roles that have none.
+func (repo *RoleRepository) ListPermissions(ctx context.Context) (map[string][]string, error) {
+	query := `
+	SELECT r.role::text, rp.permission
+	FROM unnest(enum_range(NULL::employee_role)) AS r(role)
+	LEFT JOIN role_permissions rp ON rp.role = r.role
+	WHERE r.role <> 'owner'
+	ORDER BY r.role, rp.permission`
+	rows, err := repo.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query role permissions: %w", err)
+	}
+	defer rows.Close()
+
+	permissions := make(map[string][]string)
+	for rows.Next() {
+		var role string
+		var permission sql.NullString
+		if err := rows.Scan(&role, &permission); err != nil {
+			return nil, fmt.Errorf("failed to scan role permission: %w", err)
+		}
+		if _, ok := permissions[role]; !ok {
+			permissions[role] = []string{}
+		}
+		if permission.Valid {
+			permissions[role] = append(permissions[role], permission.String)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over role permissions: %w", err)
+	}
+	return permissions, nil
+}
+
+// SetPermissions replaces the permissions of a role.
+func (repo *RoleRepository) SetPermissions(ctx context.Context, role string, permissions []string) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM role_permissions WHERE role = $1::employee_role`, role); err != nil {
+		return fmt.Errorf("failed to clear role permissions: %w", err)
+	}
+	query := `
+	INSERT INTO role_permissions (role, permission)
+	SELECT $1::employee_role, p FROM unnest($2::text[]) AS p
+	ON CONFLICT DO NOTHING`
+	if _, err := tx.ExecContext(ctx, query, role, pq.Array(permissions)); err != nil {
+		return fmt.Errorf("failed to insert role permissions: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Listen subscribes to changes of role permissions, made by any instance or
+// directly in the database. The channel is signalled after every change and
+// every reconnect, and closed when ctx is done.
+func (repo *RoleRepository) Listen(ctx context.Context) (<-chan struct{}, error) {
+	return listen(ctx, repo.dsn, rolePermissionsChannel, repo.logger)
+}
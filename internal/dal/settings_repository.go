@@ -0,0 +1,83 @@
+package dal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"frappuccino/models"
+)
+
+type SettingsRepository struct {
+	db *sql.DB
+}
+
+type SettingsInterface interface {
+	Get(ctx context.Context, key string) (string, error)
+	List(ctx context.Context) (map[string]string, error)
+	Set(ctx context.Context, values map[string]string) error
+}
+
+func NewSettingsRepository(db *sql.DB) (*SettingsRepository, error) {
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("unable to connect to database: %v", err)
+	}
+
+	return &SettingsRepository{db: db}, nil
+}
+
+func (repo *SettingsRepository) Get(ctx context.Context, key string) (string, error) {
+	var value string
+	err := repo.db.QueryRowContext(ctx, `SELECT value FROM settings WHERE key = $1`, key).Scan(&value)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", models.NotFound("setting %s not found", key)
+		}
+		return "", fmt.Errorf("failed to get setting %s: %w", key, err)
+	}
+	return value, nil
+}
+
+func (repo *SettingsRepository) List(ctx context.Context) (map[string]string, error) {
+	rows, err := repo.db.QueryContext(ctx, `SELECT key, value FROM settings ORDER BY key`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query settings: %w", err)
+	}
+	defer rows.Close()
+
+	settings := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan setting: %w", err)
+		}
+		settings[key] = value
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over settings: %w", err)
+	}
+	return settings, nil
+}
+
+// Set stores all values in one transaction.
+func (repo *SettingsRepository) Set(ctx context.Context, values map[string]string) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+	INSERT INTO settings (key, value) VALUES ($1, $2)
+	ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, updated_at = CURRENT_TIMESTAMP`
+	for key, value := range values {
+		if _, err := tx.ExecContext(ctx, query, key, value); err != nil {
+			return fmt.Errorf("failed to save setting %s: %w", key, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
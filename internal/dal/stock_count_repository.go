@@ -0,0 +1,274 @@
+package dal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"frappuccino/models"
+	"strings"
+	"time"
+)
+
+type StockCountRepository struct {
+	db *sql.DB
+}
+
+type StockCountInterface interface {
+	Create(ctx context.Context, note string) (int, error)
+	GetByID(ctx context.Context, countID int) (models.StockCount, error)
+	List(ctx context.Context) ([]models.StockCount, error)
+	SubmitLines(ctx context.Context, countID int, lines []models.StockCountSubmission) error
+	Finalize(ctx context.Context, countID int, employeeID *int) error
+}
+
+func NewStockCountRepository(db *sql.DB) (*StockCountRepository, error) {
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("unable to connect to database: %v", err)
+	}
+
+	return &StockCountRepository{db: db}, nil
+}
+
+func (repo *StockCountRepository) Create(ctx context.Context, note string) (int, error) {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Only one session can be open, otherwise corrections would overlap
+	if _, err := tx.ExecContext(ctx, `LOCK TABLE stock_counts IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return 0, fmt.Errorf("failed to lock stock counts: %w", err)
+	}
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM stock_counts WHERE status = 'open')`).Scan(&exists); err != nil {
+		return 0, fmt.Errorf("failed to check open stock counts: %w", err)
+	}
+	if exists {
+		return 0, models.Conflict("another stock count is already open")
+	}
+
+	var countID int
+	query := `INSERT INTO stock_counts (note) VALUES ($1) RETURNING count_id`
+	if err := tx.QueryRowContext(ctx, query, note).Scan(&countID); err != nil {
+		return 0, fmt.Errorf("failed to create stock count: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return countID, nil
+}
+
+func (repo *StockCountRepository) GetByID(ctx context.Context, countID int) (models.StockCount, error) {
+	var count models.StockCount
+	var note sql.NullString
+	query := `SELECT count_id, status, note, created_at, finalized_at FROM stock_counts WHERE count_id = $1`
+	err := repo.db.QueryRowContext(ctx, query, countID).Scan(&count.ID, &count.Status, &note, &count.CreatedAt, &count.FinalizedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.StockCount{}, models.NotFound("stock count not found")
+		}
+		return models.StockCount{}, fmt.Errorf("failed to scan stock count: %w", err)
+	}
+	count.Note = note.String
+
+	// Expected usage is counted from the previous finalized session
+	var since *time.Time
+	sinceQuery := `SELECT MAX(finalized_at) FROM stock_counts WHERE status = 'finalized' AND finalized_at <= $1`
+	if err := repo.db.QueryRowContext(ctx, sinceQuery, count.CreatedAt).Scan(&since); err != nil {
+		return models.StockCount{}, fmt.Errorf("failed to get previous stock count: %w", err)
+	}
+
+	linesQuery := `
+	SELECT l.ingredient_id, i.name, i.unit, l.counted_quantity, l.system_quantity,
+	       i.cost_per_unit, l.counted_at, COALESCE(u.expected, 0)
+	FROM stock_count_lines l
+	JOIN inventory i ON i.ingredient_id = l.ingredient_id
+	LEFT JOIN LATERAL (
+		SELECT SUM(oi.quantity * mii.quantity) AS expected
+		FROM orders o
+		JOIN order_items oi ON oi.order_id = o.order_id
+		JOIN menu_item_ingredients mii ON mii.menu_item_id = oi.menu_item_id
+		WHERE mii.inventory_id = l.ingredient_id
+		  AND o.status NOT IN ('cancelled', 'rejected')
+		  AND ($2::timestamp IS NULL OR o.created_at > $2::timestamp)
+		  AND o.created_at <= l.counted_at
+	) u ON TRUE
+	WHERE l.count_id = $1
+	ORDER BY i.name`
+
+	rows, err := repo.db.QueryContext(ctx, linesQuery, countID, since)
+	if err != nil {
+		return models.StockCount{}, fmt.Errorf("failed to query stock count lines: %w", err)
+	}
+	defer rows.Close()
+
+	count.Lines = []models.StockCountLine{}
+	for rows.Next() {
+		var line models.StockCountLine
+		if err := rows.Scan(&line.IngredientID, &line.Name, &line.Unit, &line.CountedQuantity, &line.SystemQuantity,
+			&line.UnitCost, &line.CountedAt, &line.ExpectedUsage); err != nil {
+			return models.StockCount{}, fmt.Errorf("failed to scan stock count line: %w", err)
+		}
+		count.Lines = append(count.Lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		return models.StockCount{}, fmt.Errorf("error iterating over stock count lines: %w", err)
+	}
+
+	return count, nil
+}
+
+func (repo *StockCountRepository) List(ctx context.Context) ([]models.StockCount, error) {
+	query := `SELECT count_id, status, COALESCE(note, ''), created_at, finalized_at FROM stock_counts ORDER BY count_id DESC`
+	rows, err := repo.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stock counts: %w", err)
+	}
+	defer rows.Close()
+
+	counts := []models.StockCount{}
+	for rows.Next() {
+		var count models.StockCount
+		if err := rows.Scan(&count.ID, &count.Status, &count.Note, &count.CreatedAt, &count.FinalizedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan stock count: %w", err)
+		}
+		counts = append(counts, count)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over stock counts: %w", err)
+	}
+	return counts, nil
+}
+
+// SubmitLines stores counted quantities. Submitting an ingredient again
+// replaces the previous count and takes a fresh system quantity snapshot.
+func (repo *StockCountRepository) SubmitLines(ctx context.Context, countID int, lines []models.StockCountSubmission) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := lockOpenStockCount(ctx, tx, countID); err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO stock_count_lines (count_id, ingredient_id, counted_quantity, system_quantity)
+	SELECT $1, ingredient_id, $3, quantity FROM inventory WHERE ingredient_id = $2
+	ON CONFLICT (count_id, ingredient_id) DO UPDATE
+	SET counted_quantity = EXCLUDED.counted_quantity,
+	    system_quantity = EXCLUDED.system_quantity,
+	    counted_at = CURRENT_TIMESTAMP`
+	for _, line := range lines {
+		result, err := tx.ExecContext(ctx, query, countID, line.IngredientID, line.CountedQuantity)
+		if err != nil {
+			return fmt.Errorf("failed to save counted quantity of ingredient %d: %w", line.IngredientID, err)
+		}
+		numRows, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get affected rows: %w", err)
+		}
+		if numRows == 0 {
+			return models.NotFound("ingredient not found: %d", line.IngredientID)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Finalize posts a count_correction movement for every line whose counted
+// quantity differs from the snapshot. The variance is applied to the current
+// quantity, so sales made after counting are kept.
+func (repo *StockCountRepository) Finalize(ctx context.Context, countID int, employeeID *int) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := lockOpenStockCount(ctx, tx, countID); err != nil {
+		return err
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT l.ingredient_id, i.name, l.counted_quantity - l.system_quantity, i.quantity
+		FROM stock_count_lines l
+		JOIN inventory i ON i.ingredient_id = l.ingredient_id
+		WHERE l.count_id = $1
+		ORDER BY l.ingredient_id
+		FOR UPDATE OF i`, countID)
+	if err != nil {
+		return fmt.Errorf("failed to query stock count lines: %w", err)
+	}
+
+	type correction struct {
+		ingredientID int
+		delta        float64
+	}
+	var corrections []correction
+	var negative []string
+	for rows.Next() {
+		var ingredientID int
+		var name string
+		var variance, current float64
+		if err := rows.Scan(&ingredientID, &name, &variance, &current); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan stock count line: %w", err)
+		}
+		// More may have been used since counting; the count is then stale
+		// rather than the stock being negative
+		if current+variance < 0 {
+			negative = append(negative, fmt.Sprintf("%s (%g in stock, variance %g)", name, current, variance))
+			continue
+		}
+		if variance != 0 {
+			corrections = append(corrections, correction{ingredientID: ingredientID, delta: variance})
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating over stock count lines: %w", err)
+	}
+	if len(negative) > 0 {
+		return models.Conflict("stock count would leave negative stock for %s. Recount these ingredients.", strings.Join(negative, ", "))
+	}
+
+	for _, c := range corrections {
+		if err := adjustQuantity(ctx, tx, c.ingredientID, c.delta, "count_correction", employeeID); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE stock_counts SET status = 'finalized', finalized_at = CURRENT_TIMESTAMP WHERE count_id = $1`, countID)
+	if err != nil {
+		return fmt.Errorf("failed to finalize stock count: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func lockOpenStockCount(ctx context.Context, tx *sql.Tx, countID int) error {
+	var status string
+	err := tx.QueryRowContext(ctx, `SELECT status FROM stock_counts WHERE count_id = $1 FOR UPDATE`, countID).Scan(&status)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.NotFound("stock count not found")
+		}
+		return fmt.Errorf("failed to get stock count: %w", err)
+	}
+	if status != "open" {
+		return models.Conflict("stock count is not open")
+	}
+	return nil
+}
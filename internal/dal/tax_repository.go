@@ -0,0 +1,144 @@
+package dal
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"frappuccino/models"
+)
+
+type TaxRepository struct {
+	db *sql.DB
+}
+
+type TaxInterface interface {
+	Create(ctx context.Context, rate *models.TaxRate) error
+	GetByID(ctx context.Context, id int) (models.TaxRate, error)
+	List(ctx context.Context) ([]models.TaxRate, error)
+	Update(ctx context.Context, rate models.TaxRate, id int) error
+	Deactivate(ctx context.Context, id int) error
+	ListApplicable(ctx context.Context, orderType string) ([]models.TaxRate, error)
+}
+
+func NewTaxRepository(db *sql.DB) (*TaxRepository, error) {
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("unable to connect to database: %v", err)
+	}
+
+	return &TaxRepository{db: db}, nil
+}
+
+func (repo *TaxRepository) Create(ctx context.Context, rate *models.TaxRate) error {
+	query := `
+	INSERT INTO tax_rates (name, rate, category, order_type, active)
+	VALUES ($1, $2, $3, $4, COALESCE($5, TRUE))
+	RETURNING tax_rate_id, active, created_at`
+	var active bool
+	err := repo.db.QueryRowContext(ctx, query, rate.Name, rate.Rate, rate.Category, rate.OrderType, rate.Active).
+		Scan(&rate.ID, &active, &rate.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create tax rate: %w", err)
+	}
+	rate.Active = &active
+	return nil
+}
+
+func (repo *TaxRepository) GetByID(ctx context.Context, id int) (models.TaxRate, error) {
+	rates, err := repo.query(ctx, `SELECT tax_rate_id, name, rate, category, order_type, active, created_at FROM tax_rates WHERE tax_rate_id = $1`, id)
+	if err != nil {
+		return models.TaxRate{}, err
+	}
+	if len(rates) == 0 {
+		return models.TaxRate{}, models.NotFound("tax rate with ID %d not found", id)
+	}
+	return rates[0], nil
+}
+
+func (repo *TaxRepository) List(ctx context.Context) ([]models.TaxRate, error) {
+	return repo.query(ctx, `SELECT tax_rate_id, name, rate, category, order_type, active, created_at FROM tax_rates ORDER BY tax_rate_id`)
+}
+
+// Update changes a rate for future orders; taxes of existing orders keep
+// the rate they were charged with.
+func (repo *TaxRepository) Update(ctx context.Context, rate models.TaxRate, id int) error {
+	query := `
+	UPDATE tax_rates
+	SET name = $1, rate = $2, category = $3, order_type = $4, active = COALESCE($5, active)
+	WHERE tax_rate_id = $6`
+	result, err := repo.db.ExecContext(ctx, query, rate.Name, rate.Rate, rate.Category, rate.OrderType, rate.Active, id)
+	if err != nil {
+		return fmt.Errorf("failed to update tax rate: %w", err)
+	}
+	numRows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if numRows == 0 {
+		return models.NotFound("tax rate with ID %d not found", id)
+	}
+	return nil
+}
+
+func (repo *TaxRepository) Deactivate(ctx context.Context, id int) error {
+	result, err := repo.db.ExecContext(ctx, `UPDATE tax_rates SET active = FALSE WHERE tax_rate_id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to deactivate tax rate: %w", err)
+	}
+	numRows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if numRows == 0 {
+		return models.NotFound("tax rate with ID %d not found", id)
+	}
+	return nil
+}
+
+// ListApplicable returns the active rates for an order type. Matching
+// categories is left to the caller, since it depends on each line.
+func (repo *TaxRepository) ListApplicable(ctx context.Context, orderType string) ([]models.TaxRate, error) {
+	query := `
+	SELECT tax_rate_id, name, rate, category, order_type, active, created_at
+	FROM tax_rates
+	WHERE active AND (order_type IS NULL OR order_type = $1::order_type)
+	ORDER BY tax_rate_id`
+	return repo.query(ctx, query, orderType)
+}
+
+func (repo *TaxRepository) query(ctx context.Context, query string, args ...interface{}) ([]models.TaxRate, error) {
+	rows, err := repo.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tax rates: %w", err)
+	}
+	defer rows.Close()
+
+	rates := []models.TaxRate{}
+	for rows.Next() {
+		var rate models.TaxRate
+		var active bool
+		if err := rows.Scan(&rate.ID, &rate.Name, &rate.Rate, &rate.Category, &rate.OrderType, &active, &rate.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan tax rate: %w", err)
+		}
+		rate.Active = &active
+		rates = append(rates, rate)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over tax rates: %w", err)
+	}
+	return rates, nil
+}
+
+func insertOrderTaxes(ctx context.Context, tx *sql.Tx, orderID int, taxes []models.OrderTax) error {
+	query := `
+	INSERT INTO order_item_taxes (order_id, menu_item_id, tax_rate_id, rate, taxable_amount, tax_amount)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	ON CONFLICT (order_id, menu_item_id, tax_rate_id) DO UPDATE
+	SET taxable_amount = order_item_taxes.taxable_amount + EXCLUDED.taxable_amount,
+	    tax_amount = order_item_taxes.tax_amount + EXCLUDED.tax_amount`
+	for _, tax := range taxes {
+		if _, err := tx.ExecContext(ctx, query, orderID, tax.ProductID, tax.TaxRateID, tax.Rate, tax.TaxableAmount, tax.TaxAmount); err != nil {
+			return fmt.Errorf("failed to insert order tax: %w", err)
+		}
+	}
+	return nil
+}
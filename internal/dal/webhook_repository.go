@@ -0,0 +1,330 @@
+package dal
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"frappuccino/models"
+
+	"github.com/lib/pq"
+)
+
+type WebhookRepository struct {
+	db *sql.DB
+}
+
+type WebhookInterface interface {
+	CreateSubscription(ctx context.Context, sub models.WebhookSubscription) (models.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id int) error
+	FanOut(ctx context.Context, limit int) error
+	ClaimDue(ctx context.Context, limit int) ([]models.WebhookDelivery, error)
+	MarkDelivered(ctx context.Context, deliveryID int64, statusCode int) error
+	MarkFailed(ctx context.Context, deliveryID int64, statusCode int, lastError string, retryAfterSeconds int) error
+	MarkDead(ctx context.Context, deliveryID int64, statusCode int, lastError string) error
+	ListDeadLetters(ctx context.Context) ([]models.WebhookDeadLetter, error)
+	Redeliver(ctx context.Context, deliveryID int64) error
+}
+
+func NewWebhookRepository(db *sql.DB) (*WebhookRepository, error) {
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("unable to connect to database: %v", err)
+	}
+
+	return &WebhookRepository{db: db}, nil
+}
+
+func (repo *WebhookRepository) CreateSubscription(ctx context.Context, sub models.WebhookSubscription) (models.WebhookSubscription, error) {
+	query := `
+	INSERT INTO webhook_subscriptions (url, event_types, secret)
+	VALUES ($1, $2, $3)
+	RETURNING subscription_id, active, created_at`
+	err := repo.db.QueryRowContext(ctx, query, sub.URL, pq.Array(sub.EventTypes), sub.Secret).Scan(&sub.ID, &sub.Active, &sub.CreatedAt)
+	if err != nil {
+		return models.WebhookSubscription{}, fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+	return sub, nil
+}
+
+func (repo *WebhookRepository) ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	query := `SELECT subscription_id, url, event_types, active, created_at FROM webhook_subscriptions ORDER BY subscription_id`
+	rows, err := repo.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	subs := []models.WebhookSubscription{}
+	for rows.Next() {
+		var sub models.WebhookSubscription
+		if err := rows.Scan(&sub.ID, &sub.URL, pq.Array(&sub.EventTypes), &sub.Active, &sub.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook subscription: %w", err)
+		}
+		subs = append(subs, sub)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over webhook subscriptions: %w", err)
+	}
+	return subs, nil
+}
+
+func (repo *WebhookRepository) DeleteSubscription(ctx context.Context, id int) error {
+	result, err := repo.db.ExecContext(ctx, `DELETE FROM webhook_subscriptions WHERE subscription_id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+	numRows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if numRows == 0 {
+		return models.NotFound("webhook subscription not found")
+	}
+	return nil
+}
+
+// FanOut creates a delivery for every active subscription of each
+// undispatched outbox event. Events are locked with SKIP LOCKED, so several
+// instances can run the dispatcher at once.
+func (repo *WebhookRepository) FanOut(ctx context.Context, limit int) error {
+	query := `
+	WITH events AS (
+		SELECT event_id, event_type FROM outbox_events
+		WHERE dispatched_at IS NULL
+		ORDER BY event_id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	), deliveries AS (
+		INSERT INTO webhook_deliveries (subscription_id, event_id)
+		SELECT s.subscription_id, e.event_id
+		FROM events e
+		JOIN webhook_subscriptions s
+		  ON s.active AND (e.event_type = ANY(s.event_types) OR '*' = ANY(s.event_types))
+		ON CONFLICT (subscription_id, event_id) DO NOTHING
+	)
+	UPDATE outbox_events SET dispatched_at = CURRENT_TIMESTAMP
+	WHERE event_id IN (SELECT event_id FROM events)`
+	if _, err := repo.db.ExecContext(ctx, query, limit); err != nil {
+		return fmt.Errorf("failed to fan out outbox events: %w", err)
+	}
+	return nil
+}
+
+// ClaimDue returns deliveries whose next attempt is due. Claimed deliveries
+// are leased for a few minutes so another instance doesn't send them too.
+func (repo *WebhookRepository) ClaimDue(ctx context.Context, limit int) ([]models.WebhookDelivery, error) {
+	query := `
+	WITH due AS (
+		SELECT delivery_id FROM webhook_deliveries
+		WHERE status = 'pending' AND next_attempt_at <= CURRENT_TIMESTAMP
+		ORDER BY next_attempt_at
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	), leased AS (
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = CURRENT_TIMESTAMP + INTERVAL '5 minutes'
+		FROM due
+		WHERE d.delivery_id = due.delivery_id
+		RETURNING d.delivery_id, d.subscription_id, d.event_id, d.attempts
+	)
+	SELECT l.delivery_id, l.attempts, s.url, s.secret, e.event_id, e.event_type, e.payload, e.created_at
+	FROM leased l
+	JOIN webhook_subscriptions s ON s.subscription_id = l.subscription_id
+	JOIN outbox_events e ON e.event_id = l.event_id
+	ORDER BY l.delivery_id`
+	rows, err := repo.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []models.WebhookDelivery
+	for rows.Next() {
+		var d models.WebhookDelivery
+		var payload []byte
+		if err := rows.Scan(&d.ID, &d.Attempts, &d.URL, &d.Secret, &d.EventID, &d.EventType, &payload, &d.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		d.Payload = payload
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+func (repo *WebhookRepository) MarkDelivered(ctx context.Context, deliveryID int64, statusCode int) error {
+	query := `
+	UPDATE webhook_deliveries
+	SET status = 'delivered', attempts = attempts + 1, last_status_code = $2, last_error = NULL, delivered_at = CURRENT_TIMESTAMP
+	WHERE delivery_id = $1`
+	if _, err := repo.db.ExecContext(ctx, query, deliveryID, statusCode); err != nil {
+		return fmt.Errorf("failed to mark webhook delivery as delivered: %w", err)
+	}
+	return nil
+}
+
+func (repo *WebhookRepository) MarkFailed(ctx context.Context, deliveryID int64, statusCode int, lastError string, retryAfterSeconds int) error {
+	query := `
+	UPDATE webhook_deliveries
+	SET attempts = attempts + 1, last_status_code = $2, last_error = $3,
+	    next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $4)
+	WHERE delivery_id = $1`
+	if _, err := repo.db.ExecContext(ctx, query, deliveryID, nullStatusCode(statusCode), lastError, retryAfterSeconds); err != nil {
+		return fmt.Errorf("failed to record webhook delivery failure: %w", err)
+	}
+	return nil
+}
+
+// MarkDead gives up on a delivery and moves it to the dead-letter table.
+func (repo *WebhookRepository) MarkDead(ctx context.Context, deliveryID int64, statusCode int, lastError string) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var attempts int
+	query := `
+	UPDATE webhook_deliveries
+	SET status = 'dead', attempts = attempts + 1, last_status_code = $2, last_error = $3
+	WHERE delivery_id = $1
+	RETURNING attempts`
+	if err := tx.QueryRowContext(ctx, query, deliveryID, nullStatusCode(statusCode), lastError).Scan(&attempts); err != nil {
+		return fmt.Errorf("failed to mark webhook delivery as dead: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO webhook_dead_letters (delivery_id, attempts, last_error) VALUES ($1, $2, $3)`,
+		deliveryID, attempts, lastError)
+	if err != nil {
+		return fmt.Errorf("failed to insert dead letter: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (repo *WebhookRepository) ListDeadLetters(ctx context.Context) ([]models.WebhookDeadLetter, error) {
+	query := `
+	SELECT dl.dead_letter_id, d.delivery_id, s.subscription_id, s.url, e.event_id, e.event_type,
+	       dl.attempts, COALESCE(dl.last_error, ''), dl.created_at, dl.redelivered_at
+	FROM webhook_dead_letters dl
+	JOIN webhook_deliveries d ON d.delivery_id = dl.delivery_id
+	JOIN webhook_subscriptions s ON s.subscription_id = d.subscription_id
+	JOIN outbox_events e ON e.event_id = d.event_id
+	ORDER BY dl.dead_letter_id DESC`
+	rows, err := repo.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dead letters: %w", err)
+	}
+	defer rows.Close()
+
+	letters := []models.WebhookDeadLetter{}
+	for rows.Next() {
+		var dl models.WebhookDeadLetter
+		if err := rows.Scan(&dl.ID, &dl.DeliveryID, &dl.SubscriptionID, &dl.URL, &dl.EventID, &dl.EventType,
+			&dl.Attempts, &dl.LastError, &dl.CreatedAt, &dl.RedeliveredAt); err != nil {
+			return nil, fmt.Errorf("failed to scan dead letter: %w", err)
+		}
+		letters = append(letters, dl)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over dead letters: %w", err)
+	}
+	return letters, nil
+}
+
+// Redeliver resets a delivery so the dispatcher sends it again with a fresh
+// retry budget.
+func (repo *WebhookRepository) Redeliver(ctx context.Context, deliveryID int64) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+	UPDATE webhook_deliveries
+	SET status = 'pending', attempts = 0, next_attempt_at = CURRENT_TIMESTAMP, delivered_at = NULL
+	WHERE delivery_id = $1`
+	result, err := tx.ExecContext(ctx, query, deliveryID)
+	if err != nil {
+		return fmt.Errorf("failed to reset webhook delivery: %w", err)
+	}
+	numRows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if numRows == 0 {
+		return models.NotFound("webhook delivery not found")
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE webhook_dead_letters SET redelivered_at = CURRENT_TIMESTAMP WHERE delivery_id = $1 AND redelivered_at IS NULL`, deliveryID)
+	if err != nil {
+		return fmt.Errorf("failed to update dead letter: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func nullStatusCode(statusCode int) interface{} {
+	if statusCode == 0 {
+		return nil
+	}
+	return statusCode
+}
+
+// enqueueEvent writes an outbox event in the caller's transaction, so the
+// event exists if and only if the change it describes was committed.
+func enqueueEvent(ctx context.Context, tx *sql.Tx, eventType string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s event: %w", eventType, err)
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO outbox_events (event_type, payload) VALUES ($1, $2::jsonb)`, eventType, string(data)); err != nil {
+		return fmt.Errorf("failed to enqueue %s event: %w", eventType, err)
+	}
+	return nil
+}
+
+// enqueueOrderEvent snapshots the order as it is inside the transaction.
+func enqueueOrderEvent(ctx context.Context, tx *sql.Tx, eventType string, orderID int) error {
+	query := `
+	INSERT INTO outbox_events (event_type, payload)
+	SELECT $1, jsonb_build_object(
+		'order_id', o.order_id,
+		'customer_name', o.customer_name,
+		'customer_id', o.customer_id,
+		'total_amount', o.total_amount,
+		'status', o.status,
+		'updated_at', o.updated_at::timestamptz,
+		'items', COALESCE((
+			SELECT jsonb_agg(jsonb_build_object('product_id', oi.menu_item_id, 'quantity', oi.quantity,
+			       'price_at_order', oi.price_at_order) ORDER BY oi.order_item_id)
+			FROM order_items oi WHERE oi.order_id = o.order_id), '[]'::jsonb))
+	FROM orders o WHERE o.order_id = $2`
+	if _, err := tx.ExecContext(ctx, query, eventType, orderID); err != nil {
+		return fmt.Errorf("failed to enqueue %s event: %w", eventType, err)
+	}
+	return nil
+}
+
+// enqueueStockout records an inventory.stockout event when an ingredient
+// runs out.
+func enqueueStockout(ctx context.Context, tx *sql.Tx, ingredientID int, name string, before, after float64) error {
+	if before <= 0 || after > 0 {
+		return nil
+	}
+	return enqueueEvent(ctx, tx, "inventory.stockout", map[string]interface{}{
+		"ingredient_id": ingredientID,
+		"name":          name,
+		"quantity":      after,
+	})
+}
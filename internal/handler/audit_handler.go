@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"encoding/json"
+	"frappuccino/internal/check"
+	"frappuccino/internal/service"
+	"frappuccino/internal/utils"
+	"frappuccino/models"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type AuditHandler struct {
+	auditService *service.AuditService
+}
+
+func NewAuditHandler(auditService *service.AuditService) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+func (h *AuditHandler) ListAudit(w http.ResponseWriter, r *http.Request) {
+	filter, ok := parseAuditFilter(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := h.auditService.List(r.Context(), filter)
+	if err != nil {
+		sendError(w, r, "Failed to list the audit log!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+	utils.Logger(r.Context()).Info("Audit log displayed", slog.Int("count", len(page.Entries)))
+}
+
+// parseAuditFilter reads entity, entityId, action, employeeId, requestId,
+// startDate, endDate, cursor and limit from the query string.
+func parseAuditFilter(w http.ResponseWriter, r *http.Request) (models.AuditFilter, bool) {
+	queryParams := r.URL.Query()
+	var filter models.AuditFilter
+
+	startDate, endDate, err := check.Check_Date(queryParams.Get("startDate"), queryParams.Get("endDate"))
+	if err != nil {
+		utils.HandleError(w, r, err, "Invalid dates!")
+		return filter, false
+	}
+	filter.StartDate, filter.EndDate = startDate, endDate
+
+	if entity := queryParams.Get("entity"); entity != "" {
+		if err := check.Check_AuditEntity(entity); err != nil {
+			utils.HandleError(w, r, err, "Invalid query!")
+			return filter, false
+		}
+		filter.Entity = &entity
+	}
+
+	if action := queryParams.Get("action"); action != "" {
+		if err := check.Check_AuditAction(action); err != nil {
+			utils.HandleError(w, r, err, "Invalid query!")
+			return filter, false
+		}
+		filter.Action = &action
+	}
+
+	if requestID := queryParams.Get("requestId"); requestID != "" {
+		filter.RequestID = &requestID
+	}
+
+	if entityID := queryParams.Get("entityId"); entityID != "" {
+		value, err := strconv.Atoi(entityID)
+		if err != nil || value <= 0 {
+			utils.SendProblem(w, r, http.StatusBadRequest, "Invalid entityId! It should be a positive number.")
+			return filter, false
+		}
+		filter.EntityID = &value
+	}
+
+	if employeeID := queryParams.Get("employeeId"); employeeID != "" {
+		value, err := strconv.Atoi(employeeID)
+		if err != nil || value <= 0 {
+			utils.SendProblem(w, r, http.StatusBadRequest, "Invalid employeeId! It should be a positive number.")
+			return filter, false
+		}
+		filter.EmployeeID = &value
+	}
+
+	if cursor := queryParams.Get("cursor"); cursor != "" {
+		value, err := strconv.Atoi(cursor)
+		if err != nil || value < 0 {
+			utils.SendProblem(w, r, http.StatusBadRequest, "Invalid cursor! Cursor should be an audit id.")
+			return filter, false
+		}
+		filter.Cursor = value
+	}
+
+	if limit := queryParams.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value < 1 {
+			utils.SendProblem(w, r, http.StatusBadRequest, "Invalid limit! Limit should be more than 0.")
+			return filter, false
+		}
+		filter.Limit = value
+	}
+
+	return filter, true
+}
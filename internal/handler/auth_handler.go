@@ -0,0 +1,197 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"frappuccino/internal/auth"
+	"frappuccino/internal/check"
+	"frappuccino/internal/router"
+	"frappuccino/internal/service"
+	"frappuccino/internal/utils"
+	"frappuccino/models"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+type AuthHandler struct {
+	authService *service.AuthService
+}
+
+func NewAuthHandler(authService *service.AuthService) *AuthHandler {
+	return &AuthHandler{
+		authService: authService,
+	}
+}
+
+// Middleware authenticates a request with the bearer token in the
+// Authorization header, either a session token from login or an API token,
+// and puts the caller into the request context.
+func (h *AuthHandler) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || strings.TrimSpace(token) == "" {
+			h.unauthorized(w, r, "Authentication required!")
+			return
+		}
+
+		principal, err := h.authService.Authenticate(r.Context(), strings.TrimSpace(token))
+		if err != nil {
+			if errors.Is(err, service.ErrUnauthenticated) {
+				h.unauthorized(w, r, "Invalid or expired token!")
+				return
+			}
+			sendError(w, r, "Failed to authenticate!", err)
+			return
+		}
+
+		r = r.WithContext(auth.WithPrincipal(r.Context(), principal))
+		actor, err := utils.Actor(r)
+		if err != nil {
+			utils.SendProblem(w, r, http.StatusBadRequest, "Invalid X-Employee-ID header")
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(utils.WithActor(r.Context(), actor)))
+	})
+}
+
+func (h *AuthHandler) unauthorized(w http.ResponseWriter, r *http.Request, message string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="frappuccino"`)
+	utils.SendProblem(w, r, http.StatusUnauthorized, message)
+}
+
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var request models.LoginRequest
+	if err := utils.DecodeJSON(r, &request); err != nil {
+		sendError(w, r, "Failed to decode login request!", err)
+		return
+	}
+
+	if err := check.Check_Login(request); err != nil {
+		sendError(w, r, "Invalid login request!", err)
+		return
+	}
+
+	response, err := h.authService.Login(r.Context(), request)
+	if err != nil {
+		if errors.Is(err, service.ErrUnauthenticated) {
+			utils.SendProblem(w, r, http.StatusUnauthorized, "Invalid username or password!")
+			utils.Logger(r.Context()).Warn("Failed login", slog.String("username", request.Username))
+			return
+		}
+		sendError(w, r, "Failed to log in!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+	utils.Logger(r.Context()).Info("Employee logged in", slog.Int("EmployeeID", response.Employee.ID))
+}
+
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.PrincipalFrom(r.Context())
+	if err := h.authService.Logout(r.Context(), principal); err != nil {
+		sendError(w, r, "Failed to log out!", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	utils.Logger(r.Context()).Info("Session revoked", slog.Int("SessionID", principal.ID))
+}
+
+func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.PrincipalFrom(r.Context())
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(principal)
+}
+
+func (h *AuthHandler) SetCredentials(w http.ResponseWriter, r *http.Request) {
+	id, err := router.IntParam(r, "id")
+	if err != nil {
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid employee ID")
+		return
+	}
+
+	var credentials models.Credentials
+	if err := utils.DecodeJSON(r, &credentials); err != nil {
+		sendError(w, r, "Failed to decode credentials!", err)
+		return
+	}
+
+	if err := check.Check_Credentials(credentials); err != nil {
+		sendError(w, r, "Invalid credentials!", err)
+		return
+	}
+	// Logging in as an employee gives their role, so their credentials are
+	// set only by callers who may manage it
+	role, err := h.authService.EmployeeRole(r.Context(), id)
+	if err != nil {
+		sendError(w, r, "Failed to get employee!", err)
+		return
+	}
+	if !canManageRole(w, r, role) {
+		return
+	}
+
+	if err := h.authService.SetCredentials(r.Context(), id, credentials); err != nil {
+		sendError(w, r, "Failed to set credentials!", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	utils.Logger(r.Context()).Info("Employee credentials set", slog.Int("EmployeeID", id))
+}
+
+func (h *AuthHandler) CreateAPIToken(w http.ResponseWriter, r *http.Request) {
+	var token models.APIToken
+	if err := utils.DecodeJSON(r, &token); err != nil {
+		sendError(w, r, "Failed to decode API token to struct!", err)
+		return
+	}
+
+	if err := check.Check_APIToken(token); err != nil {
+		sendError(w, r, "Invalid API token!", err)
+		return
+	}
+	if !canManageRole(w, r, token.Role) {
+		return
+	}
+
+	principal, _ := auth.PrincipalFrom(r.Context())
+	if err := h.authService.CreateAPIToken(r.Context(), &token, principal); err != nil {
+		sendError(w, r, "Failed to create API token!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(token)
+	utils.Logger(r.Context()).Info("API token created", slog.Int("TokenID", token.ID))
+}
+
+func (h *AuthHandler) ListAPITokens(w http.ResponseWriter, r *http.Request) {
+	tokens, err := h.authService.ListAPITokens(r.Context())
+	if err != nil {
+		sendError(w, r, "Failed to list API tokens!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tokens)
+}
+
+func (h *AuthHandler) RevokeAPIToken(w http.ResponseWriter, r *http.Request) {
+	id, err := router.IntParam(r, "id")
+	if err != nil {
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid API token ID")
+		return
+	}
+
+	if err := h.authService.RevokeAPIToken(r.Context(), id); err != nil {
+		sendError(w, r, "Failed to revoke API token!", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	utils.Logger(r.Context()).Info("API token revoked", slog.Int("TokenID", id))
+}
@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"encoding/json"
+	"frappuccino/internal/check"
+	"frappuccino/internal/router"
+	"frappuccino/internal/service"
+	"frappuccino/internal/utils"
+	"frappuccino/models"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+type CustomerHandler struct {
+	customerService *service.CustomerService
+	orderService    *service.OrderService
+}
+
+func NewCustomerHandler(customerService *service.CustomerService, orderService *service.OrderService) *CustomerHandler {
+	return &CustomerHandler{
+		customerService: customerService,
+		orderService:    orderService,
+	}
+}
+
+func (h *CustomerHandler) CreateCustomer(w http.ResponseWriter, r *http.Request) {
+	var customer models.Customer
+	if err := utils.DecodeJSON(r, &customer); err != nil {
+		sendError(w, r, "Failed to decode customer to struct!", err)
+		return
+	}
+
+	if err := check.Check_Customer(customer); err != nil {
+		sendError(w, r, "Invalid customer!", err)
+		return
+	}
+
+	if err := h.customerService.Create(r.Context(), &customer); err != nil {
+		sendError(w, r, "Failed to create customer!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(customer)
+	utils.Logger(r.Context()).Info("Customer created", slog.Int("CustomerID", customer.ID))
+}
+
+func (h *CustomerHandler) ListCustomers(w http.ResponseWriter, r *http.Request) {
+	customers, err := h.customerService.List(r.Context(), strings.TrimSpace(r.URL.Query().Get("search")))
+	if err != nil {
+		sendError(w, r, "Failed to list customers!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(customers)
+	utils.Logger(r.Context()).Info("List of customers displayed")
+}
+
+func (h *CustomerHandler) GetCustomer(w http.ResponseWriter, r *http.Request) {
+	id, err := router.IntParam(r, "id")
+	if err != nil {
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid customer ID")
+		return
+	}
+
+	customer, err := h.customerService.GetByID(r.Context(), id)
+	if err != nil {
+		sendError(w, r, "Failed to get customer!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(customer)
+	utils.Logger(r.Context()).Info("Got customer by its id", slog.Int("CustomerID", id))
+}
+
+func (h *CustomerHandler) UpdateCustomer(w http.ResponseWriter, r *http.Request) {
+	id, err := router.IntParam(r, "id")
+	if err != nil {
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid customer ID")
+		return
+	}
+
+	var customer models.Customer
+	if err := utils.DecodeJSON(r, &customer); err != nil {
+		sendError(w, r, "Failed to decode customer to struct!", err)
+		return
+	}
+
+	if err := check.Check_Customer(customer); err != nil {
+		sendError(w, r, "Invalid customer!", err)
+		return
+	}
+
+	if err := h.customerService.Update(r.Context(), customer, id); err != nil {
+		sendError(w, r, "Failed to update customer!", err)
+		return
+	}
+
+	updated, err := h.customerService.GetByID(r.Context(), id)
+	if err != nil {
+		sendError(w, r, "Failed to get customer!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+	utils.Logger(r.Context()).Info("Customer updated", slog.Int("CustomerID", id))
+}
+
+func (h *CustomerHandler) DeleteCustomer(w http.ResponseWriter, r *http.Request) {
+	id, err := router.IntParam(r, "id")
+	if err != nil {
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid customer ID")
+		return
+	}
+
+	if err := h.customerService.Delete(r.Context(), id); err != nil {
+		sendError(w, r, "Failed to delete customer!", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	utils.Logger(r.Context()).Info("Customer deleted", slog.Int("CustomerID", id))
+}
+
+// ListCustomerOrders accepts the same query parameters as GET /orders.
+func (h *CustomerHandler) ListCustomerOrders(w http.ResponseWriter, r *http.Request) {
+	id, err := router.IntParam(r, "id")
+	if err != nil {
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid customer ID")
+		return
+	}
+
+	filter, ok := parseOrderFilter(w, r)
+	if !ok {
+		return
+	}
+	filter.CustomerID = &id
+
+	if _, err := h.customerService.GetByID(r.Context(), id); err != nil {
+		sendError(w, r, "Failed to get customer!", err)
+		return
+	}
+
+	page, err := h.orderService.List(r.Context(), filter)
+	if err != nil {
+		sendError(w, r, "Failed to list customer orders!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+	utils.Logger(r.Context()).Info("List of customer orders displayed", slog.Int("CustomerID", id), slog.Int("count", len(page.Orders)))
+}
+
+func (h *CustomerHandler) GetCustomerStats(w http.ResponseWriter, r *http.Request) {
+	id, err := router.IntParam(r, "id")
+	if err != nil {
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid customer ID")
+		return
+	}
+
+	stats, err := h.customerService.GetStats(r.Context(), id)
+	if err != nil {
+		sendError(w, r, "Failed to get customer stats!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+	utils.Logger(r.Context()).Info("Customer stats displayed", slog.Int("CustomerID", id))
+}
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"encoding/json"
+	"frappuccino/internal/check"
+	"frappuccino/internal/router"
+	"frappuccino/internal/service"
+	"frappuccino/internal/utils"
+	"frappuccino/models"
+	"log/slog"
+	"net/http"
+)
+
+type DrawerHandler struct {
+	drawerService *service.DrawerService
+}
+
+func NewDrawerHandler(drawerService *service.DrawerService) *DrawerHandler {
+	return &DrawerHandler{
+		drawerService: drawerService,
+	}
+}
+
+func (h *DrawerHandler) OpenSession(w http.ResponseWriter, r *http.Request) {
+	var session models.DrawerSession
+	if err := utils.DecodeJSON(r, &session); err != nil {
+		sendError(w, r, "Failed to decode drawer session to struct!", err)
+		return
+	}
+
+	if err := check.Check_DrawerOpen(session); err != nil {
+		sendError(w, r, "Invalid drawer session!", err)
+		return
+	}
+
+	if err := h.drawerService.Open(r.Context(), &session); err != nil {
+		sendError(w, r, "Failed to open drawer session!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(session)
+	utils.Logger(r.Context()).Info("Drawer session opened", slog.Int("SessionID", session.ID))
+}
+
+func (h *DrawerHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	sessions, err := h.drawerService.List(r.Context())
+	if err != nil {
+		sendError(w, r, "Failed to list drawer sessions!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sessions)
+	utils.Logger(r.Context()).Info("List of drawer sessions displayed")
+}
+
+func (h *DrawerHandler) GetSession(w http.ResponseWriter, r *http.Request) {
+	id, err := router.IntParam(r, "id")
+	if err != nil {
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid drawer session ID")
+		return
+	}
+
+	session, err := h.drawerService.GetByID(r.Context(), id)
+	if err != nil {
+		sendError(w, r, "Failed to get drawer session!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(session)
+	utils.Logger(r.Context()).Info("Got drawer session by its id", slog.Int("SessionID", id))
+}
+
+func (h *DrawerHandler) AddMovement(w http.ResponseWriter, r *http.Request) {
+	id, err := router.IntParam(r, "id")
+	if err != nil {
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid drawer session ID")
+		return
+	}
+
+	var movement models.DrawerMovement
+	if err := utils.DecodeJSON(r, &movement); err != nil {
+		sendError(w, r, "Failed to decode drawer movement to struct!", err)
+		return
+	}
+
+	if err := check.Check_DrawerMovement(movement); err != nil {
+		sendError(w, r, "Invalid drawer movement!", err)
+		return
+	}
+
+	if err := h.drawerService.AddMovement(r.Context(), id, &movement); err != nil {
+		sendError(w, r, "Failed to add drawer movement!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(movement)
+	utils.Logger(r.Context()).Info("Drawer movement added", slog.Int("SessionID", id), slog.Int("MovementID", movement.ID))
+}
+
+func (h *DrawerHandler) CloseSession(w http.ResponseWriter, r *http.Request) {
+	id, err := router.IntParam(r, "id")
+	if err != nil {
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid drawer session ID")
+		return
+	}
+
+	var request models.DrawerCloseRequest
+	if err := utils.DecodeJSON(r, &request); err != nil {
+		sendError(w, r, "Failed to decode drawer close request!", err)
+		return
+	}
+
+	if err := check.Check_DrawerClose(request); err != nil {
+		sendError(w, r, "Invalid drawer close request!", err)
+		return
+	}
+
+	report, err := h.drawerService.Close(r.Context(), id, request)
+	if err != nil {
+		sendError(w, r, "Failed to close drawer session!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+	utils.Logger(r.Context()).Info("Drawer session closed", slog.Int("SessionID", id), slog.Float64("Variance", report.CashVariance))
+}
+
+// GetZReport returns the Z-report of a closed session as JSON, or as
+// printable text with format=text.
+func (h *DrawerHandler) GetZReport(w http.ResponseWriter, r *http.Request) {
+	id, err := router.IntParam(r, "id")
+	if err != nil {
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid drawer session ID")
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "text" {
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid format! Allowed values: json, text.")
+		return
+	}
+
+	report, err := h.drawerService.GetZReport(r.Context(), id)
+	if err != nil {
+		sendError(w, r, "Failed to get Z-report!", err)
+		return
+	}
+
+	if format == "text" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte(service.FormatZReport(report)))
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(report)
+	}
+	utils.Logger(r.Context()).Info("Z-report displayed", slog.Int("SessionID", id))
+}
@@ -0,0 +1,344 @@
+package handler
+
+import (
+	"encoding/json"
+	"frappuccino/internal/auth"
+	"frappuccino/internal/check"
+	"frappuccino/internal/router"
+	"frappuccino/internal/service"
+	"frappuccino/internal/utils"
+	"frappuccino/models"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type EmployeeHandler struct {
+	employeeService *service.EmployeeService
+}
+
+func NewEmployeeHandler(employeeService *service.EmployeeService) *EmployeeHandler {
+	return &EmployeeHandler{
+		employeeService: employeeService,
+	}
+}
+
+func (h *EmployeeHandler) CreateEmployee(w http.ResponseWriter, r *http.Request) {
+	var employee models.Employee
+	if err := utils.DecodeJSON(r, &employee); err != nil {
+		sendError(w, r, "Failed to decode employee to struct!", err)
+		return
+	}
+
+	if err := check.Check_Employee(employee); err != nil {
+		sendError(w, r, "Invalid employee!", err)
+		return
+	}
+	if !canManageRole(w, r, employee.Role) {
+		return
+	}
+
+	if err := h.employeeService.Create(r.Context(), &employee); err != nil {
+		sendError(w, r, "Failed to create employee!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(employee)
+	utils.Logger(r.Context()).Info("Employee created", slog.Int("EmployeeID", employee.ID))
+}
+
+func (h *EmployeeHandler) ListEmployees(w http.ResponseWriter, r *http.Request) {
+	includeInactive := r.URL.Query().Get("includeInactive") == "true"
+	employees, err := h.employeeService.List(r.Context(), includeInactive)
+	if err != nil {
+		sendError(w, r, "Failed to list employees!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(employees)
+	utils.Logger(r.Context()).Info("List of employees displayed")
+}
+
+func (h *EmployeeHandler) GetEmployee(w http.ResponseWriter, r *http.Request) {
+	id, err := router.IntParam(r, "id")
+	if err != nil {
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid employee ID")
+		return
+	}
+
+	employee, err := h.employeeService.GetByID(r.Context(), id)
+	if err != nil {
+		sendError(w, r, "Failed to get employee!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(employee)
+	utils.Logger(r.Context()).Info("Got employee by its id", slog.Int("EmployeeID", id))
+}
+
+func (h *EmployeeHandler) UpdateEmployee(w http.ResponseWriter, r *http.Request) {
+	id, err := router.IntParam(r, "id")
+	if err != nil {
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid employee ID")
+		return
+	}
+
+	var employee models.Employee
+	if err := utils.DecodeJSON(r, &employee); err != nil {
+		sendError(w, r, "Failed to decode employee to struct!", err)
+		return
+	}
+
+	if err := check.Check_Employee(employee); err != nil {
+		sendError(w, r, "Invalid employee!", err)
+		return
+	}
+	if !canManageRole(w, r, employee.Role) || !h.canManageEmployee(w, r, id) {
+		return
+	}
+
+	if err := h.employeeService.Update(r.Context(), employee, id); err != nil {
+		sendError(w, r, "Failed to update employee!", err)
+		return
+	}
+
+	updated, err := h.employeeService.GetByID(r.Context(), id)
+	if err != nil {
+		sendError(w, r, "Failed to get employee!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+	utils.Logger(r.Context()).Info("Employee updated", slog.Int("EmployeeID", id))
+}
+
+func (h *EmployeeHandler) DeleteEmployee(w http.ResponseWriter, r *http.Request) {
+	id, err := router.IntParam(r, "id")
+	if err != nil {
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid employee ID")
+		return
+	}
+
+	if !h.canManageEmployee(w, r, id) {
+		return
+	}
+
+	if err := h.employeeService.Deactivate(r.Context(), id); err != nil {
+		sendError(w, r, "Failed to deactivate employee!", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	utils.Logger(r.Context()).Info("Employee deactivated", slog.Int("EmployeeID", id))
+}
+
+// canManageEmployee checks that the caller may change the employee id as
+// they are now, so an owner's record is changed only by an owner.
+func (h *EmployeeHandler) canManageEmployee(w http.ResponseWriter, r *http.Request, id int) bool {
+	current, err := h.employeeService.GetByID(r.Context(), id)
+	if err != nil {
+		sendError(w, r, "Failed to get employee!", err)
+		return false
+	}
+	return canManageRole(w, r, current.Role)
+}
+
+// canManageRole answers 403 unless the caller may give role to an employee
+// or change one who has it.
+func canManageRole(w http.ResponseWriter, r *http.Request, role string) bool {
+	principal, _ := auth.PrincipalFrom(r.Context())
+	if auth.CanManageRole(principal.Role, role) {
+		return true
+	}
+	utils.Logger(r.Context()).Warn("Employee role change denied", slog.String("kind", principal.Kind), slog.Int("id", principal.ID),
+		slog.String("role", principal.Role), slog.String("employee_role", role), slog.String("path", r.URL.Path))
+	problem := utils.NewProblem(r, http.StatusForbidden, "Your role can't manage employees with the "+role+" role!")
+	problem.Role = principal.Role
+	utils.WriteProblem(w, problem)
+	return false
+}
+
+func (h *EmployeeHandler) ClockIn(w http.ResponseWriter, r *http.Request) {
+	id, err := router.IntParam(r, "id")
+	if err != nil {
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid employee ID")
+		return
+	}
+
+	entry, err := h.employeeService.ClockIn(r.Context(), id)
+	if err != nil {
+		sendError(w, r, "Failed to clock in!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(entry)
+	utils.Logger(r.Context()).Info("Employee clocked in", slog.Int("EmployeeID", id), slog.Int("EntryID", entry.ID))
+}
+
+func (h *EmployeeHandler) ClockOut(w http.ResponseWriter, r *http.Request) {
+	id, err := router.IntParam(r, "id")
+	if err != nil {
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid employee ID")
+		return
+	}
+
+	entry, err := h.employeeService.ClockOut(r.Context(), id)
+	if err != nil {
+		sendError(w, r, "Failed to clock out!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entry)
+	utils.Logger(r.Context()).Info("Employee clocked out", slog.Int("EmployeeID", id), slog.Int("EntryID", entry.ID))
+}
+
+func (h *EmployeeHandler) ListTimeEntries(w http.ResponseWriter, r *http.Request) {
+	id, err := router.IntParam(r, "id")
+	if err != nil {
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid employee ID")
+		return
+	}
+
+	startDate, endDate, err := check.Check_Date(r.URL.Query().Get("startDate"), r.URL.Query().Get("endDate"))
+	if err != nil {
+		sendError(w, r, "Invalid dates!", err)
+		return
+	}
+
+	entries, err := h.employeeService.ListTimeEntries(r.Context(), id, startDate, endDate)
+	if err != nil {
+		sendError(w, r, "Failed to list time entries!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+	utils.Logger(r.Context()).Info("List of time entries displayed", slog.Int("EmployeeID", id))
+}
+
+func (h *EmployeeHandler) CreateShift(w http.ResponseWriter, r *http.Request) {
+	var shift models.Shift
+	if err := utils.DecodeJSON(r, &shift); err != nil {
+		sendError(w, r, "Failed to decode shift to struct!", err)
+		return
+	}
+
+	if err := check.Check_Shift(shift); err != nil {
+		sendError(w, r, "Invalid shift!", err)
+		return
+	}
+
+	if err := h.employeeService.CreateShift(r.Context(), &shift); err != nil {
+		sendError(w, r, "Failed to create shift!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(shift)
+	utils.Logger(r.Context()).Info("Shift created", slog.Int("ShiftID", shift.ID))
+}
+
+func (h *EmployeeHandler) ListShifts(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	startDate, endDate, err := check.Check_Date(query.Get("startDate"), query.Get("endDate"))
+	if err != nil {
+		sendError(w, r, "Invalid dates!", err)
+		return
+	}
+	filter := models.ShiftFilter{StartDate: startDate, EndDate: endDate}
+	if value := query.Get("employeeId"); value != "" {
+		employeeID, err := strconv.Atoi(value)
+		if err != nil || employeeID <= 0 {
+			utils.SendProblem(w, r, http.StatusBadRequest, "Invalid 'employeeId'.")
+			return
+		}
+		filter.EmployeeID = &employeeID
+	}
+
+	shifts, err := h.employeeService.ListShifts(r.Context(), filter)
+	if err != nil {
+		sendError(w, r, "Failed to list shifts!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(shifts)
+	utils.Logger(r.Context()).Info("List of shifts displayed")
+}
+
+func (h *EmployeeHandler) GetShift(w http.ResponseWriter, r *http.Request) {
+	id, err := router.IntParam(r, "id")
+	if err != nil {
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid shift ID")
+		return
+	}
+
+	shift, err := h.employeeService.GetShift(r.Context(), id)
+	if err != nil {
+		sendError(w, r, "Failed to get shift!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(shift)
+	utils.Logger(r.Context()).Info("Got shift by its id", slog.Int("ShiftID", id))
+}
+
+func (h *EmployeeHandler) UpdateShift(w http.ResponseWriter, r *http.Request) {
+	id, err := router.IntParam(r, "id")
+	if err != nil {
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid shift ID")
+		return
+	}
+
+	var shift models.Shift
+	if err := utils.DecodeJSON(r, &shift); err != nil {
+		sendError(w, r, "Failed to decode shift to struct!", err)
+		return
+	}
+
+	if err := check.Check_Shift(shift); err != nil {
+		sendError(w, r, "Invalid shift!", err)
+		return
+	}
+
+	if err := h.employeeService.UpdateShift(r.Context(), shift, id); err != nil {
+		sendError(w, r, "Failed to update shift!", err)
+		return
+	}
+
+	updated, err := h.employeeService.GetShift(r.Context(), id)
+	if err != nil {
+		sendError(w, r, "Failed to get shift!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+	utils.Logger(r.Context()).Info("Shift updated", slog.Int("ShiftID", id))
+}
+
+func (h *EmployeeHandler) DeleteShift(w http.ResponseWriter, r *http.Request) {
+	id, err := router.IntParam(r, "id")
+	if err != nil {
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid shift ID")
+		return
+	}
+
+	if err := h.employeeService.DeleteShift(r.Context(), id); err != nil {
+		sendError(w, r, "Failed to delete shift!", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	utils.Logger(r.Context()).Info("Shift deleted", slog.Int("ShiftID", id))
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"frappuccino/internal/auth"
+	"frappuccino/internal/dal"
+	"frappuccino/internal/service"
+	"frappuccino/models"
+)
+
+type fakeEmployeeRepo struct {
+	dal.EmployeeInterface
+	employees map[int]models.Employee
+	changed   bool
+}
+
+func (repo *fakeEmployeeRepo) Create(ctx context.Context, employee *models.Employee) error {
+	repo.changed = true
+	return nil
+}
+
+func (repo *fakeEmployeeRepo) GetByID(ctx context.Context, id int) (models.Employee, error) {
+	employee, ok := repo.employees[id]
+	if !ok {
+		return models.Employee{}, models.NotFound("employee not found")
+	}
+	return employee, nil
+}
+
+func (repo *fakeEmployeeRepo) Update(ctx context.Context, employee models.Employee, id int) error {
+	repo.changed = true
+	return nil
+}
+
+func (repo *fakeEmployeeRepo) Deactivate(ctx context.Context, id int) error {
+	repo.changed = true
+	return nil
+}
+
+func TestEmployeeRoleChanges(t *testing.T) {
+	const (
+		baristaID = 1
+		managerID = 2
+		ownerID   = 3
+	)
+
+	tests := []struct {
+		name       string
+		callerRole string
+		method     string
+		id         int
+		body       string
+		wantStatus int
+	}{
+		{"manager creates a barista", "manager", http.MethodPost, 0, `{"name":"Ann","role":"barista"}`, http.StatusCreated},
+		{"manager creates a manager", "manager", http.MethodPost, 0, `{"name":"Ann","role":"manager"}`, http.StatusCreated},
+		{"manager creates an owner", "manager", http.MethodPost, 0, `{"name":"Ann","role":"owner"}`, http.StatusForbidden},
+		{"shift lead creates a manager", "shift_lead", http.MethodPost, 0, `{"name":"Ann","role":"manager"}`, http.StatusForbidden},
+		{"owner creates an owner", "owner", http.MethodPost, 0, `{"name":"Ann","role":"owner"}`, http.StatusCreated},
+		{"manager promotes a barista to owner", "manager", http.MethodPut, baristaID, `{"name":"Bob","role":"owner"}`, http.StatusForbidden},
+		{"shift lead edits a manager", "shift_lead", http.MethodPut, managerID, `{"name":"Mia","role":"barista"}`, http.StatusForbidden},
+		{"manager demotes the owner", "manager", http.MethodPut, ownerID, `{"name":"Olga","role":"barista"}`, http.StatusForbidden},
+		{"manager deactivates the owner", "manager", http.MethodDelete, ownerID, "", http.StatusForbidden},
+		{"manager deactivates a barista", "manager", http.MethodDelete, baristaID, "", http.StatusNoContent},
+		{"manager edits a barista", "manager", http.MethodPut, baristaID, `{"name":"Bob","role":"shift_lead"}`, http.StatusOK},
+		{"owner demotes a manager", "owner", http.MethodPut, managerID, `{"name":"Mia","role":"barista"}`, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeEmployeeRepo{employees: map[int]models.Employee{
+				baristaID: {ID: baristaID, Name: "Bob", Role: "barista"},
+				managerID: {ID: managerID, Name: "Mia", Role: "manager"},
+				ownerID:   {ID: ownerID, Name: "Olga", Role: auth.OwnerRole},
+			}}
+			h := NewEmployeeHandler(service.NewEmployeeService(repo))
+
+			r := httptest.NewRequest(tt.method, "/employees", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", "application/json")
+			r = r.WithContext(auth.WithPrincipal(r.Context(), models.Principal{Kind: models.PrincipalSession, ID: 9, Role: tt.callerRole}))
+			w := httptest.NewRecorder()
+			switch tt.method {
+			case http.MethodPost:
+				h.CreateEmployee(w, r)
+			case http.MethodPut:
+				r.SetPathValue("id", strconv.Itoa(tt.id))
+				h.UpdateEmployee(w, r)
+			case http.MethodDelete:
+				r.SetPathValue("id", strconv.Itoa(tt.id))
+				h.DeleteEmployee(w, r)
+			}
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if repo.changed != (tt.wantStatus != http.StatusForbidden) {
+				t.Errorf("employee changed = %v, want %v", repo.changed, !repo.changed)
+			}
+		})
+	}
+}
@@ -0,0 +1,13 @@
+package handler
+
+import (
+	"frappuccino/internal/utils"
+	"net/http"
+)
+
+// sendError answers the request with the problem err maps to. Errors that
+// aren't the client's are logged by utils.HandleError with message, which is
+// all the client is told about them.
+func sendError(w http.ResponseWriter, r *http.Request, message string, err error) {
+	utils.HandleError(w, r, err, message)
+}
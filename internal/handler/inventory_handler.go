@@ -3,53 +3,39 @@ package handler
 import (
 	"encoding/json"
 	"frappuccino/internal/check"
+	"frappuccino/internal/router"
 	"frappuccino/internal/service"
 	"frappuccino/internal/utils"
 	"frappuccino/models"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
 )
 
 type InventoryHandler struct {
 	inventoryService *service.InventoryService
-	logger           *slog.Logger
 }
 
-func NewInventoryHandler(inventoryService *service.InventoryService, logFilePath string) (*InventoryHandler, error) {
-	logger, err := utils.SetupLogger(logFilePath)
-	if err != nil {
-		return nil, err
-	}
-
+func NewInventoryHandler(inventoryService *service.InventoryService) *InventoryHandler {
 	return &InventoryHandler{
 		inventoryService: inventoryService,
-		logger:           logger,
-	}, nil
+	}
 }
 
 func (h *InventoryHandler) CreateIngredient(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		utils.SendError(w, utils.StatusMethodNotAllowed, "Method not allowed!")
-		return
-	}
-
 	var ingredient models.InventoryItem
-	if err := json.NewDecoder(r.Body).Decode(&ingredient); err != nil {
-		utils.SendError(w, utils.StatusConflict, "Failed to decode ingredient item to struct!")
-		h.logger.Error("Failed to decode ingredient item to struct!", slog.Any("error", err))
-		slog.Error("Failed to decode ingredient item to struct!", slog.Any("error", err))
+	if err := utils.DecodeJSON(r, &ingredient); err != nil {
+		sendError(w, r, "Failed to decode ingredient item to struct!", err)
 		return
 	}
 
-	if !check.Check_Inventory(w, r, ingredient) {
+	if err := check.Check_Inventory(ingredient); err != nil {
+		sendError(w, r, "Invalid ingredient!", err)
 		return
 	}
-
-	if err := h.inventoryService.Create(&ingredient); err != nil {
-		utils.SendError(w, utils.StatusBadRequest, "Failed to create the ingredient!")
-		slog.Error("Failed to create the ingredient!", slog.Any("error", err))
-		h.logger.Error("Failed to create the ingredient!", slog.Any("error", err))
+	if err := h.inventoryService.Create(r.Context(), &ingredient); err != nil {
+		sendError(w, r, "Failed to create the ingredient!", err)
 		return
 	}
 
@@ -57,138 +43,239 @@ func (h *InventoryHandler) CreateIngredient(w http.ResponseWriter, r *http.Reque
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(ingredient)
 
-	h.logger.Info("Inventory created", slog.Int("IngredientID", ingredient.IngredientID))
-	slog.Info("Inventory created", "IngredientID", ingredient.IngredientID)
+	utils.Logger(r.Context()).Info("Inventory created", slog.Int("IngredientID", ingredient.IngredientID))
 }
 
 func (h *InventoryHandler) ListInventory(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		utils.SendError(w, utils.StatusMethodNotAllowed, "Method not allowed!")
+	filter, ok := parseInventoryFilter(w, r)
+	if !ok {
 		return
 	}
-	ingredients, err := h.inventoryService.List()
+
+	page, err := h.inventoryService.ListPage(r.Context(), filter)
 	if err != nil {
-		utils.SendError(w, utils.StatusInternalServerError, "Failed to list inventory items!")
-		slog.Error("Failed to list inventory items!", slog.Any("error", err))
-		h.logger.Error("Failed to list inventory items!", slog.Any("error", err))
+		sendError(w, r, "Failed to list inventory items!", err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(ingredients)
-	h.logger.Info("List of inventory items displayed")
-	slog.Info("List of inventory items displayed")
+	json.NewEncoder(w).Encode(page)
+	utils.Logger(r.Context()).Info("List of inventory items displayed", slog.Int("page", page.CurrentPage))
 }
 
 func (h *InventoryHandler) GetIngredient(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		utils.SendError(w, utils.StatusMethodNotAllowed, "Method not allowed!")
-		return
-	}
-
-	ingIDStr := r.URL.Path[len("/inventory/"):]
-	ingID, err := strconv.Atoi(ingIDStr)
+	ingID, err := router.IntParam(r, "id")
 	if err != nil {
-		http.Error(w, "Invalid inventory ID", http.StatusBadRequest)
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid inventory ID")
 		return
 	}
-	ingredient, err := h.inventoryService.GetByID(ingID)
+	ingredient, err := h.inventoryService.GetByID(r.Context(), ingID)
 	if err != nil {
-		utils.SendError(w, utils.StatusNotFound, "Ingredient item doesn't exist or unreachable!")
-		slog.Error("Ingredient item doesn't exist or unreachable!", slog.Any("error", err))
-		h.logger.Error("Ingredient item doesn't exist or unreachable!", slog.Any("error", err))
+		sendError(w, r, "Failed to get ingredient item!", err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(ingredient)
-	h.logger.Info("Got inventory by its id", slog.Int("IngredientID", ingredient.IngredientID))
-	slog.Info("Got inventory by its id", "IngredientID", ingredient.IngredientID)
+	utils.Logger(r.Context()).Info("Got inventory by its id", slog.Int("IngredientID", ingredient.IngredientID))
 }
 
 func (h *InventoryHandler) UpdateIngredient(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPut {
-		utils.SendError(w, utils.StatusMethodNotAllowed, "Method not allowed!")
-		return
-	}
-	ingIDStr := r.URL.Path[len("/inventory/"):]
-	ingID, err := strconv.Atoi(ingIDStr)
+	ingID, err := router.IntParam(r, "id")
 	if err != nil {
-		http.Error(w, "Invalid inventory ID", http.StatusBadRequest)
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid inventory ID")
 		return
 	}
 	var ingredient models.InventoryItem
-	if err := json.NewDecoder(r.Body).Decode(&ingredient); err != nil {
-		utils.SendError(w, utils.StatusConflict, "Failed to decode ingredient item to struct!")
-		slog.Error("Failed to decode ingredient item to struct!", slog.Any("error", err))
-		h.logger.Error("Failed to decode ingredient item to struct!", slog.Any("error", err))
+	if err := utils.DecodeJSON(r, &ingredient); err != nil {
+		sendError(w, r, "Failed to decode ingredient item to struct!", err)
+		return
+	}
+	if err := check.Check_Inventory(ingredient); err != nil {
+		sendError(w, r, "Invalid ingredient!", err)
 		return
 	}
-	if err := h.inventoryService.Update(ingredient, ingID); err != nil {
-		utils.SendError(w, utils.StatusInternalServerError, "Failed to update ingredient item!")
-		slog.Error("Failed to update ingredient item!", slog.Any("error", err))
-		h.logger.Error("Failed to update ingredient item!", slog.Any("error", err))
+	if err := h.inventoryService.Update(r.Context(), ingredient, ingID); err != nil {
+		sendError(w, r, "Failed to update ingredient item!", err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusNoContent)
-	h.logger.Info("Inventory updated", slog.Int("IngredientID", ingredient.IngredientID))
-	slog.Info("Inventory updated", "IngredientID", ingredient.IngredientID)
+	utils.Logger(r.Context()).Info("Inventory updated", slog.Int("IngredientID", ingredient.IngredientID))
 }
 
 func (h *InventoryHandler) DeleteIngredient(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodDelete {
-		utils.SendError(w, utils.StatusMethodNotAllowed, "Method not allowed.")
-		return
-	}
-	ingIDStr := r.URL.Path[len("/inventory/"):]
-	ingID, err := strconv.Atoi(ingIDStr)
+	ingID, err := router.IntParam(r, "id")
 	if err != nil {
-		http.Error(w, "Invalid inventory ID", http.StatusBadRequest)
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid inventory ID")
 		return
 	}
-	if err := h.inventoryService.Delete(ingID); err != nil {
-		utils.SendError(w, utils.StatusInternalServerError, "Failed to delete ingredient item!")
-		h.logger.Error("Failed to delete ingredient item!", slog.Any("error", err))
-		slog.Error("Failed to delete ingredient item!", slog.Any("error", err))
+	if err := h.inventoryService.Delete(r.Context(), ingID); err != nil {
+		sendError(w, r, "Failed to delete ingredient item!", err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusNoContent)
-	h.logger.Info("Inventory deleted", slog.Int("IngredientID", ingID))
-	slog.Info("Inventory deleted", "IngredientID", ingID)
+	utils.Logger(r.Context()).Info("Inventory deleted", slog.Int("IngredientID", ingID))
 }
 
 func (h *InventoryHandler) GetLeftOvers(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		utils.SendError(w, utils.StatusMethodNotAllowed, "Method not allowed!")
+	filter, ok := parseInventoryFilter(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := h.inventoryService.GetLeftOvers(r.Context(), filter)
+	if err != nil {
+		sendError(w, r, "Failed to get leftovers!", err)
 		return
 	}
 
-	// Получаем параметры запроса
+	response := map[string]interface{}{
+		"leftovers":   page.Items,
+		"currentPage": page.CurrentPage,
+		"pageSize":    page.PageSize,
+		"totalItems":  page.TotalItems,
+		"hasNextPage": page.HasNextPage,
+		"totalPages":  page.TotalPages,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+	utils.Logger(r.Context()).Info("Leftovers fetched", slog.Int("page", page.CurrentPage), slog.Bool("hasNextPage", page.HasNextPage))
+}
+
+// parseInventoryFilter reads search, unit, belowThreshold, sortBy, order, page
+// and pageSize from the query string.
+func parseInventoryFilter(w http.ResponseWriter, r *http.Request) (models.InventoryFilter, bool) {
 	queryParams := r.URL.Query()
-	sortBy := queryParams.Get("sortBy")
-	page, pageSize := utils.ParsePaginationParams(queryParams)
+	filter := models.InventoryFilter{
+		Search: strings.TrimSpace(queryParams.Get("search")),
+		Unit:   queryParams.Get("unit"),
+		SortBy: queryParams.Get("sortBy"),
+	}
+	filter.Page, filter.PageSize = utils.ParsePaginationParams(queryParams)
+
+	switch filter.SortBy {
+	case "":
+		filter.SortBy = "name"
+	case "price":
+		// price was the old name of the unit cost
+		filter.SortBy = "cost"
+	case "name", "quantity", "cost", "last_updated":
+	default:
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid sortBy! Allowed values: name, quantity, cost, last_updated.")
+		return filter, false
+	}
+
+	switch queryParams.Get("order") {
+	case "", "asc":
+	case "desc":
+		filter.Descending = true
+	default:
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid order! Allowed values: asc, desc.")
+		return filter, false
+	}
+
+	if filter.Unit != "" {
+		if err := check.Check_Unit(filter.Unit); err != nil {
+			utils.HandleError(w, r, err, "Invalid query!")
+			return filter, false
+		}
+	}
+
+	if threshold := queryParams.Get("belowThreshold"); threshold != "" {
+		value, err := strconv.ParseFloat(threshold, 64)
+		if err != nil || value < 0 {
+			utils.SendProblem(w, r, http.StatusBadRequest, "Invalid belowThreshold! It should be a non-negative number.")
+			return filter, false
+		}
+		filter.BelowThreshold = &value
+	}
 
-	// Получаем остатки с учетом сортировки и пагинации
-	leftovers, totalPages, err := h.inventoryService.GetLeftOvers(sortBy, page, pageSize)
+	return filter, true
+}
+
+func (h *InventoryHandler) ListTransactions(w http.ResponseWriter, r *http.Request) {
+	filter, ok := parseTransactionFilter(w, r)
+	if !ok {
+		return
+	}
+	h.listTransactions(w, r, filter)
+}
+
+func (h *InventoryHandler) ListIngredientTransactions(w http.ResponseWriter, r *http.Request) {
+	ingID, err := router.IntParam(r, "id")
 	if err != nil {
-		utils.SendError(w, utils.StatusInternalServerError, "Failed to get leftovers!")
-		h.logger.Error("Failed to get leftovers!", slog.Any("error", err))
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid inventory ID")
 		return
 	}
 
-	// Определяем, есть ли следующая страница
-	hasNextPage := page < totalPages
+	filter, ok := parseTransactionFilter(w, r)
+	if !ok {
+		return
+	}
+	filter.IngredientID = &ingID
+	h.listTransactions(w, r, filter)
+}
 
-	// Формируем ответ
-	response := map[string]interface{}{
-		"leftovers":   leftovers,
-		"currentPage": page,
-		"hasNextPage": hasNextPage,
-		"totalPages":  totalPages,
+func (h *InventoryHandler) listTransactions(w http.ResponseWriter, r *http.Request, filter models.TransactionFilter) {
+	page, err := h.inventoryService.ListTransactions(r.Context(), filter)
+	if err != nil {
+		sendError(w, r, "Failed to list inventory transactions!", err)
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
-	h.logger.Info("Leftovers fetched", slog.Int("page", page), slog.Bool("hasNextPage", hasNextPage))
+	json.NewEncoder(w).Encode(page)
+	utils.Logger(r.Context()).Info("Inventory transactions displayed", slog.Int("count", len(page.Transactions)))
+}
+
+// parseTransactionFilter reads startDate, endDate, type, reason, cursor and limit
+// from the query string.
+func parseTransactionFilter(w http.ResponseWriter, r *http.Request) (models.TransactionFilter, bool) {
+	queryParams := r.URL.Query()
+	var filter models.TransactionFilter
+
+	startDate, endDate, err := check.Check_Date(queryParams.Get("startDate"), queryParams.Get("endDate"))
+	if err != nil {
+		utils.HandleError(w, r, err, "Invalid dates!")
+		return filter, false
+	}
+	filter.StartDate, filter.EndDate = startDate, endDate
+
+	if transactionType := queryParams.Get("type"); transactionType != "" {
+		if err := check.Check_TransactionType(transactionType); err != nil {
+			utils.HandleError(w, r, err, "Invalid query!")
+			return filter, false
+		}
+		filter.Type = &transactionType
+	}
+
+	if reason := queryParams.Get("reason"); reason != "" {
+		if err := check.Check_TransactionReason(reason); err != nil {
+			utils.HandleError(w, r, err, "Invalid query!")
+			return filter, false
+		}
+		filter.Reason = &reason
+	}
+
+	if cursor := queryParams.Get("cursor"); cursor != "" {
+		value, err := strconv.Atoi(cursor)
+		if err != nil || value < 0 {
+			utils.SendProblem(w, r, http.StatusBadRequest, "Invalid cursor! Cursor should be a transaction id.")
+			return filter, false
+		}
+		filter.Cursor = value
+	}
+
+	if limit := queryParams.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value < 1 {
+			utils.SendProblem(w, r, http.StatusBadRequest, "Invalid limit! Limit should be more than 0.")
+			return filter, false
+		}
+		filter.Limit = value
+	}
+
+	return filter, true
 }
@@ -3,162 +3,108 @@ package handler
 import (
 	"encoding/json"
 	"frappuccino/internal/check"
+	"frappuccino/internal/router"
 	"frappuccino/internal/service"
 	"frappuccino/internal/utils"
 	"frappuccino/models"
 	"log/slog"
 	"net/http"
-	"strconv"
-	"strings"
 )
 
 type MenuHandler struct {
 	menuService *service.MenuService
-	logger      *slog.Logger
 }
 
-func NewMenuHandler(menuService *service.MenuService, logFilePath string) (*MenuHandler, error) {
-	logger, err := utils.SetupLogger(logFilePath)
-	if err != nil {
-		return nil, err
-	}
-
+func NewMenuHandler(menuService *service.MenuService) *MenuHandler {
 	return &MenuHandler{
 		menuService: menuService,
-		logger:      logger,
-	}, nil
+	}
 }
 
 func (h *MenuHandler) CreateMenuItem(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		utils.SendError(w, utils.StatusMethodNotAllowed, "Method not allowed.")
-		return
-	}
 	var item models.MenuItem
-	if err := json.NewDecoder(r.Body).Decode(&item); err != nil {
-		utils.SendError(w, utils.StatusConflict, "Failed to decode menu item to struct!")
-		slog.Error("Failed to decode menu item to struct!", slog.Any("error", err))
-		h.logger.Error("Failed to decode menu item to struct!", slog.Any("error", err))
+	if err := utils.DecodeJSON(r, &item); err != nil {
+		sendError(w, r, "Failed to decode menu item to struct!", err)
 		return
 	}
-	if !check.Check_Menu(w, r, item) {
+	if err := check.Check_Menu(item); err != nil {
+		sendError(w, r, "Invalid menu item!", err)
 		return
 	}
-	if err := h.menuService.CreateMenuItem(&item); err != nil {
-		if strings.Contains(err.Error(), "menu item already exists") {
-			utils.SendError(w, utils.StatusConflict, "Menu item with this name already exists!")
-			return
-		}
-		utils.SendError(w, utils.StatusBadRequest, "Failed to create the menu item!")
-		slog.Error("Failed to create the menu item!", slog.Any("error", err))
-		h.logger.Error("Failed to create the menu item!", slog.Any("error", err))
+	if err := h.menuService.CreateMenuItem(r.Context(), &item); err != nil {
+		sendError(w, r, "Failed to create the menu item!", err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(item)
-	h.logger.Info("Menu item created", slog.Int("MenuItemID", item.ID))
-	slog.Info("Menu item created", "MenuItemID", item.ID)
+	utils.Logger(r.Context()).Info("Menu item created", slog.Int("MenuItemID", item.ID))
 }
 
 func (h *MenuHandler) LissMenu(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		utils.SendError(w, utils.StatusMethodNotAllowed, "Method not allowed!")
-	}
-
-	ingredients, err := h.menuService.List()
+	ingredients, err := h.menuService.List(r.Context())
 	if err != nil {
-		utils.SendError(w, utils.StatusInternalServerError, "Failed list menu items!")
-		h.logger.Error("Failed to list menu items!", slog.Any("error", err))
-		slog.Error("Failed to list menu items!", slog.Any("error", err))
+		sendError(w, r, "Failed list menu items!", err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(ingredients)
-	slog.Info("List of menu items displayed")
-	h.logger.Info("List of menu items displayed")
-	slog.Info("List of menu items displayed")
+	utils.Logger(r.Context()).Info("List of menu items displayed")
 }
 
 func (h *MenuHandler) GetMenuItem(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		utils.SendError(w, utils.StatusMethodNotAllowed, "Method not allowed")
-		return
-	}
-
-	itemIDStr := r.URL.Path[len("/menu/"):]
-	itemID, err := strconv.Atoi(itemIDStr)
+	itemID, err := router.IntParam(r, "id")
 	if err != nil {
-		http.Error(w, "Invalid inventory ID", http.StatusBadRequest)
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid menu item ID")
 		return
 	}
-	item, err := h.menuService.GetByID(itemID)
+	item, err := h.menuService.GetByID(r.Context(), itemID)
 	if err != nil {
-		utils.SendError(w, utils.StatusNotFound, "Menu item doesn't exist or unreachable!")
-		h.logger.Error("Menu item doesn't exist or unreachable!", slog.Any("error", err))
-		slog.Error("Menu item doesn't exist or unreachable!", slog.Any("error", err))
+		sendError(w, r, "Failed to get menu item!", err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(item)
-	h.logger.Info("Got menu item by its id", slog.Int("ID", itemID))
-	slog.Info("Got menu item by its id", "ID", itemID)
+	utils.Logger(r.Context()).Info("Got menu item by its id", slog.Int("ID", itemID))
 }
 
 func (h *MenuHandler) UpdateMenuItem(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPut {
-		utils.SendError(w, utils.StatusMethodNotAllowed, "Method not allowed!")
-		return
-	}
-	itemIDStr := r.URL.Path[len("/menu/"):]
-	itemID, err := strconv.Atoi(itemIDStr)
+	itemID, err := router.IntParam(r, "id")
 	if err != nil {
-		http.Error(w, "Invalid inventory ID", http.StatusBadRequest)
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid menu item ID")
 		return
 	}
 	var item models.MenuItem
-	if err := json.NewDecoder(r.Body).Decode(&item); err != nil {
-		utils.SendError(w, utils.StatusConflict, "Failed decode menu item to struct!")
-		h.logger.Error("Failed decode menu item to struct!", slog.Any("error", err))
-		slog.Error("Failed to decode menu item to struct!", slog.Any("error", err))
+	if err := utils.DecodeJSON(r, &item); err != nil {
+		sendError(w, r, "Failed decode menu item to struct!", err)
 		return
 	}
-	if !check.Check_Menu(w, r, item) {
+	if err := check.Check_Menu(item); err != nil {
+		sendError(w, r, "Invalid menu item!", err)
 		return
 	}
-	if err := h.menuService.Update(item, itemID); err != nil {
-		utils.SendError(w, utils.StatusInternalServerError, "Failed update menu item!")
-		h.logger.Error("Failed to update menu item!", slog.Any("error", err))
-		slog.Error("Failed to update menu item!", slog.Any("error", err))
+	if err := h.menuService.Update(r.Context(), item, itemID); err != nil {
+		sendError(w, r, "Failed update menu item!", err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusNoContent)
-	h.logger.Info("Menu item updated", slog.Int("ID", itemID))
-	slog.Info("Menu item updated", "ID", itemID)
+	utils.Logger(r.Context()).Info("Menu item updated", slog.Int("ID", itemID))
 }
 
 func (h *MenuHandler) DeleteMenuItem(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodDelete {
-		utils.SendError(w, utils.StatusMethodNotAllowed, "Method not allowed.")
-		return
-	}
-	itemIDStr := r.URL.Path[len("/menu/"):]
-	itemID, err := strconv.Atoi(itemIDStr)
+	itemID, err := router.IntParam(r, "id")
 	if err != nil {
-		http.Error(w, "Invalid inventory ID", http.StatusBadRequest)
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid menu item ID")
 		return
 	}
-	if err := h.menuService.Delete(itemID); err != nil {
-		utils.SendError(w, utils.StatusInternalServerError, "Failed delete menu item.")
-		slog.Error("Failed delete menu item.", slog.Any("error", err))
-		h.logger.Error("Failed delete menu item.", slog.Any("error", err))
+	if err := h.menuService.Delete(r.Context(), itemID); err != nil {
+		sendError(w, r, "Failed delete menu item.", err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusNoContent)
-	h.logger.Info("Menu item deleted", slog.Int("ID", itemID))
-	slog.Info("Menu item deleted", "ID", itemID)
+	utils.Logger(r.Context()).Info("Menu item deleted", slog.Int("ID", itemID))
 }
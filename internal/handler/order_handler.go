@@ -2,8 +2,9 @@ package handler
 
 import (
 	"encoding/json"
-	"fmt"
+	"frappuccino/internal/auth"
 	"frappuccino/internal/check"
+	"frappuccino/internal/router"
 	"frappuccino/internal/service"
 	"frappuccino/internal/utils"
 	"frappuccino/models"
@@ -15,227 +16,239 @@ import (
 
 type OrderHandler struct {
 	orderService *service.OrderService
-	logger       *slog.Logger
+	rbacService  *service.RBACService
 }
 
-func NewOrderHandler(orderService *service.OrderService, logFilePath string) (*OrderHandler, error) {
-	logger, err := utils.SetupLogger(logFilePath)
-	if err != nil {
-		return nil, err
-	}
-
+func NewOrderHandler(orderService *service.OrderService, rbacService *service.RBACService) *OrderHandler {
 	return &OrderHandler{
 		orderService: orderService,
-		logger:       logger,
-	}, nil
+		rbacService:  rbacService,
+	}
 }
 
 func (h *OrderHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		utils.SendError(w, utils.StatusMethodNotAllowed, "Method not allowed!")
-
-		return
-	}
 	var order models.Order
-	if err := json.NewDecoder(r.Body).Decode(&order); err != nil {
-		utils.SendError(w, utils.StatusConflict, "Failed to decode order to struct!")
-		slog.Error("Failed to decode order to struct!", slog.Any("error", err))
-		h.logger.Error("Failed to decode order to struct!", slog.Any("error", err))
+	if err := utils.DecodeJSON(r, &order); err != nil {
+		sendError(w, r, "Failed to decode order to struct!", err)
 		return
 	}
 
-	if !check.Check_Orders(w, r, order) {
+	if err := check.Check_Orders(order); err != nil {
+		sendError(w, r, "Invalid order!", err)
 		return
 	}
-
-	if err := h.orderService.CreateOrder(&order); err != nil {
-		h.logger.Error("Failed to create order!", slog.Any("error", err))
-
-		switch {
-		case strings.Contains(err.Error(), "insufficient ingredient"):
-			utils.SendError(w, utils.StatusBadRequest, err.Error())
-		case strings.Contains(err.Error(), "order already exists"):
-			utils.SendError(w, utils.StatusConflict, err.Error())
-		default:
-			utils.SendError(w, utils.StatusInternalServerError, "Failed to create order.")
-		}
+	if !setOrderEmployee(w, r, &order) {
+		return
+	}
+	if err := h.orderService.CreateOrder(r.Context(), &order); err != nil {
+		sendError(w, r, "Failed to create order!", err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(order)
-	slog.Info("Order created", "ID", order.ID)
-	h.logger.Info("Order created", slog.Int("ID", order.ID))
+	utils.Logger(r.Context()).Info("Order created", slog.Int("ID", order.ID))
 }
 
 func (h *OrderHandler) ListOrders(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		utils.SendError(w, utils.StatusMethodNotAllowed, "Method not allowed!")
+	filter, ok := parseOrderFilter(w, r)
+	if !ok {
 		return
 	}
 
-	orders, err := h.orderService.List()
+	page, err := h.orderService.List(r.Context(), filter)
 	if err != nil {
-		utils.SendError(w, utils.StatusInternalServerError, "Failed to list orders!")
-		slog.Error("Failed to list orders!", slog.Any("error", err))
-		h.logger.Error("Failed to list orders!", slog.Any("error", err))
+		sendError(w, r, "Failed to list orders!", err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(orders)
-	slog.Info("List of orders displayed")
-	h.logger.Info("List of orders displayed")
+	json.NewEncoder(w).Encode(page)
+	utils.Logger(r.Context()).Info("List of orders displayed", slog.Int("count", len(page.Orders)))
 }
 
-func (h *OrderHandler) GetOrder(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		utils.SendError(w, utils.StatusMethodNotAllowed, "Method not allowed!")
-		return
+// parseOrderFilter reads status, customer, startDate, endDate, menuItemId,
+// minTotal, maxTotal, sortBy, order, cursor and limit from the query string.
+func parseOrderFilter(w http.ResponseWriter, r *http.Request) (models.OrderFilter, bool) {
+	queryParams := r.URL.Query()
+	filter := models.OrderFilter{
+		Statuses:   []string{},
+		Customer:   strings.TrimSpace(queryParams.Get("customer")),
+		SortBy:     queryParams.Get("sortBy"),
+		Cursor:     queryParams.Get("cursor"),
+		Descending: true,
+	}
+
+	if statuses := queryParams.Get("status"); statuses != "" {
+		for _, status := range strings.Split(statuses, ",") {
+			if err := check.Check_OrderStatus(status); err != nil {
+				utils.HandleError(w, r, err, "Invalid query!")
+				return filter, false
+			}
+			filter.Statuses = append(filter.Statuses, status)
+		}
+	}
+
+	startDate, endDate, err := check.Check_Date(queryParams.Get("startDate"), queryParams.Get("endDate"))
+	if err != nil {
+		utils.HandleError(w, r, err, "Invalid dates!")
+		return filter, false
+	}
+	filter.StartDate, filter.EndDate = startDate, endDate
+
+	if menuItem := queryParams.Get("menuItemId"); menuItem != "" {
+		value, err := strconv.Atoi(menuItem)
+		if err != nil || value < 1 {
+			utils.SendProblem(w, r, http.StatusBadRequest, "Invalid menuItemId!")
+			return filter, false
+		}
+		filter.MenuItemID = &value
+	}
+
+	for param, target := range map[string]**float64{"minTotal": &filter.MinTotal, "maxTotal": &filter.MaxTotal} {
+		if raw := queryParams.Get(param); raw != "" {
+			value, err := strconv.ParseFloat(raw, 64)
+			if err != nil || value < 0 {
+				utils.SendProblem(w, r, http.StatusBadRequest, "Invalid "+param+". Must be a non-negative number")
+				return filter, false
+			}
+			*target = &value
+		}
+	}
+	if filter.MinTotal != nil && filter.MaxTotal != nil && *filter.MinTotal > *filter.MaxTotal {
+		utils.SendProblem(w, r, http.StatusBadRequest, "minTotal cannot be greater than maxTotal")
+		return filter, false
+	}
+
+	switch filter.SortBy {
+	case "", "created_at", "total_amount":
+	default:
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid sortBy! Allowed values: created_at, total_amount.")
+		return filter, false
+	}
+
+	switch queryParams.Get("order") {
+	case "", "desc":
+	case "asc":
+		filter.Descending = false
+	default:
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid order! Allowed values: asc, desc.")
+		return filter, false
+	}
+
+	if limit := queryParams.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value < 1 {
+			utils.SendProblem(w, r, http.StatusBadRequest, "Invalid limit! Limit should be more than 0.")
+			return filter, false
+		}
+		filter.Limit = value
 	}
 
-	orderIDStr := r.URL.Path[len("/orders/"):]
-	orderID, err := strconv.Atoi(orderIDStr)
+	return filter, true
+}
+
+func (h *OrderHandler) GetOrder(w http.ResponseWriter, r *http.Request) {
+	orderID, err := router.IntParam(r, "id")
 	if err != nil {
-		http.Error(w, "Invalid inventory ID", http.StatusBadRequest)
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid order ID")
 		return
 	}
 
-	order, err := h.orderService.GetByID(orderID)
+	order, err := h.orderService.GetByID(r.Context(), orderID)
 	if err != nil {
-		utils.SendError(w, utils.StatusNotFound, "Order doesn't exist or unreachable!")
-		slog.Error("Order doesn't exist or unreachable!", slog.Any("error", err))
-		h.logger.Error("Order doesn't exist or unreachable!", slog.Any("error", err))
+		sendError(w, r, "Failed to get order!", err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(order)
-	slog.Info("Got order by its id", "ID", orderID)
-	h.logger.Info("Got order by its id", slog.Int("ID", order.ID))
+	utils.Logger(r.Context()).Info("Got order by its id", slog.Int("ID", order.ID))
 }
 
 func (h *OrderHandler) UpdateOrder(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPut {
-		utils.SendError(w, utils.StatusMethodNotAllowed, "Method not allowed!")
-		return
-	}
-	orderIDStr := r.URL.Path[len("/orders/"):]
-	orderID, err := strconv.Atoi(orderIDStr)
+	orderID, err := router.IntParam(r, "id")
 	if err != nil {
-		http.Error(w, "Invalid inventory ID", http.StatusBadRequest)
-		return
-	}
-	existingOrder, err := h.orderService.GetByID(orderID)
-	if err != nil {
-		utils.SendError(w, utils.StatusNotFound, "Order doesn't exist")
-		slog.Error("Order doesn't exist", slog.Any("error", err))
-		h.logger.Error("Order doesn't exist", slog.Any("error", err))
-		return
-	}
-	if existingOrder.Status == "completed" {
-		utils.SendError(w, utils.StatusConflict, "Cannot update a completed order!")
-		slog.Warn("Attempted to update a completed order", "ID", orderID)
-		h.logger.Error("Attempted to update a completed order", slog.Any("error", err))
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid order ID")
 		return
 	}
 	var order models.Order
 
-	if err := json.NewDecoder(r.Body).Decode(&order); err != nil {
-		utils.SendError(w, utils.StatusConflict, "Failed to decode order to struct!")
-		slog.Error("Failed to decode order to struct!", slog.Any("error", err))
-		h.logger.Error("Failed to decode order to struct!", slog.Any("error", err))
+	if err := utils.DecodeJSON(r, &order); err != nil {
+		sendError(w, r, "Failed to decode order to struct!", err)
 		return
 	}
-	order.ID = orderID
-	if order.Status == "" {
-		order.Status = existingOrder.Status
+	if err := check.Check_OrderUpdate(order); err != nil {
+		sendError(w, r, "Invalid order!", err)
+		return
 	}
+	order.ID = orderID
 
-	if err := h.orderService.Update(order, orderID); err != nil {
-		utils.SendError(w, utils.StatusInternalServerError, "Failed to update order!")
-		slog.Error("Failed to update order!", slog.Any("error", err))
-		h.logger.Error("Failed to update order!", slog.Any("error", err))
+	if err := h.orderService.Update(r.Context(), order, orderID); err != nil {
+		sendError(w, r, "Failed to update order!", err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusNoContent)
 	json.NewEncoder(w).Encode(order)
-	slog.Info("Order updated", "ID", orderID)
-	h.logger.Info("Order updated", slog.Int("ID", order.ID))
+	utils.Logger(r.Context()).Info("Order updated", slog.Int("ID", order.ID))
 }
 
 func (h *OrderHandler) DeleteOrder(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodDelete {
-		utils.SendError(w, utils.StatusMethodNotAllowed, "Method not allowed!")
-		return
-	}
-
-	orderIDStr := r.URL.Path[len("/orders/"):]
-	orderID, err := strconv.Atoi(orderIDStr)
+	orderID, err := router.IntParam(r, "id")
 	if err != nil {
-		http.Error(w, "Invalid inventory ID", http.StatusBadRequest)
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid order ID")
 		return
 	}
 
-	if err := h.orderService.Delete(orderID); err != nil {
-		utils.SendError(w, utils.StatusInternalServerError, "Failed delete order!")
-		slog.Error("Failed delete order!", slog.Any("error", err))
-		h.logger.Error("Failed delete order!", slog.Any("error", err))
+	if err := h.orderService.Delete(r.Context(), orderID); err != nil {
+		sendError(w, r, "Failed delete order!", err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusNoContent)
-	slog.Info("Order deleted", "ID", orderID)
-	h.logger.Info("Order deleted", slog.Int("ID", orderID))
+	utils.Logger(r.Context()).Info("Order deleted", slog.Int("ID", orderID))
 }
 
 func (h *OrderHandler) CloseOrder(w http.ResponseWriter, r *http.Request) {
-	if !strings.HasPrefix(r.URL.Path, "/orders/") || !strings.HasSuffix(r.URL.Path, "/close") {
-		return
-	}
-	orderIDStr := r.URL.Path[len("/orders/") : len(r.URL.Path)-len("/close")]
-	orderID, err := strconv.Atoi(orderIDStr)
+	orderID, err := router.IntParam(r, "id")
 	if err != nil {
-		http.Error(w, "Invalid inventory ID", http.StatusBadRequest)
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid order ID")
 		return
 	}
-	fmt.Println(orderID)
-
-	order, err := h.orderService.GetByID(orderID)
-	if err != nil {
-		utils.SendError(w, utils.StatusNotFound, "Order doesn't exist or unreachable!")
-		slog.Error("Order doesn't exist or unreachable!", slog.Any("error", err))
-		h.logger.Error("Order doesn't exist or unreachable!", slog.Any("error", err))
-		return
+	override := r.URL.Query().Get("override") == "true"
+	if override {
+		principal, _ := auth.PrincipalFrom(r.Context())
+		if !h.rbacService.Allowed(principal.Role, auth.PermOrdersOverride) {
+			permissionDenied(w, r, principal, auth.PermOrdersOverride)
+			return
+		}
 	}
-
-	order.Status = "completed"
-
-	if err := h.orderService.Update(order, orderID); err != nil {
-		utils.SendError(w, utils.StatusInternalServerError, "Failed to update order!")
-		slog.Error("Failed to update order!", slog.Any("error", err))
-		h.logger.Error("Failed to update order!", slog.Any("error", err))
+	if err := h.orderService.Close(r.Context(), orderID, override); err != nil {
+		sendError(w, r, "Failed to close order!", err)
 		return
 	}
+	if override {
+		utils.Logger(r.Context()).Warn("Order closed with payment override", slog.Int("ID", orderID))
+	}
 	w.Header().Set("Content-Type", "application/json")
-	slog.Info("Order completed", "ID", orderID)
-	h.logger.Info("Order completed", slog.Int("ID", orderID))
+	utils.Logger(r.Context()).Info("Order completed", slog.Int("ID", orderID))
 }
 
 func (h *OrderHandler) GetOrderedItemsCount(w http.ResponseWriter, r *http.Request) {
 	startDate := r.URL.Query().Get("startDate")
 	endDate := r.URL.Query().Get("endDate")
 
-	startDatePtr, endDatePtr, ok := check.Check_Date(w, r, startDate, endDate)
-	if !ok {
+	startDatePtr, endDatePtr, err := check.Check_Date(startDate, endDate)
+	if err != nil {
+		sendError(w, r, "Invalid dates!", err)
 		return
 	}
 
-	counts, err := h.orderService.GetOrderedItemsCount(startDatePtr, endDatePtr)
+	counts, err := h.orderService.GetOrderedItemsCount(r.Context(), startDatePtr, endDatePtr)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("failed to get ordered items count: %v", err), http.StatusInternalServerError)
+		sendError(w, r, "Failed to get ordered items count!", err)
 		return
 	}
 
@@ -248,23 +261,41 @@ func (h *OrderHandler) BatchProcessOrders(w http.ResponseWriter, r *http.Request
 		Orders []models.Order `json:"orders"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if err := utils.DecodeJSON(r, &request); err != nil {
+		sendError(w, r, "Invalid request body", err)
 		return
 	}
 
-	for _, order := range request.Orders {
-		if !check.Check_Orders(w, r, order) {
+	if err := check.Check_BulkOrders(request.Orders); err != nil {
+		sendError(w, r, "Invalid orders!", err)
+		return
+	}
+	for i := range request.Orders {
+		if !setOrderEmployee(w, r, &request.Orders[i]) {
 			return
 		}
 	}
 
-	response, err := h.orderService.ProcessBulkOrders(request.Orders)
+	response, err := h.orderService.ProcessBulkOrders(r.Context(), request.Orders)
 	if err != nil {
-		http.Error(w, "Failed to process orders", http.StatusInternalServerError)
+		sendError(w, r, "Failed to process orders!", err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
+
+// setOrderEmployee attributes the order to the employee in the
+// X-Employee-ID header, which takes precedence over employee_id in the body.
+func setOrderEmployee(w http.ResponseWriter, r *http.Request, order *models.Order) bool {
+	employeeID, err := utils.EmployeeID(r)
+	if err != nil {
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid X-Employee-ID header")
+		return false
+	}
+	if employeeID != nil {
+		order.EmployeeID = employeeID
+	}
+	return true
+}
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"frappuccino/internal/service"
+	"frappuccino/internal/utils"
+	"frappuccino/models"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	streamReplayBatch = 500
+	streamHeartbeat   = 15 * time.Second
+)
+
+type OrderStreamHandler struct {
+	broker *service.OrderEventBroker
+}
+
+func NewOrderStreamHandler(broker *service.OrderEventBroker) *OrderStreamHandler {
+	return &OrderStreamHandler{
+		broker: broker,
+	}
+}
+
+// StreamOrders sends order events as Server-Sent Events. A client that
+// reconnects with Last-Event-ID (or ?lastEventId=) first receives the events
+// it missed.
+func (h *OrderStreamHandler) StreamOrders(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		utils.SendProblem(w, r, http.StatusInternalServerError, "Streaming is not supported!")
+		return
+	}
+
+	var lastID int64
+	resume := r.Header.Get("Last-Event-ID")
+	if resume == "" {
+		resume = r.URL.Query().Get("lastEventId")
+	}
+	if resume != "" {
+		value, err := strconv.ParseInt(resume, 10, 64)
+		if err != nil || value < 0 {
+			utils.SendProblem(w, r, http.StatusBadRequest, "Invalid Last-Event-ID!")
+			return
+		}
+		lastID = value
+	}
+
+	// The server's write timeout would cut the stream off
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	// Subscribe before replaying, so nothing is lost in between
+	events, cancel := h.broker.Subscribe()
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "retry: 3000\n\n")
+	flusher.Flush()
+
+	utils.Logger(r.Context()).Info("Order stream opened", slog.Int64("lastEventId", lastID))
+
+	// Replayed events may come again from the broker. Later events can't be
+	// skipped by id, since an event committed late has a lower id
+	replayed := make(map[int64]bool)
+	if resume != "" {
+		for {
+			missed, err := h.broker.Replay(r.Context(), lastID, streamReplayBatch)
+			if err != nil {
+				utils.Logger(r.Context()).Error("Failed to replay order events!", slog.Any("error", err))
+				return
+			}
+			for _, event := range missed {
+				if err := writeOrderEvent(w, event); err != nil {
+					return
+				}
+				replayed[event.ID] = true
+				lastID = event.ID
+			}
+			flusher.Flush()
+			if len(missed) < streamReplayBatch {
+				break
+			}
+		}
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			utils.Logger(r.Context()).Info("Order stream closed", slog.Int64("lastEventId", lastID))
+			return
+		case event, ok := <-events:
+			if !ok {
+				// Dropped by the broker, the client will reconnect and resume
+				return
+			}
+			if replayed[event.ID] {
+				continue
+			}
+			if err := writeOrderEvent(w, event); err != nil {
+				return
+			}
+			lastID = event.ID
+			flusher.Flush()
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func writeOrderEvent(w http.ResponseWriter, event models.OrderEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
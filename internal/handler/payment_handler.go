@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"encoding/json"
+	"frappuccino/internal/check"
+	"frappuccino/internal/router"
+	"frappuccino/internal/service"
+	"frappuccino/internal/utils"
+	"frappuccino/models"
+	"log/slog"
+	"net/http"
+)
+
+type PaymentHandler struct {
+	paymentService *service.PaymentService
+}
+
+func NewPaymentHandler(paymentService *service.PaymentService) *PaymentHandler {
+	return &PaymentHandler{
+		paymentService: paymentService,
+	}
+}
+
+func (h *PaymentHandler) CreatePayment(w http.ResponseWriter, r *http.Request) {
+	orderID, err := router.IntParam(r, "id")
+	if err != nil {
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid order ID")
+		return
+	}
+
+	var payment models.Payment
+	if err := utils.DecodeJSON(r, &payment); err != nil {
+		sendError(w, r, "Failed to decode payment to struct!", err)
+		return
+	}
+
+	if err := check.Check_Payment(payment); err != nil {
+		sendError(w, r, "Invalid payment!", err)
+		return
+	}
+
+	if err := h.paymentService.CreatePayment(r.Context(), orderID, &payment); err != nil {
+		sendError(w, r, "Failed to create payment!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(payment)
+	utils.Logger(r.Context()).Info("Payment created", slog.Int("OrderID", orderID), slog.Int("PaymentID", payment.ID))
+}
+
+func (h *PaymentHandler) ListPayments(w http.ResponseWriter, r *http.Request) {
+	orderID, err := router.IntParam(r, "id")
+	if err != nil {
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid order ID")
+		return
+	}
+
+	balance, err := h.paymentService.GetBalance(r.Context(), orderID)
+	if err != nil {
+		sendError(w, r, "Failed to get payments!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(balance)
+	utils.Logger(r.Context()).Info("Payments of order displayed", slog.Int("OrderID", orderID))
+}
+
+func (h *PaymentHandler) CreateRefund(w http.ResponseWriter, r *http.Request) {
+	orderID, err := router.IntParam(r, "id")
+	if err != nil {
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid order ID")
+		return
+	}
+
+	var refund models.Refund
+	if err := utils.DecodeJSON(r, &refund); err != nil {
+		sendError(w, r, "Failed to decode refund to struct!", err)
+		return
+	}
+
+	if err := check.Check_Refund(refund); err != nil {
+		sendError(w, r, "Invalid refund!", err)
+		return
+	}
+
+	if err := h.paymentService.CreateRefund(r.Context(), orderID, &refund); err != nil {
+		sendError(w, r, "Failed to create refund!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(refund)
+	utils.Logger(r.Context()).Info("Refund created", slog.Int("OrderID", orderID), slog.Int("RefundID", refund.ID))
+}
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"encoding/json"
+	"frappuccino/internal/check"
+	"frappuccino/internal/router"
+	"frappuccino/internal/service"
+	"frappuccino/internal/utils"
+	"frappuccino/models"
+	"log/slog"
+	"net/http"
+)
+
+type PromotionHandler struct {
+	promotionService *service.PromotionService
+}
+
+func NewPromotionHandler(promotionService *service.PromotionService) *PromotionHandler {
+	return &PromotionHandler{
+		promotionService: promotionService,
+	}
+}
+
+func (h *PromotionHandler) CreatePromotion(w http.ResponseWriter, r *http.Request) {
+	var promotion models.Promotion
+	if err := utils.DecodeJSON(r, &promotion); err != nil {
+		sendError(w, r, "Failed to decode promotion to struct!", err)
+		return
+	}
+
+	if err := check.Check_Promotion(promotion); err != nil {
+		sendError(w, r, "Invalid promotion!", err)
+		return
+	}
+
+	if err := h.promotionService.Create(r.Context(), &promotion); err != nil {
+		sendError(w, r, "Failed to create promotion!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(promotion)
+	utils.Logger(r.Context()).Info("Promotion created", slog.Int("PromotionID", promotion.ID))
+}
+
+func (h *PromotionHandler) ListPromotions(w http.ResponseWriter, r *http.Request) {
+	promotions, err := h.promotionService.List(r.Context())
+	if err != nil {
+		sendError(w, r, "Failed to list promotions!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(promotions)
+	utils.Logger(r.Context()).Info("List of promotions displayed")
+}
+
+func (h *PromotionHandler) GetPromotion(w http.ResponseWriter, r *http.Request) {
+	id, err := router.IntParam(r, "id")
+	if err != nil {
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid promotion ID")
+		return
+	}
+
+	promotion, err := h.promotionService.GetByID(r.Context(), id)
+	if err != nil {
+		sendError(w, r, "Failed to get promotion!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(promotion)
+	utils.Logger(r.Context()).Info("Got promotion by its id", slog.Int("PromotionID", id))
+}
+
+func (h *PromotionHandler) UpdatePromotion(w http.ResponseWriter, r *http.Request) {
+	id, err := router.IntParam(r, "id")
+	if err != nil {
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid promotion ID")
+		return
+	}
+
+	var promotion models.Promotion
+	if err := utils.DecodeJSON(r, &promotion); err != nil {
+		sendError(w, r, "Failed to decode promotion to struct!", err)
+		return
+	}
+
+	if err := check.Check_Promotion(promotion); err != nil {
+		sendError(w, r, "Invalid promotion!", err)
+		return
+	}
+
+	if err := h.promotionService.Update(r.Context(), promotion, id); err != nil {
+		sendError(w, r, "Failed to update promotion!", err)
+		return
+	}
+
+	updated, err := h.promotionService.GetByID(r.Context(), id)
+	if err != nil {
+		sendError(w, r, "Failed to get promotion!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+	utils.Logger(r.Context()).Info("Promotion updated", slog.Int("PromotionID", id))
+}
+
+func (h *PromotionHandler) DeletePromotion(w http.ResponseWriter, r *http.Request) {
+	id, err := router.IntParam(r, "id")
+	if err != nil {
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid promotion ID")
+		return
+	}
+
+	if err := h.promotionService.Deactivate(r.Context(), id); err != nil {
+		sendError(w, r, "Failed to deactivate promotion!", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	utils.Logger(r.Context()).Info("Promotion deactivated", slog.Int("PromotionID", id))
+}
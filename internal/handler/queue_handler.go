@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"encoding/json"
+	"frappuccino/internal/router"
+	"frappuccino/internal/service"
+	"frappuccino/internal/utils"
+	"frappuccino/models"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+type QueueHandler struct {
+	queueService *service.QueueService
+}
+
+func NewQueueHandler(queueService *service.QueueService) *QueueHandler {
+	return &QueueHandler{
+		queueService: queueService,
+	}
+}
+
+func (h *QueueHandler) ListQueue(w http.ResponseWriter, r *http.Request) {
+	queue, err := h.queueService.List(r.Context(), r.URL.Query().Get("station"))
+	if err != nil {
+		sendError(w, r, "Failed to list queue!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(queue)
+	utils.Logger(r.Context()).Info("Queue displayed", slog.Int("orders", len(queue)))
+}
+
+func (h *QueueHandler) ClaimOrder(w http.ResponseWriter, r *http.Request) {
+	orderID, err := router.IntParam(r, "id")
+	if err != nil {
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid order ID")
+		return
+	}
+
+	var claim models.QueueClaim
+	if err := utils.DecodeJSON(r, &claim); err != nil {
+		sendError(w, r, "Failed to decode claim to struct!", err)
+		return
+	}
+	if strings.TrimSpace(claim.HandledBy) == "" {
+		utils.SendProblem(w, r, http.StatusBadRequest, "Empty handled_by in claim!")
+		return
+	}
+
+	if err := h.queueService.Claim(r.Context(), orderID, claim); err != nil {
+		sendError(w, r, "Failed to claim order!", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	utils.Logger(r.Context()).Info("Order claimed", slog.Int("ID", orderID), slog.String("handledBy", claim.HandledBy))
+}
+
+// CompleteItem handles POST /queue/{id}/items/{itemId}/done
+func (h *QueueHandler) CompleteItem(w http.ResponseWriter, r *http.Request) {
+	orderID, err := router.IntParam(r, "id")
+	if err != nil {
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid order ID")
+		return
+	}
+	orderItemID, err := router.IntParam(r, "itemId")
+	if err != nil {
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid order item ID")
+		return
+	}
+
+	if err := h.queueService.CompleteItem(r.Context(), orderID, orderItemID); err != nil {
+		sendError(w, r, "Failed to mark order item as done!", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	utils.Logger(r.Context()).Info("Order item done", slog.Int("ID", orderID), slog.Int("OrderItemID", orderItemID))
+}
+
+func (h *QueueHandler) BumpOrder(w http.ResponseWriter, r *http.Request) {
+	orderID, err := router.IntParam(r, "id")
+	if err != nil {
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid order ID")
+		return
+	}
+
+	if err := h.queueService.Bump(r.Context(), orderID); err != nil {
+		sendError(w, r, "Failed to bump order!", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	utils.Logger(r.Context()).Info("Order ready", slog.Int("ID", orderID))
+}
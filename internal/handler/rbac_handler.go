@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"encoding/json"
+	"frappuccino/internal/auth"
+	"frappuccino/internal/check"
+	"frappuccino/internal/router"
+	"frappuccino/internal/service"
+	"frappuccino/internal/utils"
+	"frappuccino/models"
+	"log/slog"
+	"net/http"
+)
+
+type RBACHandler struct {
+	rbacService *service.RBACService
+}
+
+func NewRBACHandler(rbacService *service.RBACService) *RBACHandler {
+	return &RBACHandler{
+		rbacService: rbacService,
+	}
+}
+
+// Require serves next only to callers whose role has permission.
+func (h *RBACHandler) Require(permission string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := auth.PrincipalFrom(r.Context())
+		if !ok {
+			utils.SendProblem(w, r, http.StatusUnauthorized, "Authentication required!")
+			return
+		}
+		if !h.rbacService.Allowed(principal.Role, permission) {
+			permissionDenied(w, r, principal, permission)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// permissionDenied answers a caller whose role lacks permission.
+func permissionDenied(w http.ResponseWriter, r *http.Request, principal models.Principal, permission string) {
+	utils.Logger(r.Context()).Warn("Permission denied", slog.String("kind", principal.Kind), slog.Int("id", principal.ID),
+		slog.String("role", principal.Role), slog.String("permission", permission), slog.String("path", r.URL.Path))
+	problem := utils.NewProblem(r, http.StatusForbidden, "Permission denied!")
+	problem.Permission, problem.Role = permission, principal.Role
+	utils.WriteProblem(w, problem)
+}
+
+func (h *RBACHandler) ListRoles(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.rbacService.List())
+}
+
+func (h *RBACHandler) ListPermissions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(auth.Permissions)
+}
+
+func (h *RBACHandler) UpdateRolePermissions(w http.ResponseWriter, r *http.Request) {
+	role := router.Param(r, "role")
+	if err := check.Check_Role(role); err != nil {
+		sendError(w, r, "Invalid role!", err)
+		return
+	}
+	if role == auth.OwnerRole {
+		utils.SendProblem(w, r, http.StatusBadRequest, "The owner has every permission and can't be changed!")
+		return
+	}
+
+	var permissions []string
+	if err := utils.DecodeJSON(r, &permissions); err != nil {
+		sendError(w, r, "Failed to decode permissions! Expected a list of permission names.", err)
+		return
+	}
+	for _, permission := range permissions {
+		if !auth.IsPermission(permission) {
+			utils.SendProblem(w, r, http.StatusBadRequest, "Unknown permission: "+permission)
+			return
+		}
+	}
+
+	if err := h.rbacService.Update(r.Context(), role, permissions); err != nil {
+		sendError(w, r, "Failed to update role permissions!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.rbacService.List())
+	utils.Logger(r.Context()).Info("Role permissions updated", slog.String("role", role), slog.Int("permissions", len(permissions)))
+}
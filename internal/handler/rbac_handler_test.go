@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"frappuccino/internal/auth"
+	"frappuccino/internal/service"
+	"frappuccino/internal/utils"
+	"frappuccino/models"
+)
+
+type fakeRoleRepo struct {
+	permissions map[string][]string
+}
+
+func (repo *fakeRoleRepo) ListPermissions(ctx context.Context) (map[string][]string, error) {
+	return repo.permissions, nil
+}
+
+func (repo *fakeRoleRepo) SetPermissions(ctx context.Context, role string, permissions []string) error {
+	repo.permissions[role] = permissions
+	return nil
+}
+
+func (repo *fakeRoleRepo) Listen(ctx context.Context) (<-chan struct{}, error) {
+	return nil, nil
+}
+
+func TestRequire(t *testing.T) {
+	rbacService, err := service.NewRBACService(context.Background(), &fakeRoleRepo{permissions: map[string][]string{
+		"barista": {auth.PermOrdersRead, auth.PermOrdersCreate},
+		"manager": {auth.PermOrdersRead, auth.PermOrdersOverride},
+	}}, slog.Default())
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewRBACHandler(rbacService)
+
+	tests := []struct {
+		name       string
+		principal  *models.Principal
+		permission string
+		wantStatus int
+	}{
+		{"no principal", nil, auth.PermOrdersRead, http.StatusUnauthorized},
+		{"granted", &models.Principal{Kind: "employee", ID: 1, Role: "barista"}, auth.PermOrdersCreate, http.StatusOK},
+		{"not granted", &models.Principal{Kind: "employee", ID: 1, Role: "barista"}, auth.PermOrdersOverride, http.StatusForbidden},
+		{"granted to another role", &models.Principal{Kind: "employee", ID: 2, Role: "manager"}, auth.PermOrdersOverride, http.StatusOK},
+		{"unknown role", &models.Principal{Kind: "token", ID: 3, Role: "intern"}, auth.PermOrdersRead, http.StatusForbidden},
+		{"owner has every permission", &models.Principal{Kind: "employee", ID: 4, Role: auth.OwnerRole}, auth.PermRolesManage, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			next := func(w http.ResponseWriter, r *http.Request) { called = true }
+
+			r := httptest.NewRequest(http.MethodGet, "/orders", nil)
+			if tt.principal != nil {
+				r = r.WithContext(auth.WithPrincipal(r.Context(), *tt.principal))
+			}
+			w := httptest.NewRecorder()
+			h.Require(tt.permission, next)(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if called != (tt.wantStatus == http.StatusOK) {
+				t.Errorf("next called = %v, want %v", called, !called)
+			}
+			if tt.wantStatus != http.StatusForbidden {
+				return
+			}
+			var problem utils.Problem
+			if err := json.NewDecoder(w.Body).Decode(&problem); err != nil {
+				t.Fatalf("decode problem: %v", err)
+			}
+			if problem.Permission != tt.permission || problem.Role != tt.principal.Role {
+				t.Errorf("problem permission %q role %q, want %q %q", problem.Permission, problem.Role, tt.permission, tt.principal.Role)
+			}
+		})
+	}
+}
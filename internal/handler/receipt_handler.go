@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"errors"
+	"frappuccino/internal/receipt"
+	"frappuccino/internal/router"
+	"frappuccino/internal/service"
+	"frappuccino/internal/utils"
+	"log/slog"
+	"net/http"
+)
+
+type ReceiptHandler struct {
+	receiptService *service.ReceiptService
+}
+
+func NewReceiptHandler(receiptService *service.ReceiptService) *ReceiptHandler {
+	return &ReceiptHandler{
+		receiptService: receiptService,
+	}
+}
+
+// GetReceipt renders the receipt of an order (format=text, html or escpos),
+// or the kitchen ticket with copy=kitchen.
+func (h *ReceiptHandler) GetReceipt(w http.ResponseWriter, r *http.Request) {
+	orderID, err := router.IntParam(r, "id")
+	if err != nil {
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid order ID")
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = receipt.FormatText
+	}
+	copyType := r.URL.Query().Get("copy")
+	if copyType != "" && copyType != "customer" && copyType != "kitchen" {
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid copy! Allowed values: customer, kitchen.")
+		return
+	}
+
+	body, contentType, err := h.receiptService.Render(r.Context(), orderID, format, copyType == "kitchen")
+	if err != nil {
+		switch {
+		case errors.Is(err, receipt.ErrUnknownFormat):
+			utils.SendProblem(w, r, http.StatusBadRequest, "Invalid format! Allowed values: text, html, escpos.")
+		default:
+			sendError(w, r, "Failed to render receipt!", err)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Write(body)
+	utils.Logger(r.Context()).Info("Receipt rendered", slog.Int("OrderID", orderID), slog.String("Format", format))
+}
@@ -1,7 +1,6 @@
 package handler
 
 import (
-	"context"
 	"encoding/json"
 	"frappuccino/internal/check"
 	"frappuccino/internal/service"
@@ -11,33 +10,26 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type ReportHandler struct {
 	service *service.ReportService
-	logger  *slog.Logger
 }
 
-func NewReportsHandler(service *service.ReportService, logFilePath string) (*ReportHandler, error) {
-	logger, err := utils.SetupLogger(logFilePath)
-	if err != nil {
-		return nil, err
-	}
-
+func NewReportsHandler(service *service.ReportService) *ReportHandler {
 	return &ReportHandler{
 		service: service,
-		logger:  logger,
-	}, nil
+	}
 }
 
 func (h *ReportHandler) GetTotalSales(w http.ResponseWriter, r *http.Request) {
 	// Создаем контекст для запроса
-	ctx := context.Background()
+	ctx := r.Context()
 
 	total, err := h.service.GetTotalSales(ctx)
 	if err != nil {
-		utils.SendError(w, utils.StatusInternalServerError, "Failed to calculate total sales!")
-		h.logger.Error("Failed to calculate total sales!", slog.Any("error", err))
+		sendError(w, r, "Failed to calculate total sales!", err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -45,12 +37,11 @@ func (h *ReportHandler) GetTotalSales(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *ReportHandler) GetPopularItems(w http.ResponseWriter, r *http.Request) {
-	ctx := context.Background()
+	ctx := r.Context()
 
 	popularity, err := h.service.GetPopularItems(ctx)
 	if err != nil {
-		utils.SendError(w, utils.StatusInternalServerError, "Failed to fetch popular items!")
-		h.logger.Error("Failed to fetch popular items!", slog.Any("error", err))
+		sendError(w, r, "Failed to fetch popular items!", err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -58,7 +49,7 @@ func (h *ReportHandler) GetPopularItems(w http.ResponseWriter, r *http.Request)
 }
 
 func (h *ReportHandler) GetOrderedItemsByPeriod(w http.ResponseWriter, r *http.Request) {
-	ctx := context.Background()
+	ctx := r.Context()
 
 	query := r.URL.Query()
 	period := query.Get("period")
@@ -66,27 +57,26 @@ func (h *ReportHandler) GetOrderedItemsByPeriod(w http.ResponseWriter, r *http.R
 	year := query.Get("year")
 
 	if period != "day" && period != "month" {
-		utils.SendError(w, utils.StatusBadRequest, "Invalid period parameter! Must be 'day' or 'month'.")
-		h.logger.Error("Invalid period parameter", slog.String("period", period))
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid period parameter! Must be 'day' or 'month'.")
+		utils.Logger(r.Context()).Error("Invalid period parameter", slog.String("period", period))
 		return
 	}
 
 	if period == "day" && month == "" {
-		utils.SendError(w, utils.StatusBadRequest, "Month parameter is required when period=day.")
-		h.logger.Error("Missing month parameter for period=day")
+		utils.SendProblem(w, r, http.StatusBadRequest, "Month parameter is required when period=day.")
+		utils.Logger(r.Context()).Error("Missing month parameter for period=day")
 		return
 	}
 
 	if period == "month" && year == "" {
-		utils.SendError(w, utils.StatusBadRequest, "Year parameter is required when period=month.")
-		h.logger.Error("Missing year parameter for period=month")
+		utils.SendProblem(w, r, http.StatusBadRequest, "Year parameter is required when period=month.")
+		utils.Logger(r.Context()).Error("Missing year parameter for period=month")
 		return
 	}
 
 	orders, err := h.service.GetOrderedItemsByPeriod(ctx, period, month, year)
 	if err != nil {
-		utils.SendError(w, utils.StatusInternalServerError, "Failed to fetch ordered items!")
-		h.logger.Error("Failed to fetch ordered items!", slog.Any("error", err))
+		sendError(w, r, "Failed to fetch ordered items!", err)
 		return
 	}
 
@@ -97,7 +87,7 @@ func (h *ReportHandler) GetOrderedItemsByPeriod(w http.ResponseWriter, r *http.R
 func (h *ReportHandler) HandleSearch(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query().Get("q")
 	if query == "" {
-		http.Error(w, "Missing search query", http.StatusBadRequest)
+		utils.SendProblem(w, r, http.StatusBadRequest, "Missing search query")
 		return
 	}
 
@@ -106,7 +96,8 @@ func (h *ReportHandler) HandleSearch(w http.ResponseWriter, r *http.Request) {
 		filters = []string{"all"}
 	}
 
-	if !check.Check_OrderItemheckFilters(w, r, filters) {
+	if err := check.Check_OrderItemheckFilters(filters); err != nil {
+		sendError(w, r, "Invalid search filter!", err)
 		return
 	}
 
@@ -114,7 +105,7 @@ func (h *ReportHandler) HandleSearch(w http.ResponseWriter, r *http.Request) {
 	if minStr := r.URL.Query().Get("minPrice"); minStr != "" {
 		val, err := strconv.ParseFloat(minStr, 64)
 		if err != nil || val < 0 {
-			http.Error(w, "Invalid minPrice. Must be a non-negative number", http.StatusBadRequest)
+			utils.SendProblem(w, r, http.StatusBadRequest, "Invalid minPrice. Must be a non-negative number")
 			return
 		}
 		minPrice = val
@@ -124,24 +115,138 @@ func (h *ReportHandler) HandleSearch(w http.ResponseWriter, r *http.Request) {
 	if maxStr := r.URL.Query().Get("maxPrice"); maxStr != "" {
 		val, err := strconv.ParseFloat(maxStr, 64)
 		if err != nil || val < 0 {
-			http.Error(w, "Invalid maxPrice. Must be a non-negative number", http.StatusBadRequest)
+			utils.SendProblem(w, r, http.StatusBadRequest, "Invalid maxPrice. Must be a non-negative number")
 			return
 		}
 		maxPrice = val
 	}
 
 	if minPrice > maxPrice {
-		http.Error(w, "minPrice cannot be greater than maxPrice", http.StatusBadRequest)
+		utils.SendProblem(w, r, http.StatusBadRequest, "minPrice cannot be greater than maxPrice")
 		return
 	}
 
-	ctx := context.Background()
+	ctx := r.Context()
 	response, err := h.service.Search(ctx, query, filters, minPrice, maxPrice)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		sendError(w, r, "Failed to search!", err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
+
+func (h *ReportHandler) GetInventoryValuation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	query := r.URL.Query()
+	costMethod := query.Get("costMethod")
+	if costMethod == "" {
+		costMethod = "weighted_average"
+	}
+	if costMethod != "weighted_average" && costMethod != "current" {
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid costMethod parameter! Must be 'weighted_average' or 'current'.")
+		utils.Logger(r.Context()).Error("Invalid costMethod parameter", slog.String("costMethod", costMethod))
+		return
+	}
+
+	var date *string
+	if value := query.Get("date"); value != "" {
+		asOf, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			utils.SendProblem(w, r, http.StatusBadRequest, "Invalid 'date' format. Use 'YYYY-MM-DD'.")
+			return
+		}
+		if asOf.After(time.Now()) {
+			utils.SendProblem(w, r, http.StatusBadRequest, "'date' cannot be in the future.")
+			return
+		}
+		date = &value
+	}
+
+	valuation, err := h.service.GetInventoryValuation(ctx, date, costMethod)
+	if err != nil {
+		sendError(w, r, "Failed to calculate inventory valuation!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(valuation)
+}
+
+func (h *ReportHandler) GetIngredientUsage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	startDate, endDate, err := check.Check_Date(r.URL.Query().Get("startDate"), r.URL.Query().Get("endDate"))
+	if err != nil {
+		sendError(w, r, "Invalid dates!", err)
+		return
+	}
+
+	report, err := h.service.GetIngredientUsage(ctx, startDate, endDate)
+	if err != nil {
+		sendError(w, r, "Failed to calculate ingredient usage!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+func (h *ReportHandler) GetSalesSummary(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	startDate, endDate, err := check.Check_Date(r.URL.Query().Get("startDate"), r.URL.Query().Get("endDate"))
+	if err != nil {
+		sendError(w, r, "Invalid dates!", err)
+		return
+	}
+
+	summary, err := h.service.GetSalesSummary(ctx, startDate, endDate)
+	if err != nil {
+		sendError(w, r, "Failed to calculate sales summary!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
+
+func (h *ReportHandler) GetTaxReport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	startDate, endDate, err := check.Check_Date(r.URL.Query().Get("startDate"), r.URL.Query().Get("endDate"))
+	if err != nil {
+		sendError(w, r, "Invalid dates!", err)
+		return
+	}
+
+	report, err := h.service.GetTaxReport(ctx, startDate, endDate)
+	if err != nil {
+		sendError(w, r, "Failed to calculate tax report!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+func (h *ReportHandler) GetLaborReport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	startDate, endDate, err := check.Check_Date(r.URL.Query().Get("startDate"), r.URL.Query().Get("endDate"))
+	if err != nil {
+		sendError(w, r, "Invalid dates!", err)
+		return
+	}
+
+	report, err := h.service.GetLaborReport(ctx, startDate, endDate)
+	if err != nil {
+		sendError(w, r, "Failed to calculate labor report!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"encoding/json"
+	"frappuccino/internal/check"
+	"frappuccino/internal/service"
+	"frappuccino/internal/utils"
+	"net/http"
+)
+
+type SettingsHandler struct {
+	settingsService *service.SettingsService
+}
+
+func NewSettingsHandler(settingsService *service.SettingsService) *SettingsHandler {
+	return &SettingsHandler{
+		settingsService: settingsService,
+	}
+}
+
+func (h *SettingsHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	settings, err := h.settingsService.List(r.Context())
+	if err != nil {
+		sendError(w, r, "Failed to get settings!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(settings)
+	utils.Logger(r.Context()).Info("Settings displayed")
+}
+
+func (h *SettingsHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	var values map[string]string
+	if err := utils.DecodeJSON(r, &values); err != nil {
+		sendError(w, r, "Failed to decode settings!", err)
+		return
+	}
+
+	if err := check.Check_Settings(values); err != nil {
+		sendError(w, r, "Invalid settings!", err)
+		return
+	}
+
+	settings, err := h.settingsService.Update(r.Context(), values)
+	if err != nil {
+		sendError(w, r, "Failed to update settings!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(settings)
+	utils.Logger(r.Context()).Info("Settings updated")
+}
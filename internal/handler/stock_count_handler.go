@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"encoding/json"
+	"frappuccino/internal/check"
+	"frappuccino/internal/router"
+	"frappuccino/internal/service"
+	"frappuccino/internal/utils"
+	"frappuccino/models"
+	"log/slog"
+	"net/http"
+)
+
+type StockCountHandler struct {
+	stockCountService *service.StockCountService
+}
+
+func NewStockCountHandler(stockCountService *service.StockCountService) *StockCountHandler {
+	return &StockCountHandler{
+		stockCountService: stockCountService,
+	}
+}
+
+func (h *StockCountHandler) OpenCount(w http.ResponseWriter, r *http.Request) {
+	var count models.StockCount
+	if r.ContentLength != 0 {
+		if err := utils.DecodeJSON(r, &count); err != nil {
+			sendError(w, r, "Failed to decode stock count to struct!", err)
+			return
+		}
+	}
+
+	if err := h.stockCountService.Open(r.Context(), &count); err != nil {
+		sendError(w, r, "Failed to open stock count!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(count)
+	utils.Logger(r.Context()).Info("Stock count opened", slog.Int("CountID", count.ID))
+}
+
+func (h *StockCountHandler) ListCounts(w http.ResponseWriter, r *http.Request) {
+	counts, err := h.stockCountService.List(r.Context())
+	if err != nil {
+		sendError(w, r, "Failed to list stock counts!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(counts)
+	utils.Logger(r.Context()).Info("List of stock counts displayed")
+}
+
+func (h *StockCountHandler) GetCount(w http.ResponseWriter, r *http.Request) {
+	countID, err := router.IntParam(r, "id")
+	if err != nil {
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid stock count ID")
+		return
+	}
+
+	count, err := h.stockCountService.GetByID(r.Context(), countID)
+	if err != nil {
+		sendError(w, r, "Failed to get stock count!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(count)
+	utils.Logger(r.Context()).Info("Got stock count by its id", slog.Int("CountID", countID))
+}
+
+func (h *StockCountHandler) SubmitLines(w http.ResponseWriter, r *http.Request) {
+	countID, err := router.IntParam(r, "id")
+	if err != nil {
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid stock count ID")
+		return
+	}
+
+	var request struct {
+		Lines []models.StockCountSubmission `json:"lines"`
+	}
+	if err := utils.DecodeJSON(r, &request); err != nil {
+		sendError(w, r, "Failed to decode counted ingredients to struct!", err)
+		return
+	}
+
+	if err := check.Check_StockCountLines(request.Lines); err != nil {
+		sendError(w, r, "Invalid counted ingredients!", err)
+		return
+	}
+
+	if err := h.stockCountService.SubmitLines(r.Context(), countID, request.Lines); err != nil {
+		sendError(w, r, "Failed to submit counted ingredients!", err)
+		return
+	}
+
+	count, err := h.stockCountService.GetByID(r.Context(), countID)
+	if err != nil {
+		sendError(w, r, "Failed to get stock count!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(count)
+	utils.Logger(r.Context()).Info("Counted ingredients submitted", slog.Int("CountID", countID), slog.Int("lines", len(request.Lines)))
+}
+
+func (h *StockCountHandler) FinalizeCount(w http.ResponseWriter, r *http.Request) {
+	countID, err := router.IntParam(r, "id")
+	if err != nil {
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid stock count ID")
+		return
+	}
+
+	employeeID, err := utils.EmployeeID(r)
+	if err != nil {
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid X-Employee-ID header")
+		return
+	}
+
+	count, err := h.stockCountService.Finalize(r.Context(), countID, employeeID)
+	if err != nil {
+		sendError(w, r, "Failed to finalize stock count!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(count)
+	utils.Logger(r.Context()).Info("Stock count finalized", slog.Int("CountID", countID))
+}
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"encoding/json"
+	"frappuccino/internal/check"
+	"frappuccino/internal/router"
+	"frappuccino/internal/service"
+	"frappuccino/internal/utils"
+	"frappuccino/models"
+	"log/slog"
+	"net/http"
+)
+
+type TaxHandler struct {
+	taxService *service.TaxService
+}
+
+func NewTaxHandler(taxService *service.TaxService) *TaxHandler {
+	return &TaxHandler{
+		taxService: taxService,
+	}
+}
+
+func (h *TaxHandler) CreateTaxRate(w http.ResponseWriter, r *http.Request) {
+	var rate models.TaxRate
+	if err := utils.DecodeJSON(r, &rate); err != nil {
+		sendError(w, r, "Failed to decode tax rate to struct!", err)
+		return
+	}
+
+	if err := check.Check_TaxRate(rate); err != nil {
+		sendError(w, r, "Invalid tax rate!", err)
+		return
+	}
+
+	if err := h.taxService.Create(r.Context(), &rate); err != nil {
+		sendError(w, r, "Failed to create tax rate!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(rate)
+	utils.Logger(r.Context()).Info("Tax rate created", slog.Int("TaxRateID", rate.ID))
+}
+
+func (h *TaxHandler) ListTaxRates(w http.ResponseWriter, r *http.Request) {
+	rates, err := h.taxService.List(r.Context())
+	if err != nil {
+		sendError(w, r, "Failed to list tax rates!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rates)
+	utils.Logger(r.Context()).Info("List of tax rates displayed")
+}
+
+func (h *TaxHandler) GetTaxRate(w http.ResponseWriter, r *http.Request) {
+	id, err := router.IntParam(r, "id")
+	if err != nil {
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid tax rate ID")
+		return
+	}
+
+	rate, err := h.taxService.GetByID(r.Context(), id)
+	if err != nil {
+		sendError(w, r, "Failed to get tax rate!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rate)
+	utils.Logger(r.Context()).Info("Got tax rate by its id", slog.Int("TaxRateID", id))
+}
+
+func (h *TaxHandler) UpdateTaxRate(w http.ResponseWriter, r *http.Request) {
+	id, err := router.IntParam(r, "id")
+	if err != nil {
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid tax rate ID")
+		return
+	}
+
+	var rate models.TaxRate
+	if err := utils.DecodeJSON(r, &rate); err != nil {
+		sendError(w, r, "Failed to decode tax rate to struct!", err)
+		return
+	}
+
+	if err := check.Check_TaxRate(rate); err != nil {
+		sendError(w, r, "Invalid tax rate!", err)
+		return
+	}
+
+	if err := h.taxService.Update(r.Context(), rate, id); err != nil {
+		sendError(w, r, "Failed to update tax rate!", err)
+		return
+	}
+
+	updated, err := h.taxService.GetByID(r.Context(), id)
+	if err != nil {
+		sendError(w, r, "Failed to get tax rate!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+	utils.Logger(r.Context()).Info("Tax rate updated", slog.Int("TaxRateID", id))
+}
+
+func (h *TaxHandler) DeleteTaxRate(w http.ResponseWriter, r *http.Request) {
+	id, err := router.IntParam(r, "id")
+	if err != nil {
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid tax rate ID")
+		return
+	}
+
+	if err := h.taxService.Deactivate(r.Context(), id); err != nil {
+		sendError(w, r, "Failed to deactivate tax rate!", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	utils.Logger(r.Context()).Info("Tax rate deactivated", slog.Int("TaxRateID", id))
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"encoding/json"
+	"frappuccino/internal/check"
+	"frappuccino/internal/router"
+	"frappuccino/internal/service"
+	"frappuccino/internal/utils"
+	"frappuccino/models"
+	"log/slog"
+	"net/http"
+)
+
+type WebhookHandler struct {
+	webhookService *service.WebhookService
+}
+
+func NewWebhookHandler(webhookService *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+func (h *WebhookHandler) CreateSubscription(w http.ResponseWriter, r *http.Request) {
+	var sub models.WebhookSubscription
+	if err := utils.DecodeJSON(r, &sub); err != nil {
+		sendError(w, r, "Failed to decode webhook subscription to struct!", err)
+		return
+	}
+
+	if err := check.Check_WebhookSubscription(sub); err != nil {
+		sendError(w, r, "Invalid webhook subscription!", err)
+		return
+	}
+
+	created, err := h.webhookService.CreateSubscription(r.Context(), sub)
+	if err != nil {
+		sendError(w, r, "Failed to create webhook subscription!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+	utils.Logger(r.Context()).Info("Webhook subscription created", slog.Int("SubscriptionID", created.ID))
+}
+
+func (h *WebhookHandler) ListSubscriptions(w http.ResponseWriter, r *http.Request) {
+	subs, err := h.webhookService.ListSubscriptions(r.Context())
+	if err != nil {
+		sendError(w, r, "Failed to list webhook subscriptions!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(subs)
+	utils.Logger(r.Context()).Info("List of webhook subscriptions displayed")
+}
+
+func (h *WebhookHandler) DeleteSubscription(w http.ResponseWriter, r *http.Request) {
+	id, err := router.IntParam(r, "id")
+	if err != nil {
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid webhook subscription ID")
+		return
+	}
+
+	if err := h.webhookService.DeleteSubscription(r.Context(), id); err != nil {
+		sendError(w, r, "Failed to delete webhook subscription!", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	utils.Logger(r.Context()).Info("Webhook subscription deleted", slog.Int("SubscriptionID", id))
+}
+
+func (h *WebhookHandler) ListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	letters, err := h.webhookService.ListDeadLetters(r.Context())
+	if err != nil {
+		sendError(w, r, "Failed to list dead letters!", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(letters)
+	utils.Logger(r.Context()).Info("List of webhook dead letters displayed")
+}
+
+func (h *WebhookHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	deliveryID, err := router.IntParam(r, "id")
+	if err != nil {
+		utils.SendProblem(w, r, http.StatusBadRequest, "Invalid webhook delivery ID")
+		return
+	}
+
+	if err := h.webhookService.Redeliver(r.Context(), int64(deliveryID)); err != nil {
+		sendError(w, r, "Failed to redeliver webhook!", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	utils.Logger(r.Context()).Info("Webhook delivery scheduled for redelivery", slog.Int("DeliveryID", deliveryID))
+}
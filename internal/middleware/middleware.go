@@ -0,0 +1,205 @@
+// Package middleware holds the handlers that wrap every request: request
+// ids, access logs, panic recovery, CORS and deadlines.
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"frappuccino/internal/router"
+	"frappuccino/internal/utils"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// validRequestID limits the ids taken from clients to what is safe to log
+// and store.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,100}$`)
+
+// recorder remembers the status and size of a response.
+type recorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func record(w http.ResponseWriter) *recorder {
+	if rec, ok := w.(*recorder); ok {
+		return rec
+	}
+	return &recorder{ResponseWriter: w}
+}
+
+func (rec *recorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *recorder) Write(data []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	n, err := rec.ResponseWriter.Write(data)
+	rec.bytes += n
+	return n, err
+}
+
+// Flush keeps server-sent events working through the recorder.
+func (rec *recorder) Flush() {
+	if flusher, ok := rec.ResponseWriter.(http.Flusher); ok {
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+		flusher.Flush()
+	}
+}
+
+func (rec *recorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// RequestID takes the X-Request-ID of the request or generates one, returns
+// it in the response and puts it into the context together with a logger
+// that tags every line with it.
+func RequestID(base *slog.Logger) router.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID := r.Header.Get(utils.RequestIDHeader)
+			if !validRequestID.MatchString(requestID) {
+				requestID = newRequestID()
+			}
+			w.Header().Set(utils.RequestIDHeader, requestID)
+
+			ctx := utils.WithRequestID(r.Context(), requestID)
+			ctx = utils.WithLogger(ctx, base.With(slog.String("request_id", requestID)))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func newRequestID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// AccessLog writes one line per request once it is served.
+func AccessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := record(w)
+		next.ServeHTTP(rec, r)
+
+		status := rec.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		utils.Logger(r.Context()).LogAttrs(r.Context(), level, "Request served",
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", status),
+			slog.Int("bytes", rec.bytes),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("remote_addr", r.RemoteAddr))
+	})
+}
+
+// Recover turns a panic in a handler into a 500, so one bad request doesn't
+// take the server down.
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := record(w)
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+			utils.Logger(r.Context()).Error("Panic while serving request",
+				slog.Any("panic", recovered), slog.String("stack", string(debug.Stack())))
+			if rec.status == 0 {
+				utils.SendProblem(rec, r, http.StatusInternalServerError, "Internal server error!")
+			}
+		}()
+		next.ServeHTTP(rec, r)
+	})
+}
+
+// CORSConfig lists the origins allowed to call the API from a browser,
+// such as the web POS. An origin of * allows every origin.
+type CORSConfig struct {
+	AllowedOrigins []string
+	MaxAge         time.Duration
+}
+
+var (
+	corsMethods = "GET, POST, PUT, DELETE, OPTIONS"
+	corsHeaders = "Authorization, Content-Type, Last-Event-ID, " + utils.EmployeeHeader + ", " + utils.RequestIDHeader
+)
+
+// CORS answers preflight requests and adds the CORS headers to responses
+// for allowed origins. Requests from other origins get no CORS headers, so
+// the browser refuses them.
+func CORS(config CORSConfig) router.Middleware {
+	allowed := map[string]bool{}
+	for _, origin := range config.AllowedOrigins {
+		allowed[strings.TrimSuffix(strings.TrimSpace(origin), "/")] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" || !(allowed["*"] || allowed[origin]) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Add("Vary", "Origin")
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Expose-Headers", utils.RequestIDHeader)
+
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				w.Header().Set("Access-Control-Allow-Methods", corsMethods)
+				w.Header().Set("Access-Control-Allow-Headers", corsHeaders)
+				if config.MaxAge > 0 {
+					w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(config.MaxAge.Seconds())))
+				}
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Timeout gives every request a deadline in its context. A handler that
+// runs out of time without answering gets a 503.
+func Timeout(timeout time.Duration) router.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			rec := record(w)
+			next.ServeHTTP(rec, r.WithContext(ctx))
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) && rec.status == 0 {
+				utils.Logger(ctx).Warn("Request timed out", slog.Duration("timeout", timeout))
+				utils.SendProblem(rec, r, http.StatusServiceUnavailable, "Request timed out!")
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package receipt
+
+import "bytes"
+
+// ESC/POS commands understood by common thermal printers.
+var (
+	escInit        = []byte{0x1b, 0x40}       // ESC @
+	escCodePage437 = []byte{0x1b, 0x74, 0x00} // ESC t 0
+	escAlignLeft   = []byte{0x1b, 0x61, 0x00} // ESC a 0
+	escAlignCenter = []byte{0x1b, 0x61, 0x01} // ESC a 1
+	escBoldOn      = []byte{0x1b, 0x45, 0x01} // ESC E 1
+	escBoldOff     = []byte{0x1b, 0x45, 0x00} // ESC E 0
+	escSizeDouble  = []byte{0x1d, 0x21, 0x11} // GS ! 0x11
+	escSizeNormal  = []byte{0x1d, 0x21, 0x00} // GS ! 0
+	escFeed        = []byte{0x1b, 0x64, 0x04} // ESC d 4
+	escPartialCut  = []byte{0x1d, 0x56, 0x01} // GS V 1
+)
+
+// renderESCPOS prints the rows with the printer's own alignment and
+// emphasis. Characters outside ASCII are replaced, since the printer is set
+// to code page 437 and multi-byte text would print as garbage.
+func renderESCPOS(rows []row) []byte {
+	var b bytes.Buffer
+	b.Write(escInit)
+	b.Write(escCodePage437)
+
+	for _, r := range rows {
+		width := lineWidth
+		if r.large {
+			width /= 2
+			b.Write(escSizeDouble)
+		}
+		if r.bold {
+			b.Write(escBoldOn)
+		}
+		if r.center {
+			b.Write(escAlignCenter)
+			r.center = false
+		}
+		for _, line := range textLines(r, width) {
+			b.WriteString(asciiOnly(line))
+			b.WriteByte('\n')
+		}
+		if r.bold {
+			b.Write(escBoldOff)
+		}
+		if r.large {
+			b.Write(escSizeNormal)
+		}
+		b.Write(escAlignLeft)
+	}
+
+	b.Write(escFeed)
+	b.Write(escPartialCut)
+	return b.Bytes()
+}
+
+func asciiOnly(s string) string {
+	out := make([]byte, 0, len(s))
+	for _, c := range s {
+		if c >= 0x20 && c < 0x7f {
+			out = append(out, byte(c))
+		} else {
+			out = append(out, '?')
+		}
+	}
+	return string(out)
+}
@@ -0,0 +1,68 @@
+package receipt
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+)
+
+var htmlTemplate = template.Must(template.New("receipt").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: monospace; max-width: 42ch; margin: 1em auto; }
+.row { display: flex; justify-content: space-between; white-space: pre-wrap; }
+.center { justify-content: center; text-align: center; }
+.bold { font-weight: bold; }
+.large { font-size: 1.6em; }
+hr { border: 0; border-top: 1px dashed; }
+</style>
+</head>
+<body>
+{{- range .Rows}}
+{{- if .Rule}}
+<hr>
+{{- else if .Blank}}
+<br>
+{{- else}}
+<div class="{{.Class}}"><span>{{.Left}}</span>{{if .Right}}<span>{{.Right}}</span>{{end}}</div>
+{{- end}}
+{{- end}}
+</body>
+</html>
+`))
+
+type htmlRow struct {
+	Rule, Blank bool
+	Class       string
+	Left, Right string
+}
+
+func renderHTML(r Receipt, rows []row) ([]byte, error) {
+	data := struct {
+		Title string
+		Rows  []htmlRow
+	}{Title: fmt.Sprintf("Order #%d", r.OrderID)}
+
+	for _, row := range rows {
+		h := htmlRow{Rule: row.kind == rowRule, Blank: row.kind == rowBlank, Class: "row", Left: row.left, Right: row.right}
+		if row.center {
+			h.Class += " center"
+		}
+		if row.bold {
+			h.Class += " bold"
+		}
+		if row.large {
+			h.Class += " large"
+		}
+		data.Rows = append(data.Rows, h)
+	}
+
+	var b bytes.Buffer
+	if err := htmlTemplate.Execute(&b, data); err != nil {
+		return nil, fmt.Errorf("failed to render receipt: %w", err)
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,213 @@
+// Package receipt renders customer receipts and kitchen tickets as plain
+// text, HTML or ESC/POS bytes for thermal printers. The output depends only
+// on the Receipt value, so the same receipt always renders the same bytes.
+package receipt
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	FormatText   = "text"
+	FormatHTML   = "html"
+	FormatESCPOS = "escpos"
+)
+
+// lineWidth is the number of characters per line of an 80 mm printer with
+// the default font.
+const lineWidth = 42
+
+var ErrUnknownFormat = errors.New("unknown receipt format")
+
+type Receipt struct {
+	Header              []string
+	Footer              []string
+	OrderID             int
+	CreatedAt           time.Time
+	CustomerName        string
+	OrderType           string
+	Kitchen             bool
+	Lines               []Line
+	SpecialInstructions []string
+	Subtotal            float64
+	Discounts           []Amount
+	Taxes               []Amount
+	TaxInclusive        bool
+	Total               float64
+	Payments            []Payment
+	Refunded            float64
+	BalanceDue          float64
+}
+
+type Line struct {
+	Name      string
+	Quantity  int
+	UnitPrice float64
+	Modifiers []string
+}
+
+type Amount struct {
+	Label  string
+	Amount float64
+}
+
+type Payment struct {
+	Method   string
+	Amount   float64
+	Tip      float64
+	Tendered *float64
+	Change   float64
+}
+
+// Render returns the receipt in the given format and its content type.
+func Render(r Receipt, format string) ([]byte, string, error) {
+	rows := layout(r)
+	switch format {
+	case FormatText:
+		return []byte(renderText(rows)), "text/plain; charset=utf-8", nil
+	case FormatHTML:
+		out, err := renderHTML(r, rows)
+		if err != nil {
+			return nil, "", err
+		}
+		return out, "text/html; charset=utf-8", nil
+	case FormatESCPOS:
+		return renderESCPOS(rows), "application/octet-stream", nil
+	}
+	return nil, "", fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+}
+
+type rowKind int
+
+const (
+	rowText rowKind = iota
+	rowPair
+	rowRule
+	rowBlank
+)
+
+// row is one line of the receipt, shared by all formats.
+type row struct {
+	kind   rowKind
+	left   string
+	right  string
+	center bool
+	bold   bool
+	large  bool
+}
+
+func text(s string) row           { return row{kind: rowText, left: s} }
+func pair(l, r string) row        { return row{kind: rowPair, left: l, right: r} }
+func centered(s string) row       { return row{kind: rowText, left: s, center: true} }
+func money(amount float64) string { return fmt.Sprintf("%.2f", amount) }
+
+func layout(r Receipt) []row {
+	var rows []row
+	for i, line := range r.Header {
+		header := centered(line)
+		if i == 0 {
+			header.bold, header.large = true, true
+		}
+		rows = append(rows, header)
+	}
+	if r.Kitchen {
+		rows = append(rows, row{kind: rowText, left: "KITCHEN", center: true, bold: true, large: true})
+	}
+	rows = append(rows, row{kind: rowRule})
+
+	rows = append(rows, pair(fmt.Sprintf("Order #%d", r.OrderID), r.CreatedAt.Format("2006-01-02 15:04")))
+	if r.CustomerName != "" {
+		rows = append(rows, text("Customer: "+r.CustomerName))
+	}
+	if r.OrderType != "" {
+		rows = append(rows, text(orderTypeLabel(r.OrderType)))
+	}
+	rows = append(rows, row{kind: rowRule})
+
+	for _, line := range r.Lines {
+		item := fmt.Sprintf("%d x %s", line.Quantity, line.Name)
+		if r.Kitchen {
+			rows = append(rows, row{kind: rowText, left: item, bold: true})
+		} else {
+			rows = append(rows, pair(item, money(line.UnitPrice*float64(line.Quantity))))
+			if line.Quantity > 1 {
+				rows = append(rows, text("    @ "+money(line.UnitPrice)))
+			}
+		}
+		for _, modifier := range line.Modifiers {
+			rows = append(rows, text("  + "+modifier))
+		}
+	}
+	if len(r.SpecialInstructions) > 0 {
+		rows = append(rows, row{kind: rowBlank})
+		for _, instruction := range r.SpecialInstructions {
+			rows = append(rows, row{kind: rowText, left: "Note: " + instruction, bold: r.Kitchen})
+		}
+	}
+	if r.Kitchen {
+		rows = append(rows, row{kind: rowRule})
+		return rows
+	}
+	rows = append(rows, row{kind: rowRule})
+
+	rows = append(rows, pair("Subtotal", money(r.Subtotal)))
+	for _, discount := range r.Discounts {
+		rows = append(rows, pair(discount.Label, money(-discount.Amount)))
+	}
+	for _, tax := range r.Taxes {
+		if r.TaxInclusive {
+			rows = append(rows, pair("incl. "+tax.Label, money(tax.Amount)))
+		} else {
+			rows = append(rows, pair(tax.Label, money(tax.Amount)))
+		}
+	}
+	rows = append(rows, row{kind: rowPair, left: "TOTAL", right: money(r.Total), bold: true})
+
+	if len(r.Payments) > 0 {
+		rows = append(rows, row{kind: rowBlank})
+		for _, payment := range r.Payments {
+			rows = append(rows, pair(paymentLabel(payment.Method), money(payment.Amount)))
+			if payment.Tip > 0 {
+				rows = append(rows, pair("  Tip", money(payment.Tip)))
+			}
+			if payment.Tendered != nil {
+				rows = append(rows, pair("  Tendered", money(*payment.Tendered)))
+				rows = append(rows, pair("  Change", money(payment.Change)))
+			}
+		}
+	}
+	if r.Refunded > 0 {
+		rows = append(rows, pair("Refunded", money(-r.Refunded)))
+	}
+	if r.BalanceDue > 0 {
+		rows = append(rows, row{kind: rowPair, left: "BALANCE DUE", right: money(r.BalanceDue), bold: true})
+	}
+
+	if len(r.Footer) > 0 {
+		rows = append(rows, row{kind: rowRule})
+		for _, line := range r.Footer {
+			rows = append(rows, centered(line))
+		}
+	}
+	return rows
+}
+
+func orderTypeLabel(orderType string) string {
+	switch orderType {
+	case "dine_in":
+		return "Dine in"
+	case "takeaway":
+		return "Takeaway"
+	}
+	return orderType
+}
+
+func paymentLabel(method string) string {
+	if method == "" {
+		return method
+	}
+	return strings.ToUpper(method[:1]) + method[1:]
+}
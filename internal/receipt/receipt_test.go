@@ -0,0 +1,204 @@
+package receipt
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func TestRenderESCPOS(t *testing.T) {
+	createdAt := time.Date(2024, 3, 5, 14, 30, 0, 0, time.UTC)
+	header := []string{"Frappuccino", "12 Market Street"}
+	tendered := 20.0
+
+	tests := []struct {
+		name    string
+		receipt Receipt
+	}{
+		{
+			name: "taxes",
+			receipt: Receipt{
+				Header:    header,
+				OrderID:   101,
+				CreatedAt: createdAt,
+				OrderType: "takeaway",
+				Lines: []Line{
+					{Name: "Latte", Quantity: 2, UnitPrice: 4.5},
+					{Name: "Croissant", Quantity: 1, UnitPrice: 3},
+				},
+				Subtotal: 12,
+				Taxes: []Amount{
+					{Label: "VAT 10%", Amount: 1.2},
+					{Label: "City tax 2%", Amount: 0.24},
+				},
+				Total:    13.44,
+				Payments: []Payment{{Method: "card", Amount: 13.44}},
+				Footer:   []string{"Thank you!"},
+			},
+		},
+		{
+			name: "tax_inclusive",
+			receipt: Receipt{
+				Header:       header,
+				OrderID:      102,
+				CreatedAt:    createdAt,
+				OrderType:    "dine_in",
+				Lines:        []Line{{Name: "Espresso", Quantity: 1, UnitPrice: 2.2}},
+				Subtotal:     2.2,
+				Taxes:        []Amount{{Label: "VAT 10%", Amount: 0.2}},
+				TaxInclusive: true,
+				Total:        2.2,
+				BalanceDue:   2.2,
+			},
+		},
+		{
+			name: "discounts",
+			receipt: Receipt{
+				Header:       header,
+				OrderID:      103,
+				CreatedAt:    createdAt,
+				CustomerName: "Alice",
+				Lines: []Line{
+					{Name: "Cappuccino", Quantity: 3, UnitPrice: 4},
+					{Name: "Muffin", Quantity: 1, UnitPrice: 2.5},
+				},
+				Subtotal: 14.5,
+				Discounts: []Amount{
+					{Label: "Happy hour", Amount: 1.45},
+					{Label: "Loyalty points", Amount: 2},
+				},
+				Taxes:    []Amount{{Label: "VAT 10%", Amount: 1.11}},
+				Total:    12.16,
+				Payments: []Payment{{Method: "cash", Amount: 12.16, Tendered: &tendered, Change: 7.84}},
+			},
+		},
+		{
+			name: "modifiers",
+			receipt: Receipt{
+				Header:    header,
+				OrderID:   104,
+				CreatedAt: createdAt,
+				Lines: []Line{
+					{Name: "Flat white", Quantity: 1, UnitPrice: 4.2, Modifiers: []string{"Oat milk", "Extra shot"}},
+					{Name: "Tea", Quantity: 2, UnitPrice: 2.5, Modifiers: []string{"No sugar"}},
+				},
+				SpecialInstructions: []string{"Extra hot"},
+				Subtotal:            9.2,
+				Total:               9.2,
+				Payments:            []Payment{{Method: "card", Amount: 9.2, Tip: 1}},
+			},
+		},
+		{
+			name: "split_payments",
+			receipt: Receipt{
+				Header:    header,
+				OrderID:   105,
+				CreatedAt: createdAt,
+				Lines:     []Line{{Name: "Mocha", Quantity: 4, UnitPrice: 5}},
+				Subtotal:  20,
+				Total:     20,
+				Payments: []Payment{
+					{Method: "card", Amount: 8},
+					{Method: "cash", Amount: 7, Tendered: &tendered, Change: 13},
+					{Method: "voucher", Amount: 5},
+				},
+				Refunded: 5,
+			},
+		},
+		{
+			name: "long_names",
+			receipt: Receipt{
+				Header:       []string{"The Very Long Name Of A Small Coffee Shop Downtown", "12 Market Street"},
+				OrderID:      106,
+				CreatedAt:    createdAt,
+				CustomerName: "Zoë Ångström-Øverland",
+				Lines: []Line{
+					{Name: "Caramel hazelnut macchiato with whipped cream and cinnamon", Quantity: 1, UnitPrice: 6.75},
+					{Name: "Crème brûlée", Quantity: 12, UnitPrice: 5.5},
+				},
+				Subtotal: 72.75,
+				Total:    72.75,
+				Payments: []Payment{{Method: "card", Amount: 72.75}},
+			},
+		},
+		{
+			name: "kitchen",
+			receipt: Receipt{
+				Header:              header,
+				OrderID:             107,
+				CreatedAt:           createdAt,
+				OrderType:           "dine_in",
+				Kitchen:             true,
+				Lines:               []Line{{Name: "Latte", Quantity: 2, UnitPrice: 4.5, Modifiers: []string{"Oat milk"}}},
+				SpecialInstructions: []string{"Table 4"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, contentType, err := Render(tt.receipt, FormatESCPOS)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if contentType != "application/octet-stream" {
+				t.Errorf("content type = %q, want application/octet-stream", contentType)
+			}
+
+			golden := filepath.Join("testdata", tt.name+".escpos.golden")
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v; run go test -update to create it", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("output differs from %s at byte %d\ngot:  %q\nwant: %q", golden, firstDifference(got, want), got, want)
+			}
+		})
+	}
+}
+
+func TestRenderIsDeterministic(t *testing.T) {
+	r := Receipt{
+		OrderID:  1,
+		Lines:    []Line{{Name: "Latte", Quantity: 1, UnitPrice: 4.5}},
+		Subtotal: 4.5,
+		Total:    4.5,
+	}
+	for _, format := range []string{FormatText, FormatHTML, FormatESCPOS} {
+		first, _, err := Render(r, format)
+		if err != nil {
+			t.Fatalf("Render(%s) error = %v", format, err)
+		}
+		second, _, _ := Render(r, format)
+		if !bytes.Equal(first, second) {
+			t.Errorf("Render(%s) isn't deterministic", format)
+		}
+	}
+}
+
+func TestRenderUnknownFormat(t *testing.T) {
+	if _, _, err := Render(Receipt{}, "pdf"); !errors.Is(err, ErrUnknownFormat) {
+		t.Fatalf("Render(pdf) error = %v, want ErrUnknownFormat", err)
+	}
+}
+
+func firstDifference(a, b []byte) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return min(len(a), len(b))
+}
@@ -0,0 +1,72 @@
+package receipt
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+func renderText(rows []row) string {
+	var b strings.Builder
+	for _, r := range rows {
+		for _, line := range textLines(r, lineWidth) {
+			b.WriteString(line)
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
+
+// textLines lays a row out in width characters. The left side of a pair is
+// wrapped so the right side fits at the end of its last line.
+func textLines(r row, width int) []string {
+	switch r.kind {
+	case rowRule:
+		return []string{strings.Repeat("-", width)}
+	case rowBlank:
+		return []string{""}
+	case rowPair:
+		right := utf8.RuneCountInString(r.right)
+		if right+1 >= width {
+			return append(wrap(r.left, width), pad(r.right, width))
+		}
+		lines := wrap(r.left, width-right-1)
+		last := len(lines) - 1
+		lines[last] += strings.Repeat(" ", width-utf8.RuneCountInString(lines[last])-right) + r.right
+		return lines
+	}
+
+	lines := wrap(r.left, width)
+	if r.center {
+		for i, line := range lines {
+			lines[i] = strings.Repeat(" ", (width-utf8.RuneCountInString(line))/2) + line
+		}
+	}
+	return lines
+}
+
+// pad right-aligns s in width characters.
+func pad(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return strings.Repeat(" ", width-n) + s
+	}
+	return s
+}
+
+// wrap breaks s into lines of at most width characters, at spaces where
+// possible.
+func wrap(s string, width int) []string {
+	var lines []string
+	runes := []rune(s)
+	for len(runes) > width {
+		cut := width
+		for i := width; i > 0; i-- {
+			if runes[i] == ' ' {
+				cut = i
+				break
+			}
+		}
+		lines = append(lines, strings.TrimRight(string(runes[:cut]), " "))
+		runes = []rune(strings.TrimLeft(string(runes[cut:]), " "))
+	}
+	return append(lines, string(runes))
+}
@@ -0,0 +1,240 @@
+// Package router matches requests to handlers by method and path. Routes are
+// compiled into a tree of path segments when they are registered, so a
+// request is matched by walking down the tree, without regular expressions.
+package router
+
+import (
+	"fmt"
+	"frappuccino/internal/utils"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Middleware wraps a handler with behaviour that runs around it.
+type Middleware func(http.Handler) http.Handler
+
+// Chain composes middleware so that the first one is the outermost.
+func Chain(middleware ...Middleware) Middleware {
+	return func(next http.Handler) http.Handler {
+		for i := len(middleware) - 1; i >= 0; i-- {
+			next = middleware[i](next)
+		}
+		return next
+	}
+}
+
+// node is one path segment. Static children are preferred over the
+// parameter child, so /orders/stream wins over /orders/{id} whatever the
+// order the routes were registered in.
+type node struct {
+	static   map[string]*node
+	param    *node
+	name     string
+	handlers map[string]http.Handler
+	allow    string
+}
+
+func newNode() *node {
+	return &node{static: map[string]*node{}, handlers: map[string]http.Handler{}}
+}
+
+type Router struct {
+	root       *node
+	middleware []Middleware
+	handler    http.Handler
+}
+
+func New() *Router {
+	router := &Router{root: newNode()}
+	router.handler = http.HandlerFunc(router.serve)
+	return router
+}
+
+// Use adds middleware that runs for every request, including those that
+// match no route. It must be called before the router serves requests.
+func (router *Router) Use(middleware ...Middleware) {
+	router.middleware = append(router.middleware, middleware...)
+	router.handler = Chain(router.middleware...)(http.HandlerFunc(router.serve))
+}
+
+// Handle registers a handler for a method and a path such as
+// /orders/{id}/payments, where {id} matches any one segment.
+func (router *Router) Handle(method, path string, handler http.Handler) {
+	if !strings.HasPrefix(path, "/") {
+		log.Fatalf("Invalid route path: %s", path)
+	}
+
+	current := router.root
+	for _, segment := range splitPath(path) {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			name := segment[1 : len(segment)-1]
+			if current.param == nil {
+				current.param = newNode()
+				current.param.name = name
+			}
+			if current.param.name != name {
+				log.Fatalf("Route %s names the parameter {%s}, already registered as {%s}", path, name, current.param.name)
+			}
+			current = current.param
+			continue
+		}
+		child, ok := current.static[segment]
+		if !ok {
+			child = newNode()
+			current.static[segment] = child
+		}
+		current = child
+	}
+
+	if _, ok := current.handlers[method]; ok {
+		log.Fatalf("Route %s %s is registered twice", method, path)
+	}
+	current.handlers[method] = handler
+	current.allow = allowHeader(current.handlers)
+}
+
+// HandleFunc registers a handler for a route written as "METHOD /path".
+func (router *Router) HandleFunc(route string, handler http.HandlerFunc) {
+	method, path := splitRoute(route)
+	router.Handle(method, path, handler)
+}
+
+// Group returns routes under a path prefix that share middleware.
+func (router *Router) Group(prefix string, middleware ...Middleware) *Group {
+	return &Group{router: router, prefix: strings.TrimSuffix(prefix, "/"), middleware: middleware}
+}
+
+func (router *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	router.handler.ServeHTTP(w, r)
+}
+
+func (router *Router) serve(w http.ResponseWriter, r *http.Request) {
+	current, params := router.root.match(splitPath(r.URL.Path), nil)
+	if current == nil {
+		utils.SendProblem(w, r, http.StatusNotFound, "Not found!")
+		return
+	}
+
+	handler, ok := current.handlers[r.Method]
+	if !ok && r.Method == http.MethodHead {
+		handler, ok = current.handlers[http.MethodGet]
+	}
+	if !ok {
+		w.Header().Set("Allow", current.allow)
+		utils.SendProblem(w, r, http.StatusMethodNotAllowed, "Method not allowed!")
+		return
+	}
+
+	for _, param := range params {
+		r.SetPathValue(param[0], param[1])
+	}
+	handler.ServeHTTP(w, r)
+}
+
+// match walks down the tree, trying the static child of each segment before
+// the parameter child, and returns the node with handlers the path ends at.
+func (n *node) match(segments []string, params [][2]string) (*node, [][2]string) {
+	if len(segments) == 0 {
+		if len(n.handlers) == 0 {
+			return nil, nil
+		}
+		return n, params
+	}
+	if child, ok := n.static[segments[0]]; ok {
+		if found, foundParams := child.match(segments[1:], params); found != nil {
+			return found, foundParams
+		}
+	}
+	if n.param != nil && segments[0] != "" {
+		return n.param.match(segments[1:], append(params, [2]string{n.param.name, segments[0]}))
+	}
+	return nil, nil
+}
+
+// Group registers routes under a prefix, wrapped in the group's middleware.
+type Group struct {
+	router     *Router
+	prefix     string
+	middleware []Middleware
+}
+
+// Use adds middleware to the routes registered on the group afterwards.
+func (group *Group) Use(middleware ...Middleware) {
+	group.middleware = append(group.middleware, middleware...)
+}
+
+// Group returns a group nested in this one, with its middleware running
+// inside the middleware of this group.
+func (group *Group) Group(prefix string, middleware ...Middleware) *Group {
+	return &Group{
+		router:     group.router,
+		prefix:     group.prefix + strings.TrimSuffix(prefix, "/"),
+		middleware: append(append([]Middleware{}, group.middleware...), middleware...),
+	}
+}
+
+// Handle registers a handler for a path relative to the group prefix. The
+// path / stands for the prefix itself.
+func (group *Group) Handle(method, path string, handler http.Handler) {
+	full := group.prefix + path
+	if path == "/" && group.prefix != "" {
+		full = group.prefix
+	}
+	group.router.Handle(method, full, Chain(group.middleware...)(handler))
+}
+
+// HandleFunc registers a handler for a route written as "METHOD /path".
+func (group *Group) HandleFunc(route string, handler http.HandlerFunc) {
+	method, path := splitRoute(route)
+	group.Handle(method, path, handler)
+}
+
+// Param returns a path parameter of the matched route, empty when the
+// route has no such parameter.
+func Param(r *http.Request, name string) string {
+	return r.PathValue(name)
+}
+
+// IntParam returns a path parameter as a positive integer.
+func IntParam(r *http.Request, name string) (int, error) {
+	value := r.PathValue(name)
+	id, err := strconv.Atoi(value)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid path parameter %s: %q", name, value)
+	}
+	return id, nil
+}
+
+func splitRoute(route string) (string, string) {
+	method, path, ok := strings.Cut(route, " ")
+	if !ok || method == "" {
+		log.Fatalf("Invalid route format: %s", route)
+	}
+	return method, path
+}
+
+// splitPath returns the segments of a path; the root has none.
+func splitPath(path string) []string {
+	path = strings.TrimPrefix(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+func allowHeader(handlers map[string]http.Handler) string {
+	methods := make([]string, 0, len(handlers)+1)
+	for method := range handlers {
+		methods = append(methods, method)
+	}
+	if _, ok := handlers[http.MethodGet]; ok {
+		if _, ok := handlers[http.MethodHead]; !ok {
+			methods = append(methods, http.MethodHead)
+		}
+	}
+	sort.Strings(methods)
+	return strings.Join(methods, ", ")
+}
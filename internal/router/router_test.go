@@ -0,0 +1,136 @@
+package router
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func respond(body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, body)
+	}
+}
+
+func newTestRouter() *Router {
+	router := New()
+	router.HandleFunc("GET /orders", respond("list"))
+	router.HandleFunc("POST /orders", respond("create"))
+	router.HandleFunc("GET /orders/{id}", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "order "+Param(r, "id"))
+	})
+	router.HandleFunc("DELETE /orders/{id}", respond("delete"))
+	router.HandleFunc("GET /orders/stream", respond("stream"))
+	router.HandleFunc("POST /orders/{id}/close", respond("close"))
+	router.HandleFunc("GET /menu", respond("menu"))
+	router.HandleFunc("HEAD /menu", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Head", "explicit")
+	})
+	return router
+}
+
+func TestRouterMethods(t *testing.T) {
+	router := newTestRouter()
+
+	tests := []struct {
+		method     string
+		path       string
+		wantStatus int
+		wantBody   string
+		wantAllow  string
+	}{
+		{http.MethodGet, "/orders", http.StatusOK, "list", ""},
+		{http.MethodPost, "/orders", http.StatusOK, "create", ""},
+		{http.MethodHead, "/orders", http.StatusOK, "list", ""},
+		{http.MethodHead, "/orders/7", http.StatusOK, "order 7", ""},
+		{http.MethodPut, "/orders", http.StatusMethodNotAllowed, "", "GET, HEAD, POST"},
+		{http.MethodPatch, "/orders/7", http.StatusMethodNotAllowed, "", "DELETE, GET, HEAD"},
+		{http.MethodGet, "/orders/7/close", http.StatusMethodNotAllowed, "", "POST"},
+		{http.MethodHead, "/orders/7/close", http.StatusMethodNotAllowed, "", "POST"},
+		{http.MethodDelete, "/menu", http.StatusMethodNotAllowed, "", "GET, HEAD"},
+		{http.MethodGet, "/missing", http.StatusNotFound, "", ""},
+		{http.MethodGet, "/orders/7/items", http.StatusNotFound, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", w.Body.String(), tt.wantBody)
+			}
+			if allow := w.Header().Get("Allow"); allow != tt.wantAllow {
+				t.Errorf("Allow = %q, want %q", allow, tt.wantAllow)
+			}
+		})
+	}
+}
+
+func TestRouterExplicitHead(t *testing.T) {
+	w := httptest.NewRecorder()
+	newTestRouter().ServeHTTP(w, httptest.NewRequest(http.MethodHead, "/menu", nil))
+	if w.Header().Get("X-Head") != "explicit" || w.Body.Len() != 0 {
+		t.Errorf("HEAD /menu served by the GET handler, want the HEAD handler")
+	}
+}
+
+func TestRouterStaticBeforeParam(t *testing.T) {
+	router := newTestRouter()
+	for path, want := range map[string]string{
+		"/orders/stream": "stream",
+		"/orders/42":     "order 42",
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Body.String() != want {
+			t.Errorf("GET %s = %q, want %q", path, w.Body.String(), want)
+		}
+	}
+}
+
+func TestRouterMiddlewareOrder(t *testing.T) {
+	var calls []string
+	trace := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls = append(calls, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	router := New()
+	router.Use(trace("global"))
+	api := router.Group("/api", trace("group"))
+	api.Group("/admin", trace("nested")).HandleFunc("GET /", respond("admin"))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin", nil))
+	if got := strings.Join(calls, ","); got != "global,group,nested" || w.Body.String() != "admin" {
+		t.Errorf("calls = %s body = %q, want global,group,nested and admin", got, w.Body.String())
+	}
+
+	// Global middleware runs for requests that match no route too
+	calls = nil
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/nowhere", nil))
+	if got := strings.Join(calls, ","); got != "global" {
+		t.Errorf("calls = %s, want global", got)
+	}
+}
+
+func TestIntParam(t *testing.T) {
+	for value, wantErr := range map[string]bool{"7": false, "0": true, "-1": true, "abc": true} {
+		r := httptest.NewRequest(http.MethodGet, "/orders/"+value, nil)
+		r.SetPathValue("id", value)
+		id, err := IntParam(r, "id")
+		if (err != nil) != wantErr {
+			t.Errorf("IntParam(%q) = %d, %v", value, id, err)
+		}
+	}
+}
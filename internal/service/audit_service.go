@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"frappuccino/internal/dal"
+	"frappuccino/models"
+)
+
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 500
+)
+
+type AuditService struct {
+	repo dal.AuditInterface
+}
+
+func NewAuditService(repo dal.AuditInterface) *AuditService {
+	return &AuditService{
+		repo: repo,
+	}
+}
+
+// List returns a page of the audit log, newest first.
+func (s *AuditService) List(ctx context.Context, filter models.AuditFilter) (models.AuditPage, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultAuditLimit
+	}
+	if filter.Limit > maxAuditLimit {
+		filter.Limit = maxAuditLimit
+	}
+
+	// Fetch one extra row to know whether there is a next page
+	limit := filter.Limit
+	filter.Limit++
+	entries, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return models.AuditPage{}, err
+	}
+
+	page := models.AuditPage{Entries: entries}
+	if len(entries) > limit {
+		page.Entries = entries[:limit]
+		page.HasMore = true
+		next := page.Entries[limit-1].ID
+		page.NextCursor = &next
+	}
+	return page, nil
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"frappuccino/internal/auth"
+	"frappuccino/internal/dal"
+	"frappuccino/models"
+	"strings"
+	"time"
+)
+
+// SessionTTL is how long a staff login lasts.
+const SessionTTL = 12 * time.Hour
+
+// ErrUnauthenticated is returned for any credentials or token that don't
+// authenticate; the reason is not told to the caller.
+var ErrUnauthenticated = errors.New("invalid credentials")
+
+// dummyPasswordHash is verified against when a username doesn't exist, so
+// that unknown and known usernames take the same time to reject.
+var dummyPasswordHash, _ = auth.HashPassword("frappuccino")
+
+type AuthService struct {
+	repo         dal.AuthInterface
+	employeeRepo dal.EmployeeInterface
+	secret       []byte
+}
+
+func NewAuthService(repo dal.AuthInterface, employeeRepo dal.EmployeeInterface, secret []byte) *AuthService {
+	return &AuthService{
+		repo:         repo,
+		employeeRepo: employeeRepo,
+		secret:       secret,
+	}
+}
+
+// Login checks a username and password and starts a session.
+func (s *AuthService) Login(ctx context.Context, request models.LoginRequest) (models.LoginResponse, error) {
+	credentials, err := s.repo.GetCredentials(ctx, request.Username)
+	if err != nil {
+		if !errors.Is(err, models.ErrNotFound) {
+			return models.LoginResponse{}, err
+		}
+		auth.VerifyPassword(request.Password, dummyPasswordHash)
+		return models.LoginResponse{}, ErrUnauthenticated
+	}
+	if !auth.VerifyPassword(request.Password, credentials.PasswordHash) || !credentials.Active {
+		return models.LoginResponse{}, ErrUnauthenticated
+	}
+
+	employee, err := s.employeeRepo.GetByID(ctx, credentials.EmployeeID)
+	if err != nil {
+		return models.LoginResponse{}, err
+	}
+	sessionID, _, err := s.repo.CreateSession(ctx, credentials.EmployeeID, SessionTTL)
+	if err != nil {
+		return models.LoginResponse{}, err
+	}
+	// The expiry in the token is what clients see; the row expires at the
+	// same moment by the database clock
+	expiresAt := time.Now().Add(SessionTTL).Truncate(time.Second)
+	return models.LoginResponse{
+		Token:     auth.SignSession(s.secret, sessionID, expiresAt),
+		ExpiresAt: expiresAt,
+		Employee:  employee,
+	}, nil
+}
+
+// Authenticate resolves a bearer token to the caller it belongs to.
+func (s *AuthService) Authenticate(ctx context.Context, token string) (models.Principal, error) {
+	if strings.HasPrefix(token, auth.APITokenPrefix) {
+		apiToken, err := s.repo.GetActiveAPIToken(ctx, auth.HashAPIToken(token))
+		if err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				return models.Principal{}, ErrUnauthenticated
+			}
+			return models.Principal{}, err
+		}
+		return models.Principal{
+			Kind: models.PrincipalAPIToken,
+			ID:   apiToken.ID,
+			Name: apiToken.Name,
+			Role: apiToken.Role,
+		}, nil
+	}
+
+	sessionID, err := auth.ParseSession(s.secret, token, time.Now())
+	if err != nil {
+		return models.Principal{}, ErrUnauthenticated
+	}
+	session, err := s.repo.GetActiveSession(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return models.Principal{}, ErrUnauthenticated
+		}
+		return models.Principal{}, err
+	}
+	if !session.Active {
+		return models.Principal{}, ErrUnauthenticated
+	}
+	return models.Principal{
+		Kind:       models.PrincipalSession,
+		ID:         session.ID,
+		EmployeeID: &session.EmployeeID,
+		Name:       session.EmployeeName,
+		Role:       session.Role,
+	}, nil
+}
+
+// Logout revokes the session of a staff member. API tokens are revoked
+// through RevokeAPIToken instead.
+func (s *AuthService) Logout(ctx context.Context, principal models.Principal) error {
+	if principal.Kind != models.PrincipalSession {
+		return models.Invalid("only session tokens can log out")
+	}
+	return s.repo.RevokeSession(ctx, principal.ID)
+}
+
+// EmployeeRole returns the role of the employee whose credentials are set.
+func (s *AuthService) EmployeeRole(ctx context.Context, employeeID int) (string, error) {
+	employee, err := s.employeeRepo.GetByID(ctx, employeeID)
+	if err != nil {
+		return "", err
+	}
+	return employee.Role, nil
+}
+
+func (s *AuthService) SetCredentials(ctx context.Context, employeeID int, credentials models.Credentials) error {
+	hash, err := auth.HashPassword(credentials.Password)
+	if err != nil {
+		return err
+	}
+	return s.repo.SetCredentials(ctx, employeeID, credentials.Username, hash)
+}
+
+// CreateAPIToken issues a token. Its value is returned only here.
+func (s *AuthService) CreateAPIToken(ctx context.Context, token *models.APIToken, createdBy models.Principal) error {
+	value, hash, err := auth.NewAPIToken()
+	if err != nil {
+		return fmt.Errorf("failed to create API token: %w", err)
+	}
+	token.Token = value
+	token.CreatedBy = createdBy.EmployeeID
+	return s.repo.CreateAPIToken(ctx, token, hash)
+}
+
+func (s *AuthService) ListAPITokens(ctx context.Context) ([]models.APIToken, error) {
+	return s.repo.ListAPITokens(ctx)
+}
+
+func (s *AuthService) RevokeAPIToken(ctx context.Context, id int) error {
+	return s.repo.RevokeAPIToken(ctx, id)
+}
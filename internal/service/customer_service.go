@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"frappuccino/internal/dal"
+	"frappuccino/models"
+)
+
+type CustomerService struct {
+	repo dal.CustomerInterface
+}
+
+func NewCustomerService(repo dal.CustomerInterface) *CustomerService {
+	return &CustomerService{
+		repo: repo,
+	}
+}
+
+func (s *CustomerService) Create(ctx context.Context, customer *models.Customer) error {
+	return s.repo.Create(ctx, customer)
+}
+
+func (s *CustomerService) GetByID(ctx context.Context, id int) (models.Customer, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *CustomerService) List(ctx context.Context, search string) ([]models.Customer, error) {
+	return s.repo.List(ctx, search)
+}
+
+func (s *CustomerService) Update(ctx context.Context, customer models.Customer, id int) error {
+	return s.repo.Update(ctx, customer, id)
+}
+
+func (s *CustomerService) Delete(ctx context.Context, id int) error {
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *CustomerService) GetStats(ctx context.Context, id int) (models.CustomerStats, error) {
+	stats, err := s.repo.GetStats(ctx, id)
+	if err != nil {
+		return models.CustomerStats{}, err
+	}
+	stats.LifetimeValue = roundMoney(stats.LifetimeValue)
+	if stats.CompletedOrders > 0 {
+		stats.AverageOrderValue = roundMoney(stats.LifetimeValue / float64(stats.CompletedOrders))
+	}
+	return stats, nil
+}
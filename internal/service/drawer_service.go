@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"frappuccino/internal/dal"
+	"frappuccino/models"
+	"strings"
+)
+
+type DrawerService struct {
+	repo dal.DrawerInterface
+}
+
+func NewDrawerService(repo dal.DrawerInterface) *DrawerService {
+	return &DrawerService{
+		repo: repo,
+	}
+}
+
+func (s *DrawerService) Open(ctx context.Context, session *models.DrawerSession) error {
+	session.OpeningFloat = roundMoney(session.OpeningFloat)
+	return s.repo.Open(ctx, session)
+}
+
+func (s *DrawerService) List(ctx context.Context) ([]models.DrawerSession, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *DrawerService) GetByID(ctx context.Context, id int) (models.DrawerSession, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *DrawerService) AddMovement(ctx context.Context, sessionID int, movement *models.DrawerMovement) error {
+	movement.SessionID = sessionID
+	movement.Amount = roundMoney(movement.Amount)
+	return s.repo.AddMovement(ctx, movement)
+}
+
+// Close counts the drawer and takes the Z-report snapshot in the same
+// transaction that closes the session, so nothing recorded later can
+// change it.
+func (s *DrawerService) Close(ctx context.Context, id int, request models.DrawerCloseRequest) (models.ZReport, error) {
+	tx, err := s.repo.BeginTransaction(ctx)
+	if err != nil {
+		return models.ZReport{}, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	session, err := s.repo.LockSession(ctx, tx, id)
+	if err != nil {
+		return models.ZReport{}, err
+	}
+	if session.Status != "open" {
+		return models.ZReport{}, models.Conflict("drawer session %d is closed", id)
+	}
+
+	report, err := s.repo.BuildReport(ctx, tx, session)
+	if err != nil {
+		return models.ZReport{}, err
+	}
+	report.ClosedBy = request.ClosedBy
+	report.CountedCash = roundMoney(*request.CountedCash)
+	completeZReport(&report)
+
+	if err := s.repo.SaveClose(ctx, tx, report); err != nil {
+		return models.ZReport{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return models.ZReport{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return report, nil
+}
+
+func (s *DrawerService) GetZReport(ctx context.Context, id int) (models.ZReport, error) {
+	return s.repo.GetZReport(ctx, id)
+}
+
+// completeZReport works out the totals and the cash the drawer should hold.
+// Cash tips stay in the drawer until they are paid out as cash out.
+func completeZReport(report *models.ZReport) {
+	report.GrossSales = roundMoney(report.GrossSales)
+	report.Discounts = roundMoney(report.Discounts)
+	report.Tax = roundMoney(report.Tax)
+	report.TotalSales = roundMoney(report.TotalSales)
+	report.CashIn = roundMoney(report.CashIn)
+	report.CashOut = roundMoney(report.CashOut)
+
+	expected := report.OpeningFloat + report.CashIn - report.CashOut
+	for i := range report.Tenders {
+		tender := &report.Tenders[i]
+		tender.Amount = roundMoney(tender.Amount)
+		tender.Tips = roundMoney(tender.Tips)
+		tender.Refunds = roundMoney(tender.Refunds)
+		tender.Net = roundMoney(tender.Amount - tender.Refunds)
+		report.TotalPayments += tender.Amount
+		report.TotalTips += tender.Tips
+		report.TotalRefunds += tender.Refunds
+		if tender.Method == "cash" {
+			expected += tender.Amount + tender.Tips - tender.Refunds
+		}
+	}
+	report.TotalPayments = roundMoney(report.TotalPayments)
+	report.TotalTips = roundMoney(report.TotalTips)
+	report.TotalRefunds = roundMoney(report.TotalRefunds)
+	report.ExpectedCash = roundMoney(expected)
+	report.CashVariance = roundMoney(report.CountedCash - report.ExpectedCash)
+}
+
+// FormatZReport renders a Z-report as fixed-width text for printing.
+func FormatZReport(report models.ZReport) string {
+	const width = 40
+	var b strings.Builder
+	line := func(label string, value float64) {
+		amount := fmt.Sprintf("%.2f", value)
+		fmt.Fprintf(&b, "%-*s%s\n", width-len(amount), label, amount)
+	}
+	rule := strings.Repeat("-", width) + "\n"
+
+	title := fmt.Sprintf("Z-REPORT #%d", report.SessionID)
+	fmt.Fprintf(&b, "%*s\n", (width+len(title))/2, title)
+	b.WriteString(rule)
+	fmt.Fprintf(&b, "Opened: %s by %s\n", report.OpenedAt.Format("2006-01-02 15:04"), report.OpenedBy)
+	fmt.Fprintf(&b, "Closed: %s by %s\n", report.ClosedAt.Format("2006-01-02 15:04"), report.ClosedBy)
+	b.WriteString(rule)
+
+	fmt.Fprintf(&b, "%-*s%d\n", width-len(fmt.Sprint(report.Orders)), "Orders", report.Orders)
+	line("Gross sales", report.GrossSales)
+	line("Discounts", report.Discounts)
+	line("Tax", report.Tax)
+	line("Total sales", report.TotalSales)
+	b.WriteString(rule)
+
+	for _, tender := range report.Tenders {
+		fmt.Fprintf(&b, "%s (%d)\n", strings.ToUpper(tender.Method), tender.Payments)
+		line("  Payments", tender.Amount)
+		line("  Tips", tender.Tips)
+		line("  Refunds", tender.Refunds)
+		line("  Net", tender.Net)
+	}
+	line("Total payments", report.TotalPayments)
+	line("Total tips", report.TotalTips)
+	line("Total refunds", report.TotalRefunds)
+	b.WriteString(rule)
+
+	line("Opening float", report.OpeningFloat)
+	line("Cash in", report.CashIn)
+	line("Cash out", report.CashOut)
+	line("Expected cash", report.ExpectedCash)
+	line("Counted cash", report.CountedCash)
+	line("Variance", report.CashVariance)
+	b.WriteString(rule)
+	return b.String()
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"frappuccino/internal/dal"
+	"frappuccino/models"
+	"math"
+)
+
+type EmployeeService struct {
+	repo dal.EmployeeInterface
+}
+
+func NewEmployeeService(repo dal.EmployeeInterface) *EmployeeService {
+	return &EmployeeService{
+		repo: repo,
+	}
+}
+
+func (s *EmployeeService) Create(ctx context.Context, employee *models.Employee) error {
+	employee.HourlyRate = roundMoney(employee.HourlyRate)
+	return s.repo.Create(ctx, employee)
+}
+
+func (s *EmployeeService) GetByID(ctx context.Context, id int) (models.Employee, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *EmployeeService) List(ctx context.Context, includeInactive bool) ([]models.Employee, error) {
+	return s.repo.List(ctx, includeInactive)
+}
+
+func (s *EmployeeService) Update(ctx context.Context, employee models.Employee, id int) error {
+	employee.HourlyRate = roundMoney(employee.HourlyRate)
+	return s.repo.Update(ctx, employee, id)
+}
+
+func (s *EmployeeService) Deactivate(ctx context.Context, id int) error {
+	return s.repo.Deactivate(ctx, id)
+}
+
+func (s *EmployeeService) CreateShift(ctx context.Context, shift *models.Shift) error {
+	if err := s.repo.CreateShift(ctx, shift); err != nil {
+		return err
+	}
+	created, err := s.repo.GetShift(ctx, shift.ID)
+	if err != nil {
+		return err
+	}
+	*shift = created
+	return nil
+}
+
+func (s *EmployeeService) GetShift(ctx context.Context, id int) (models.Shift, error) {
+	return s.repo.GetShift(ctx, id)
+}
+
+func (s *EmployeeService) ListShifts(ctx context.Context, filter models.ShiftFilter) ([]models.Shift, error) {
+	return s.repo.ListShifts(ctx, filter)
+}
+
+func (s *EmployeeService) UpdateShift(ctx context.Context, shift models.Shift, id int) error {
+	return s.repo.UpdateShift(ctx, shift, id)
+}
+
+func (s *EmployeeService) DeleteShift(ctx context.Context, id int) error {
+	return s.repo.DeleteShift(ctx, id)
+}
+
+func (s *EmployeeService) ClockIn(ctx context.Context, employeeID int) (models.TimeEntry, error) {
+	return s.repo.ClockIn(ctx, employeeID)
+}
+
+func (s *EmployeeService) ClockOut(ctx context.Context, employeeID int) (models.TimeEntry, error) {
+	entry, err := s.repo.ClockOut(ctx, employeeID)
+	entry.Hours = roundHours(entry.Hours)
+	return entry, err
+}
+
+func (s *EmployeeService) ListTimeEntries(ctx context.Context, employeeID int, startDate, endDate *string) ([]models.TimeEntry, error) {
+	entries, err := s.repo.ListTimeEntries(ctx, employeeID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		entries[i].Hours = roundHours(entries[i].Hours)
+	}
+	return entries, nil
+}
+
+func roundHours(hours float64) float64 {
+	return math.Round(hours*100) / 100
+}
@@ -1,12 +1,11 @@
 package service
 
 import (
-	"errors"
+	"context"
 	"fmt"
 	"frappuccino/internal/dal"
 	"frappuccino/models"
-	"log"
-	"sort"
+	"math"
 )
 
 type InventoryService struct {
@@ -19,11 +18,10 @@ func NewIngredientService(repo dal.InventoryInterface) *InventoryService {
 	}
 }
 
-func (s *InventoryService) Create(ingredient *models.InventoryItem) error {
-	id, err := s.repo.Create(*ingredient)
+func (s *InventoryService) Create(ctx context.Context, ingredient *models.InventoryItem) error {
+	id, err := s.repo.Create(ctx, *ingredient)
 	if err != nil {
-		log.Default().Printf("error creating ingredient: %v\n", err)
-		return errors.New("failed to create ingredient")
+		return fmt.Errorf("failed to create ingredient: %w", err)
 	}
 
 	ingredient.IngredientID = id // Присваиваем полученный ID
@@ -31,64 +29,98 @@ func (s *InventoryService) Create(ingredient *models.InventoryItem) error {
 	return nil
 }
 
-func (s *InventoryService) GetByID(ingID int) (models.InventoryItem, error) {
-	return s.repo.GetByID(ingID)
+func (s *InventoryService) GetByID(ctx context.Context, ingID int) (models.InventoryItem, error) {
+	return s.repo.GetByID(ctx, ingID)
 }
 
-func (s *InventoryService) Update(ingredient models.InventoryItem, id int) error {
-	return s.repo.Update(ingredient, id)
+func (s *InventoryService) Update(ctx context.Context, ingredient models.InventoryItem, id int) error {
+	return s.repo.Update(ctx, ingredient, id)
 }
 
-func (s *InventoryService) Delete(ingID int) error {
-	return s.repo.Delete(ingID)
+func (s *InventoryService) Delete(ctx context.Context, ingID int) error {
+	return s.repo.Delete(ctx, ingID)
 }
 
-func (s *InventoryService) List() ([]models.InventoryItem, error) {
-	return s.repo.List()
+func (s *InventoryService) List(ctx context.Context) ([]models.InventoryItem, error) {
+	return s.repo.List(ctx)
 }
 
-func (s *InventoryService) GetLeftOvers(sortBy string, page, pageSize int) ([]models.InventoryItem, int, error) {
-	// Получаем весь инвентарь
-	allItems, err := s.repo.List()
+// ListPage returns a page of inventory items. A page past the end is empty,
+// not an error.
+func (s *InventoryService) ListPage(ctx context.Context, filter models.InventoryFilter) (models.InventoryPage, error) {
+	items, totalItems, err := s.repo.ListPage(ctx, filter)
 	if err != nil {
-		return nil, 0, err
+		return models.InventoryPage{}, err
 	}
 
-	// Фильтруем ингредиенты, у которых количество больше 0
-	var leftovers []models.InventoryItem
-	for _, item := range allItems {
-		if item.Quantity > 0 {
-			leftovers = append(leftovers, item)
-		}
+	totalPages := (totalItems + filter.PageSize - 1) / filter.PageSize
+	return models.InventoryPage{
+		Items:       items,
+		CurrentPage: filter.Page,
+		PageSize:    filter.PageSize,
+		TotalItems:  totalItems,
+		TotalPages:  totalPages,
+		HasNextPage: filter.Page < totalPages,
+	}, nil
+}
+
+// GetLeftOvers is ListPage restricted to ingredients that are still in stock.
+func (s *InventoryService) GetLeftOvers(ctx context.Context, filter models.InventoryFilter) (models.InventoryPage, error) {
+	filter.InStockOnly = true
+	return s.ListPage(ctx, filter)
+}
+
+const (
+	defaultLedgerLimit = 50
+	maxLedgerLimit     = 500
+)
+
+// ListTransactions returns a page of the inventory ledger. When the filter is
+// scoped to one ingredient the page also reports whether replaying its whole
+// ledger from zero gives the current inventory quantity.
+func (s *InventoryService) ListTransactions(ctx context.Context, filter models.TransactionFilter) (models.TransactionPage, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultLedgerLimit
+	}
+	if filter.Limit > maxLedgerLimit {
+		filter.Limit = maxLedgerLimit
 	}
 
-	// Сортировка
-	switch sortBy {
-	case "price":
-		sort.Slice(leftovers, func(i, j int) bool {
-			return leftovers[i].Price < leftovers[j].Price // Предполагается, что у InventoryItem есть поле Price
-		})
-	case "quantity":
-		sort.Slice(leftovers, func(i, j int) bool {
-			return leftovers[i].Quantity < leftovers[j].Quantity
-		})
+	var ingredient models.InventoryItem
+	if filter.IngredientID != nil {
+		var err error
+		ingredient, err = s.repo.GetByID(ctx, *filter.IngredientID)
+		if err != nil {
+			return models.TransactionPage{}, err
+		}
 	}
 
-	// Подсчет общего количества элементов
-	totalItems := len(leftovers)
-	totalPages := (totalItems + pageSize - 1) / pageSize // <-- Исправлено
+	// Fetch one extra row to know whether there is a next page
+	limit := filter.Limit
+	filter.Limit++
+	transactions, err := s.repo.ListTransactions(ctx, filter)
+	if err != nil {
+		return models.TransactionPage{}, err
+	}
 
-	// Проверка выхода за границы
-	if page < 1 || page > totalPages {
-		return []models.InventoryItem{}, totalPages, fmt.Errorf("page %d out of range", page)
+	page := models.TransactionPage{Transactions: transactions}
+	if len(transactions) > limit {
+		page.Transactions = transactions[:limit]
+		page.HasMore = true
+		next := page.Transactions[limit-1].ID
+		page.NextCursor = &next
 	}
 
-	// Пагинация
-	start := (page - 1) * pageSize
-	end := start + pageSize
-	if end > totalItems {
-		end = totalItems
+	if filter.IngredientID != nil {
+		balance, err := s.repo.LedgerBalance(ctx, *filter.IngredientID)
+		if err != nil {
+			return models.TransactionPage{}, err
+		}
+		reconciled := math.Abs(balance-ingredient.Quantity) < 1e-9
+		page.CurrentQuantity = &ingredient.Quantity
+		page.LedgerBalance = &balance
+		page.Reconciled = &reconciled
 	}
 
-	return leftovers[start:end], totalPages, nil // <-- Возвращаем totalPages вместо totalItems
+	return page, nil
 }
@@ -1,23 +1,57 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"frappuccino/internal/dal"
 	"frappuccino/models"
 )
 
 type MenuService struct {
-	repo dal.MenuInterface
+	repo          dal.MenuInterface
+	inventoryRepo dal.InventoryInterface
 }
 
-func NewMenuItemService(repo dal.MenuInterface) *MenuService {
+func NewMenuItemService(repo dal.MenuInterface, inventoryRepo dal.InventoryInterface) *MenuService {
 	return &MenuService{
-		repo: repo,
+		repo:          repo,
+		inventoryRepo: inventoryRepo,
 	}
 }
 
-func (s *MenuService) CreateMenuItem(item *models.MenuItem) error {
-	menuItemID, err := s.repo.Create(*item)
+// checkIngredients reports every ingredient of the item that isn't in the
+// inventory.
+func (s *MenuService) checkIngredients(ctx context.Context, item models.MenuItem) error {
+	ids := make([]int, len(item.Ingredients))
+	for i, ingredient := range item.Ingredients {
+		ids[i] = ingredient.IngredientID
+	}
+	existing, err := s.inventoryRepo.Existing(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	var fields []models.FieldError
+	for i, ingredient := range item.Ingredients {
+		if !existing[ingredient.IngredientID] {
+			fields = append(fields, models.FieldError{
+				Pointer: fmt.Sprintf("/ingredients/%d/ingredient_id", i),
+				Code:    models.CodeNotFound,
+				Message: fmt.Sprintf("Ingredient %d doesn't exist!", ingredient.IngredientID),
+			})
+		}
+	}
+	if len(fields) > 0 {
+		return &models.ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+func (s *MenuService) CreateMenuItem(ctx context.Context, item *models.MenuItem) error {
+	if err := s.checkIngredients(ctx, *item); err != nil {
+		return err
+	}
+	menuItemID, err := s.repo.Create(ctx, *item)
 	if err != nil {
 		return fmt.Errorf("failed to create menu item: %w", err)
 	}
@@ -25,18 +59,21 @@ func (s *MenuService) CreateMenuItem(item *models.MenuItem) error {
 	return nil
 }
 
-func (s *MenuService) GetByID(menuID int) (models.MenuItem, error) {
-	return s.repo.GetByID(menuID)
+func (s *MenuService) GetByID(ctx context.Context, menuID int) (models.MenuItem, error) {
+	return s.repo.GetByID(ctx, menuID)
 }
 
-func (s *MenuService) Update(item models.MenuItem, id int) error {
-	return s.repo.Update(item, id)
+func (s *MenuService) Update(ctx context.Context, item models.MenuItem, id int) error {
+	if err := s.checkIngredients(ctx, item); err != nil {
+		return err
+	}
+	return s.repo.Update(ctx, item, id)
 }
 
-func (s *MenuService) Delete(menuID int) error {
-	return s.repo.Delete(menuID)
+func (s *MenuService) Delete(ctx context.Context, menuID int) error {
+	return s.repo.Delete(ctx, menuID)
 }
 
-func (s *MenuService) List() ([]models.MenuItem, error) {
-	return s.repo.List()
+func (s *MenuService) List(ctx context.Context) ([]models.MenuItem, error) {
+	return s.repo.List(ctx)
 }
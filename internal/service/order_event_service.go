@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"frappuccino/internal/dal"
+	"frappuccino/models"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	orderEventBatch  = 500
+	subscriberBuffer = 64
+
+	// An event id is taken on insert but the event is seen only on commit,
+	// so ids skipped over are looked for again for a while
+	orderEventGapTimeout = time.Minute
+	orderEventMaxGaps    = 1000
+)
+
+// OrderEventBroker fans order events out to stream subscribers. Events are
+// read from the database after every notification, so each instance delivers
+// the same events in the same order no matter which instance made the change.
+type OrderEventBroker struct {
+	repo        dal.OrderEventInterface
+	mu          sync.Mutex
+	subscribers map[chan models.OrderEvent]struct{}
+	lastID      int64
+	gaps        map[int64]time.Time
+	closed      bool
+	logger      *slog.Logger
+}
+
+func NewOrderEventBroker(repo dal.OrderEventInterface, logger *slog.Logger) *OrderEventBroker {
+	return &OrderEventBroker{
+		repo:        repo,
+		logger:      logger,
+		subscribers: make(map[chan models.OrderEvent]struct{}),
+		gaps:        make(map[int64]time.Time),
+	}
+}
+
+// Run starts listening for notifications and dispatching events until ctx
+// is done.
+func (b *OrderEventBroker) Run(ctx context.Context) error {
+	lastID, err := b.repo.LatestID(ctx)
+	if err != nil {
+		return err
+	}
+	b.lastID = lastID
+
+	signals, err := b.repo.Listen(ctx)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		for range signals {
+			if err := b.dispatch(ctx); err != nil {
+				b.logger.Error("Failed to dispatch order events", slog.Any("error", err))
+			}
+		}
+	}()
+	return nil
+}
+
+// dispatch publishes the events after lastID and the ones that filled a gap
+// below it, committed after a later event was already published.
+func (b *OrderEventBroker) dispatch(ctx context.Context) error {
+	if err := b.fillGaps(ctx); err != nil {
+		return err
+	}
+
+	for {
+		events, err := b.repo.ListAfter(ctx, b.lastID, orderEventBatch)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, event := range events {
+			for id := max(b.lastID+1, event.ID-orderEventMaxGaps); id < event.ID; id++ {
+				b.gaps[id] = now
+			}
+			b.lastID = event.ID
+		}
+		b.publish(events)
+
+		if len(events) < orderEventBatch {
+			return nil
+		}
+	}
+}
+
+// fillGaps publishes the events of gaps that have since been committed. Gaps
+// left by rolled back transactions are given up after orderEventGapTimeout.
+func (b *OrderEventBroker) fillGaps(ctx context.Context) error {
+	var ids []int64
+	for id, since := range b.gaps {
+		if time.Since(since) > orderEventGapTimeout {
+			delete(b.gaps, id)
+			continue
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	events, err := b.repo.ListByIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		delete(b.gaps, event.ID)
+	}
+	b.publish(events)
+	return nil
+}
+
+func (b *OrderEventBroker) publish(events []models.OrderEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, event := range events {
+		for ch := range b.subscribers {
+			select {
+			case ch <- event:
+			default:
+				// A subscriber that can't keep up is dropped; it resumes
+				// with Last-Event-ID after reconnecting
+				delete(b.subscribers, ch)
+				close(ch)
+			}
+		}
+	}
+}
+
+// Subscribe returns a channel of live events and a function that cancels the
+// subscription. The channel is closed when the subscription ends.
+func (b *OrderEventBroker) Subscribe() (<-chan models.OrderEvent, func()) {
+	ch := make(chan models.OrderEvent, subscriberBuffer)
+
+	b.mu.Lock()
+	if b.closed {
+		close(ch)
+	} else {
+		b.subscribers[ch] = struct{}{}
+	}
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subscribers[ch]; ok {
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Close ends every subscription, and those made later, so open streams
+// finish when the server shuts down.
+func (b *OrderEventBroker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for ch := range b.subscribers {
+		delete(b.subscribers, ch)
+		close(ch)
+	}
+}
+
+// Replay returns stored events after afterID, up to the given limit.
+func (b *OrderEventBroker) Replay(ctx context.Context, afterID int64, limit int) ([]models.OrderEvent, error) {
+	events, err := b.repo.ListAfter(ctx, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to replay order events: %w", err)
+	}
+	return events, nil
+}
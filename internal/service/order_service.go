@@ -1,111 +1,380 @@
 package service
 
 import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"frappuccino/internal/dal"
 	"frappuccino/models"
+	"math"
+	"strconv"
+	"strings"
 )
 
+// 100 loyalty points are worth 1.00 at checkout.
+const loyaltyPointValue = 0.01
+
 type OrderService struct {
 	menuRepo      dal.MenuInterface
 	orderRepo     dal.OrderInterface
 	inventoryRepo dal.InventoryInterface
+	customerRepo  dal.CustomerInterface
+	promotionRepo dal.PromotionInterface
+	taxRepo       dal.TaxInterface
+	settingsRepo  dal.SettingsInterface
 }
 
-func NewOrderService(orderRepo dal.OrderInterface, inventoryRepo dal.InventoryInterface, menuRepo dal.MenuInterface) *OrderService {
+func NewOrderService(orderRepo dal.OrderInterface, inventoryRepo dal.InventoryInterface, menuRepo dal.MenuInterface,
+	customerRepo dal.CustomerInterface, promotionRepo dal.PromotionInterface, taxRepo dal.TaxInterface,
+	settingsRepo dal.SettingsInterface) *OrderService {
 	return &OrderService{
 		menuRepo:      menuRepo,
 		orderRepo:     orderRepo,
 		inventoryRepo: inventoryRepo,
+		customerRepo:  customerRepo,
+		promotionRepo: promotionRepo,
+		taxRepo:       taxRepo,
+		settingsRepo:  settingsRepo,
+	}
+}
+
+// CreateOrder prices the order and deducts the ingredients.
+func (s *OrderService) CreateOrder(ctx context.Context, order *models.Order) error {
+	if err := s.priceOrder(ctx, order); err != nil {
+		return err
+	}
+
+	// The stock is checked, deducted and the order stored in one transaction,
+	// so a failure leaves neither the order nor the deduction behind
+	tx, err := s.orderRepo.BeginTransaction(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, shortages, err := s.inventoryRepo.CheckAndReserveInventory(ctx, tx, order.Items, order.EmployeeID)
+	if err != nil {
+		return fmt.Errorf("failed to reserve inventory: %w", err)
+	}
+	if len(shortages) > 0 {
+		return &models.InsufficientStockError{Shortages: shortages}
+	}
+
+	orderID, err := s.orderRepo.CreateOrder(ctx, tx, *order)
+	if err != nil {
+		return fmt.Errorf("failed to create order: %w", err)
 	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	order.ID = orderID
+	return nil
 }
 
-func (s *OrderService) CreateOrder(order *models.Order) error {
-	requiredIngredients := make(map[int]float64)
+// priceOrder prices the order from the menu and applies promotions, redeemed
+// loyalty points and taxes.
+func (s *OrderService) priceOrder(ctx context.Context, order *models.Order) error {
+	if order.OrderType == "" {
+		order.OrderType = "dine_in"
+	}
+	var subtotal float64
+	var lines []pricedLine
 
 	for _, item := range order.Items {
-		menuItem, err := s.menuRepo.GetByID(item.ProductID)
+		menuItem, err := s.menuRepo.GetByID(ctx, item.ProductID)
 		if err != nil {
-			return fmt.Errorf("menu item not found: %d", item.ProductID)
+			return err
 		}
+		subtotal += menuItem.Price * float64(item.Quantity)
+		lines = append(lines, pricedLine{
+			menuItemID: menuItem.ID,
+			categories: menuItem.Category,
+			unitPrice:  menuItem.Price,
+			quantity:   item.Quantity,
+		})
+	}
 
-		for _, ingredient := range menuItem.Ingredients {
-			totalQuantity := ingredient.Quantity * float64(item.Quantity)
-			requiredIngredients[ingredient.IngredientID] += totalQuantity
-		}
+	order.Subtotal = roundMoney(subtotal)
+	if err := s.applyPromotions(ctx, order, lines); err != nil {
+		return err
+	}
+	order.DiscountAmount = 0
+	for _, promotion := range order.Promotions {
+		order.DiscountAmount += promotion.DiscountAmount
 	}
 
-	for ingredientID, requiredQuantity := range requiredIngredients {
-		inventoryItem, err := s.inventoryRepo.GetByID(ingredientID)
+	if order.CustomerID != nil {
+		customer, err := s.customerRepo.GetByID(ctx, *order.CustomerID)
 		if err != nil {
-			return fmt.Errorf("ingredient not found: %d", ingredientID)
+			return err
+		}
+		if order.CustomerName == "" {
+			order.CustomerName = customer.Name
 		}
-		if inventoryItem.Quantity < requiredQuantity {
-			return fmt.Errorf("insufficient ingredient: %s", inventoryItem.Name)
+		if order.RedeemPoints > customer.LoyaltyPoints {
+			return models.Conflict("insufficient loyalty points")
 		}
+		// Points beyond what is left to pay are not taken
+		if maxPoints := int(math.Round((order.Subtotal - order.DiscountAmount) / loyaltyPointValue)); order.RedeemPoints > maxPoints {
+			order.RedeemPoints = maxPoints
+		}
+		redeemed := float64(order.RedeemPoints) * loyaltyPointValue
+		allocateDiscount(lines, redeemed)
+		order.DiscountAmount += redeemed
 	}
+	order.DiscountAmount = roundMoney(order.DiscountAmount)
 
-	orderID, err := s.orderRepo.Create(order)
+	added, err := s.applyTaxes(ctx, order, lines)
 	if err != nil {
-		return fmt.Errorf("failed to create order: %w", err)
+		return err
+	}
+	order.TotalAmount = roundMoney(order.Subtotal - order.DiscountAmount + added)
+	return nil
+}
+
+// applyTaxes sets the tax breakdown of the order from what is left of its
+// lines and returns the tax to add to the total.
+func (s *OrderService) applyTaxes(ctx context.Context, order *models.Order, lines []pricedLine) (float64, error) {
+	inclusive, err := pricesIncludeTax(ctx, s.settingsRepo)
+	if err != nil {
+		return 0, err
+	}
+	rates, err := s.taxRepo.ListApplicable(ctx, order.OrderType)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load tax rates: %w", err)
+	}
+
+	var added float64
+	order.Taxes, added = calculateTaxes(lines, rates, inclusive)
+	order.TaxAmount = 0
+	for _, tax := range order.Taxes {
+		order.TaxAmount += tax.TaxAmount
+	}
+	order.TaxAmount = roundMoney(order.TaxAmount)
+	return added, nil
+}
+
+// applyPromotions sets the promotions that give the order a discount. A
+// promo code that is unknown, expired or gives nothing is an error rather
+// than being silently ignored.
+func (s *OrderService) applyPromotions(ctx context.Context, order *models.Order, lines []pricedLine) error {
+	code := strings.ToUpper(strings.TrimSpace(order.PromoCode))
+	promotions, err := s.promotionRepo.ListApplicable(ctx, code)
+	if err != nil {
+		return fmt.Errorf("failed to load promotions: %w", err)
+	}
+
+	codePromotionID := 0
+	for _, promotion := range promotions {
+		if promotion.Code != nil {
+			codePromotionID = promotion.ID
+		}
+	}
+	if code != "" && codePromotionID == 0 {
+		return models.Invalid("invalid promo code")
+	}
+
+	order.Promotions = evaluatePromotions(lines, promotions)
+	if codePromotionID != 0 {
+		for _, applied := range order.Promotions {
+			if applied.PromotionID == codePromotionID {
+				return nil
+			}
+		}
+		return models.Invalid("promo code is not applicable to this order")
+	}
+	return nil
+}
+
+func (s *OrderService) GetByID(ctx context.Context, orderID int) (models.Order, error) {
+	return s.orderRepo.GetByID(ctx, orderID)
+}
+
+// Update changes the items, instructions and priority of an order, and
+// accepts a pending one. The amounts are computed again from the items
+// rather than taken from the request. Other status changes go through the
+// queue, Close and Delete.
+func (s *OrderService) Update(ctx context.Context, order models.Order, id int) error {
+	existing, err := s.orderRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if order.Status == "" {
+		order.Status = existing.Status
+	}
+	if err := s.repriceOrder(ctx, &order, existing); err != nil {
+		return err
+	}
+	return s.orderRepo.Update(ctx, order, id)
+}
+
+// repriceOrder sets the amounts of an order whose items change. Changed
+// items are charged the current menu price and the others what they were
+// ordered at. The discount given when the order was placed is kept, up to
+// the new subtotal, and the taxes are computed again.
+func (s *OrderService) repriceOrder(ctx context.Context, order *models.Order, existing models.Order) error {
+	changed := make(map[int]bool, len(order.Items))
+	for _, item := range order.Items {
+		changed[item.ProductID] = true
+	}
+	items := append([]models.OrderItem{}, order.Items...)
+	for _, item := range existing.Items {
+		if !changed[item.ProductID] {
+			items = append(items, item)
+		}
 	}
-	order.ID = orderID
 
-	for ingredientID, requiredQuantity := range requiredIngredients {
-		inventoryItem, _ := s.inventoryRepo.GetByID(ingredientID)
-		inventoryItem.Quantity -= requiredQuantity
-		if err := s.inventoryRepo.Update(inventoryItem, ingredientID); err != nil {
-			return fmt.Errorf("failed to update ingredient quantity: %s", inventoryItem.Name)
+	var subtotal float64
+	var lines []pricedLine
+	for _, item := range items {
+		if item.Quantity == 0 {
+			continue
+		}
+		menuItem, err := s.menuRepo.GetByID(ctx, item.ProductID)
+		if err != nil {
+			return err
 		}
+		price := menuItem.Price
+		if !changed[item.ProductID] {
+			price = item.PriceAtOrder
+		}
+		subtotal += price * float64(item.Quantity)
+		lines = append(lines, pricedLine{
+			menuItemID: menuItem.ID,
+			categories: menuItem.Category,
+			unitPrice:  price,
+			quantity:   item.Quantity,
+			remaining:  price * float64(item.Quantity),
+		})
+	}
+	if len(lines) == 0 {
+		return models.Invalid("order must contain at least one item")
 	}
 
+	order.OrderType = existing.OrderType
+	order.Subtotal = roundMoney(subtotal)
+	order.DiscountAmount = math.Min(existing.DiscountAmount, order.Subtotal)
+	allocateDiscount(lines, order.DiscountAmount)
+
+	added, err := s.applyTaxes(ctx, order, lines)
+	if err != nil {
+		return err
+	}
+	order.TotalAmount = roundMoney(order.Subtotal - order.DiscountAmount + added)
 	return nil
 }
 
-func (s *OrderService) GetByID(orderID int) (models.Order, error) {
-	return s.orderRepo.GetByID(orderID)
+// Close completes an order. An order that isn't fully paid is refused
+// unless override is set.
+func (s *OrderService) Close(ctx context.Context, orderID int, override bool) error {
+	return s.orderRepo.Close(ctx, orderID, override)
 }
 
-func (s *OrderService) Update(order models.Order, id int) error {
-	return s.orderRepo.Update(order, id)
+func (s *OrderService) Delete(ctx context.Context, orderID int) error {
+	return s.orderRepo.Delete(ctx, orderID)
 }
 
-func (s *OrderService) Delete(orderID int) error {
-	return s.orderRepo.Delete(orderID)
+const (
+	defaultOrderLimit = 50
+	maxOrderLimit     = 200
+)
+
+type orderCursor struct {
+	Value string `json:"v"`
+	ID    int    `json:"id"`
 }
 
-func (s *OrderService) List() ([]models.Order, error) {
-	return s.orderRepo.List()
+// List returns a page of orders. The next cursor encodes the sort value and
+// id of the last order, so pages stay consistent while new orders arrive.
+func (s *OrderService) List(ctx context.Context, filter models.OrderFilter) (models.OrderPage, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultOrderLimit
+	}
+	if filter.Limit > maxOrderLimit {
+		filter.Limit = maxOrderLimit
+	}
+	if filter.SortBy == "" {
+		filter.SortBy = "created_at"
+	}
+
+	if filter.Cursor != "" {
+		var cursor orderCursor
+		raw, err := base64.RawURLEncoding.DecodeString(filter.Cursor)
+		if err != nil || json.Unmarshal(raw, &cursor) != nil || cursor.Value == "" {
+			return models.OrderPage{}, models.Invalid("invalid cursor")
+		}
+		filter.AfterValue, filter.AfterID = &cursor.Value, cursor.ID
+	}
+
+	limit := filter.Limit
+	filter.Limit++
+	orders, err := s.orderRepo.List(ctx, filter)
+	if err != nil {
+		return models.OrderPage{}, err
+	}
+
+	page := models.OrderPage{Orders: orders}
+	if len(orders) > limit {
+		page.Orders = orders[:limit]
+		page.HasMore = true
+
+		last := page.Orders[limit-1]
+		cursor := orderCursor{ID: last.ID, Value: last.CreatedAt.Format("2006-01-02 15:04:05.999999")}
+		if filter.SortBy == "total_amount" {
+			cursor.Value = strconv.FormatFloat(last.TotalAmount, 'f', -1, 64)
+		}
+		raw, _ := json.Marshal(cursor)
+		next := base64.RawURLEncoding.EncodeToString(raw)
+		page.NextCursor = &next
+	}
+
+	return page, nil
 }
 
-func (s *OrderService) GetOrderedItemsCount(startDate, endDate *string) (map[string]int, error) {
-	return s.orderRepo.GetOrderedItemsCount(startDate, endDate)
+func (s *OrderService) GetOrderedItemsCount(ctx context.Context, startDate, endDate *string) (map[string]int, error) {
+	return s.orderRepo.GetOrderedItemsCount(ctx, startDate, endDate)
 }
 
-func (s *OrderService) ProcessBulkOrders(orders []models.Order) (map[string]interface{}, error) {
+func (s *OrderService) ProcessBulkOrders(ctx context.Context, orders []models.Order) (map[string]interface{}, error) {
 	var processedOrders []map[string]interface{}
 	var totalRevenue float64
 	accepted, rejected := 0, 0
 	inventoryMap := make(map[int]*map[string]interface{}) // Map для агрегации обновлений
 
-	tx, err := s.orderRepo.BeginTransaction()
+	tx, err := s.orderRepo.BeginTransaction(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to start transaction: %w", err)
 	}
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		}
-	}()
+	defer tx.Rollback()
 
 	for _, order := range orders {
-		total, sufficient, updates, err := s.inventoryRepo.CheckAndReserveInventory(tx, order.Items)
+		// Each order is priced the same way as one created on its own. An
+		// order that can't be priced, such as one with an expired promo code,
+		// is rejected alone
+		if err := s.priceOrder(ctx, &order); err != nil {
+			var domainErr *models.Error
+			if !errors.As(err, &domainErr) {
+				return nil, err
+			}
+			processedOrders = append(processedOrders, map[string]interface{}{
+				"customer_name": order.CustomerName,
+				"status":        "rejected",
+				"reason":        "invalid_order",
+				"detail":        domainErr.Message,
+			})
+			rejected++
+			continue
+		}
+
+		updates, shortages, err := s.inventoryRepo.CheckAndReserveInventory(ctx, tx, order.Items, order.EmployeeID)
 		if err != nil {
-			tx.Rollback()
 			return nil, fmt.Errorf("failed to check inventory: %w", err)
 		}
 
-		if !sufficient {
+		if len(shortages) > 0 {
 			processedOrders = append(processedOrders, map[string]interface{}{
 				"customer_name": order.CustomerName,
 				"status":        "rejected",
@@ -115,9 +384,10 @@ func (s *OrderService) ProcessBulkOrders(orders []models.Order) (map[string]inte
 			continue
 		}
 
-		orderID, err := s.orderRepo.CreateOrder(tx, order, total)
+		order.Status = "accepted"
+
+		orderID, err := s.orderRepo.CreateOrder(ctx, tx, order)
 		if err != nil {
-			tx.Rollback()
 			return nil, fmt.Errorf("failed to create order: %w", err)
 		}
 
@@ -125,15 +395,15 @@ func (s *OrderService) ProcessBulkOrders(orders []models.Order) (map[string]inte
 			"order_id":      orderID,
 			"customer_name": order.CustomerName,
 			"status":        "accepted",
-			"total":         total,
+			"total":         order.TotalAmount,
 		})
-		totalRevenue += total
+		totalRevenue += order.TotalAmount
 		accepted++
 
 		// Агрегируем `inventory_updates` и корректно считаем `remaining`
 		for _, update := range updates {
 			if existing, exists := inventoryMap[update.IngredientID]; exists {
-				(*existing)["quantity_used"] = (*existing)["quantity_used"].(int) + update.QuantityUsed
+				(*existing)["quantity_used"] = (*existing)["quantity_used"].(float64) + update.QuantityUsed
 				(*existing)["remaining"] = update.Remaining // Обновляем `remaining` на последнее значение
 			} else {
 				inventoryMap[update.IngredientID] = &map[string]interface{}{
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"frappuccino/internal/dal"
+	"frappuccino/models"
+)
+
+// fakeOrderRepo implements the order queries the tests need. Any other
+// method panics through the nil embedded interface.
+type fakeOrderRepo struct {
+	dal.OrderInterface
+	orders []models.Order
+	filter models.OrderFilter
+}
+
+func (repo *fakeOrderRepo) List(ctx context.Context, filter models.OrderFilter) ([]models.Order, error) {
+	repo.filter = filter
+	if filter.Limit < len(repo.orders) {
+		return repo.orders[:filter.Limit], nil
+	}
+	return repo.orders, nil
+}
+
+func TestOrderListCursor(t *testing.T) {
+	createdAt := time.Date(2024, 3, 5, 14, 30, 0, 123456000, time.UTC)
+	repo := &fakeOrderRepo{orders: []models.Order{
+		{ID: 3, CreatedAt: createdAt, TotalAmount: 12.5},
+		{ID: 2, CreatedAt: createdAt, TotalAmount: 7.25},
+		{ID: 1, CreatedAt: createdAt, TotalAmount: 3},
+	}}
+	s := &OrderService{orderRepo: repo}
+
+	tests := []struct {
+		sortBy    string
+		wantValue string
+	}{
+		{"", "2024-03-05 14:30:00.123456"},
+		{"total_amount", "7.25"},
+	}
+	for _, tt := range tests {
+		t.Run("sort by "+tt.sortBy, func(t *testing.T) {
+			page, err := s.List(context.Background(), models.OrderFilter{SortBy: tt.sortBy, Limit: 2})
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if repo.filter.Limit != 3 {
+				t.Errorf("repository limit = %d, want one more than the page", repo.filter.Limit)
+			}
+			if len(page.Orders) != 2 || !page.HasMore || page.NextCursor == nil {
+				t.Fatalf("page = %d orders, has more %v, cursor %v; want 2 orders and a cursor", len(page.Orders), page.HasMore, page.NextCursor)
+			}
+
+			// The cursor of the page is decoded into the keyset of the next one
+			if _, err := s.List(context.Background(), models.OrderFilter{SortBy: tt.sortBy, Limit: 2, Cursor: *page.NextCursor}); err != nil {
+				t.Fatalf("List(next cursor) error = %v", err)
+			}
+			if repo.filter.AfterValue == nil || *repo.filter.AfterValue != tt.wantValue || repo.filter.AfterID != 2 {
+				t.Errorf("after = %v/%d, want %s/2", repo.filter.AfterValue, repo.filter.AfterID, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestOrderListLastPage(t *testing.T) {
+	repo := &fakeOrderRepo{orders: []models.Order{{ID: 1}}}
+	s := &OrderService{orderRepo: repo}
+
+	page, err := s.List(context.Background(), models.OrderFilter{Limit: 1000})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if page.HasMore || page.NextCursor != nil {
+		t.Errorf("page has more %v, cursor %v; want the last page", page.HasMore, page.NextCursor)
+	}
+	if repo.filter.Limit != maxOrderLimit+1 {
+		t.Errorf("repository limit = %d, want %d", repo.filter.Limit, maxOrderLimit+1)
+	}
+}
+
+func TestOrderListInvalidCursor(t *testing.T) {
+	s := &OrderService{orderRepo: &fakeOrderRepo{}}
+
+	for _, cursor := range []string{
+		"not base64!",
+		base64.RawURLEncoding.EncodeToString([]byte("not json")),
+		base64.RawURLEncoding.EncodeToString([]byte(`{"id":2}`)),
+	} {
+		_, err := s.List(context.Background(), models.OrderFilter{Cursor: cursor})
+		if !errors.Is(err, models.ErrValidation) {
+			t.Errorf("List(cursor %q) error = %v, want a validation error", cursor, err)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"frappuccino/internal/dal"
+	"frappuccino/models"
+)
+
+type PaymentService struct {
+	repo dal.PaymentInterface
+}
+
+func NewPaymentService(repo dal.PaymentInterface) *PaymentService {
+	return &PaymentService{
+		repo: repo,
+	}
+}
+
+// CreatePayment records a payment for the order. Change is worked out from
+// the tendered cash, which must cover the amount and the tip.
+func (s *PaymentService) CreatePayment(ctx context.Context, orderID int, payment *models.Payment) error {
+	payment.OrderID = orderID
+	payment.Amount = roundMoney(payment.Amount)
+	payment.Tip = roundMoney(payment.Tip)
+	payment.Change = 0
+	if payment.Tendered != nil {
+		if *payment.Tendered < payment.Amount+payment.Tip {
+			return models.Invalid("tendered cash doesn't cover the amount and tip")
+		}
+		payment.Change = roundMoney(*payment.Tendered - payment.Amount - payment.Tip)
+	}
+	return s.repo.CreatePayment(ctx, payment)
+}
+
+func (s *PaymentService) CreateRefund(ctx context.Context, orderID int, refund *models.Refund) error {
+	refund.OrderID = orderID
+	refund.Amount = roundMoney(refund.Amount)
+	return s.repo.CreateRefund(ctx, refund)
+}
+
+func (s *PaymentService) GetBalance(ctx context.Context, orderID int) (models.OrderBalance, error) {
+	balance, err := s.repo.GetBalance(ctx, orderID)
+	if err != nil {
+		return models.OrderBalance{}, err
+	}
+
+	balance.Paid = roundMoney(balance.Paid)
+	balance.Refunded = roundMoney(balance.Refunded)
+	balance.Tips = roundMoney(balance.Tips)
+	balance.BalanceDue = roundMoney(balance.BalanceDue)
+	return balance, nil
+}
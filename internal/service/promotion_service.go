@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"frappuccino/internal/dal"
+	"frappuccino/models"
+	"math"
+	"sort"
+	"strings"
+)
+
+type PromotionService struct {
+	repo dal.PromotionInterface
+}
+
+func NewPromotionService(repo dal.PromotionInterface) *PromotionService {
+	return &PromotionService{
+		repo: repo,
+	}
+}
+
+func (s *PromotionService) Create(ctx context.Context, promotion *models.Promotion) error {
+	normalizePromoCode(promotion)
+	if err := s.repo.Create(ctx, promotion); err != nil {
+		return err
+	}
+	created, err := s.repo.GetByID(ctx, promotion.ID)
+	if err != nil {
+		return err
+	}
+	*promotion = created
+	return nil
+}
+
+func (s *PromotionService) GetByID(ctx context.Context, id int) (models.Promotion, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *PromotionService) List(ctx context.Context) ([]models.Promotion, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *PromotionService) Update(ctx context.Context, promotion models.Promotion, id int) error {
+	normalizePromoCode(&promotion)
+	return s.repo.Update(ctx, promotion, id)
+}
+
+func (s *PromotionService) Deactivate(ctx context.Context, id int) error {
+	return s.repo.Deactivate(ctx, id)
+}
+
+// Promo codes are matched case-insensitively by storing them upper-cased.
+func normalizePromoCode(promotion *models.Promotion) {
+	if promotion.Code == nil {
+		return
+	}
+	code := strings.ToUpper(strings.TrimSpace(*promotion.Code))
+	if code == "" {
+		promotion.Code = nil
+		return
+	}
+	promotion.Code = &code
+}
+
+// pricedLine is an order line being priced. Remaining is the part of the
+// line amount that hasn't been discounted yet.
+type pricedLine struct {
+	menuItemID int
+	categories []string
+	unitPrice  float64
+	quantity   int
+	remaining  float64
+}
+
+// evaluatePromotions applies the promotions one after another. Each one
+// discounts only what the previous ones left, so a line never goes below
+// zero however many rules match it.
+func evaluatePromotions(lines []pricedLine, promotions []models.Promotion) []models.OrderPromotion {
+	var subtotal float64
+	for i := range lines {
+		lines[i].remaining = lines[i].unitPrice * float64(lines[i].quantity)
+		subtotal += lines[i].remaining
+	}
+
+	applied := []models.OrderPromotion{}
+	for _, promotion := range promotions {
+		if subtotal < promotion.MinSubtotal {
+			continue
+		}
+
+		var eligible []*pricedLine
+		for i := range lines {
+			if promotionMatches(promotion, lines[i]) {
+				eligible = append(eligible, &lines[i])
+			}
+		}
+
+		var discount float64
+		switch promotion.Type {
+		case "percentage":
+			for _, line := range eligible {
+				d := line.remaining * promotion.Value / 100
+				line.remaining -= d
+				discount += d
+			}
+		case "fixed":
+			left := promotion.Value
+			for _, line := range eligible {
+				d := math.Min(left, line.remaining)
+				line.remaining -= d
+				left -= d
+				discount += d
+			}
+		case "buy_x_get_y":
+			discount = buyXGetYDiscount(promotion, eligible)
+		}
+
+		discount = roundMoney(discount)
+		if discount > 0 {
+			applied = append(applied, models.OrderPromotion{
+				PromotionID:    promotion.ID,
+				Name:           promotion.Name,
+				DiscountAmount: discount,
+			})
+		}
+	}
+	return applied
+}
+
+// buyXGetYDiscount discounts the cheapest units: out of every buy+get
+// eligible units, get units are Value percent off.
+func buyXGetYDiscount(promotion models.Promotion, eligible []*pricedLine) float64 {
+	if promotion.BuyQuantity == nil || promotion.GetQuantity == nil {
+		return 0
+	}
+
+	type unit struct {
+		price float64
+		line  *pricedLine
+	}
+	var units []unit
+	for _, line := range eligible {
+		for i := 0; i < line.quantity; i++ {
+			units = append(units, unit{price: line.unitPrice, line: line})
+		}
+	}
+	sort.SliceStable(units, func(i, j int) bool { return units[i].price < units[j].price })
+
+	free := len(units) / (*promotion.BuyQuantity + *promotion.GetQuantity) * *promotion.GetQuantity
+	var discount float64
+	for _, u := range units[:free] {
+		d := math.Min(u.price*promotion.Value/100, u.line.remaining)
+		u.line.remaining -= d
+		discount += d
+	}
+	return discount
+}
+
+func promotionMatches(promotion models.Promotion, line pricedLine) bool {
+	if promotion.MenuItemID != nil && *promotion.MenuItemID != line.menuItemID {
+		return false
+	}
+	if promotion.Category != nil {
+		for _, category := range line.categories {
+			if strings.EqualFold(category, *promotion.Category) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"frappuccino/models"
+)
+
+func TestEvaluatePromotions(t *testing.T) {
+	pastry := "pastry"
+	latte := 1
+	two, one := 2, 1
+
+	tests := []struct {
+		name       string
+		lines      []pricedLine
+		promotions []models.Promotion
+		want       []models.OrderPromotion
+		remaining  []float64
+	}{
+		{
+			name: "percentage off every line",
+			lines: []pricedLine{
+				{menuItemID: 1, categories: []string{"coffee"}, unitPrice: 4.5, quantity: 2},
+				{menuItemID: 2, categories: []string{"pastry"}, unitPrice: 3, quantity: 1},
+			},
+			promotions: []models.Promotion{{ID: 1, Name: "10% off", Type: "percentage", Value: 10}},
+			want:       []models.OrderPromotion{{PromotionID: 1, Name: "10% off", DiscountAmount: 1.2}},
+			remaining:  []float64{8.1, 2.7},
+		},
+		{
+			name: "fixed amount capped at the eligible lines",
+			lines: []pricedLine{
+				{menuItemID: 1, categories: []string{"coffee"}, unitPrice: 4.5, quantity: 2},
+				{menuItemID: 2, categories: []string{"Pastry"}, unitPrice: 3, quantity: 1},
+			},
+			promotions: []models.Promotion{{ID: 2, Name: "Pastry 5 off", Type: "fixed", Value: 5, Category: &pastry}},
+			want:       []models.OrderPromotion{{PromotionID: 2, Name: "Pastry 5 off", DiscountAmount: 3}},
+			remaining:  []float64{9, 0},
+		},
+		{
+			name: "buy two get one free discounts the cheapest unit",
+			lines: []pricedLine{
+				{menuItemID: 1, unitPrice: 4.5, quantity: 2},
+				{menuItemID: 3, unitPrice: 2, quantity: 1},
+			},
+			promotions: []models.Promotion{{ID: 3, Name: "3 for 2", Type: "buy_x_get_y", Value: 100, BuyQuantity: &two, GetQuantity: &one}},
+			want:       []models.OrderPromotion{{PromotionID: 3, Name: "3 for 2", DiscountAmount: 2}},
+			remaining:  []float64{9, 0},
+		},
+		{
+			name: "menu item restriction",
+			lines: []pricedLine{
+				{menuItemID: 1, unitPrice: 4, quantity: 1},
+				{menuItemID: 2, unitPrice: 6, quantity: 1},
+			},
+			promotions: []models.Promotion{{ID: 4, Name: "Latte half price", Type: "percentage", Value: 50, MenuItemID: &latte}},
+			want:       []models.OrderPromotion{{PromotionID: 4, Name: "Latte half price", DiscountAmount: 2}},
+			remaining:  []float64{2, 6},
+		},
+		{
+			name:       "minimum subtotal not reached",
+			lines:      []pricedLine{{menuItemID: 1, unitPrice: 4, quantity: 1}},
+			promotions: []models.Promotion{{ID: 5, Name: "Big order", Type: "fixed", Value: 2, MinSubtotal: 20}},
+			want:       []models.OrderPromotion{},
+			remaining:  []float64{4},
+		},
+		{
+			name:  "stacked promotions never go below zero",
+			lines: []pricedLine{{menuItemID: 1, unitPrice: 10, quantity: 1}},
+			promotions: []models.Promotion{
+				{ID: 6, Name: "Half price", Type: "percentage", Value: 50},
+				{ID: 7, Name: "10 off", Type: "fixed", Value: 10},
+				{ID: 8, Name: "Another 10%", Type: "percentage", Value: 10},
+			},
+			want: []models.OrderPromotion{
+				{PromotionID: 6, Name: "Half price", DiscountAmount: 5},
+				{PromotionID: 7, Name: "10 off", DiscountAmount: 5},
+			},
+			remaining: []float64{0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := evaluatePromotions(tt.lines, tt.promotions)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("evaluatePromotions() = %+v, want %+v", got, tt.want)
+			}
+			for i, line := range tt.lines {
+				if !moneyEqual(line.remaining, tt.remaining[i]) {
+					t.Errorf("line %d remaining = %v, want %v", i, line.remaining, tt.remaining[i])
+				}
+			}
+		})
+	}
+}
+
+func moneyEqual(a, b float64) bool {
+	return math.Abs(a-b) < 0.005
+}
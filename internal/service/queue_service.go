@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+	"frappuccino/internal/dal"
+	"frappuccino/models"
+)
+
+type QueueService struct {
+	repo dal.QueueInterface
+}
+
+func NewQueueService(repo dal.QueueInterface) *QueueService {
+	return &QueueService{
+		repo: repo,
+	}
+}
+
+func (s *QueueService) List(ctx context.Context, station string) ([]models.QueueOrder, error) {
+	return s.repo.ListActive(ctx, station)
+}
+
+func (s *QueueService) Claim(ctx context.Context, orderID int, claim models.QueueClaim) error {
+	return s.repo.Claim(ctx, orderID, claim)
+}
+
+func (s *QueueService) CompleteItem(ctx context.Context, orderID, orderItemID int) error {
+	return s.repo.CompleteItem(ctx, orderID, orderItemID)
+}
+
+func (s *QueueService) Bump(ctx context.Context, orderID int) error {
+	return s.repo.Bump(ctx, orderID)
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"frappuccino/internal/auth"
+	"frappuccino/internal/dal"
+	"frappuccino/models"
+	"log/slog"
+	"sync"
+)
+
+// RBACService answers whether a role has a permission. The mapping is kept
+// in memory and reloaded whenever it changes, on this instance or another.
+type RBACService struct {
+	repo   dal.RoleInterface
+	logger *slog.Logger
+
+	mu          sync.RWMutex
+	permissions map[string]map[string]bool
+}
+
+func NewRBACService(ctx context.Context, repo dal.RoleInterface, logger *slog.Logger) (*RBACService, error) {
+	s := &RBACService{repo: repo, logger: logger}
+	if err := s.load(ctx); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Run reloads the mapping after every change notified by the database until
+// ctx is done.
+func (s *RBACService) Run(ctx context.Context) error {
+	signals, err := s.repo.Listen(ctx)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		for range signals {
+			if err := s.load(ctx); err != nil {
+				s.logger.Error("Failed to reload role permissions", slog.Any("error", err))
+			}
+		}
+	}()
+	return nil
+}
+
+func (s *RBACService) load(ctx context.Context) error {
+	mapping, err := s.repo.ListPermissions(ctx)
+	if err != nil {
+		return err
+	}
+	permissions := make(map[string]map[string]bool, len(mapping))
+	for role, names := range mapping {
+		permissions[role] = make(map[string]bool, len(names))
+		for _, name := range names {
+			permissions[role][name] = true
+		}
+	}
+
+	s.mu.Lock()
+	s.permissions = permissions
+	s.mu.Unlock()
+	return nil
+}
+
+// Allowed reports whether role has permission. The owner has them all.
+func (s *RBACService) Allowed(role, permission string) bool {
+	if role == auth.OwnerRole {
+		return true
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.permissions[role][permission]
+}
+
+func (s *RBACService) List() []models.RolePermissions {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	roles := []models.RolePermissions{}
+	for _, role := range auth.Roles {
+		entry := models.RolePermissions{Role: role, Permissions: []string{}}
+		for _, permission := range auth.Permissions {
+			if role == auth.OwnerRole || s.permissions[role][permission] {
+				entry.Permissions = append(entry.Permissions, permission)
+			}
+		}
+		roles = append(roles, entry)
+	}
+	return roles
+}
+
+func (s *RBACService) Update(ctx context.Context, role string, permissions []string) error {
+	if err := s.repo.SetPermissions(ctx, role, permissions); err != nil {
+		return err
+	}
+	return s.load(ctx)
+}
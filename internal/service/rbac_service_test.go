@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+type fakeRoleRepo struct {
+	permissions map[string][]string
+	signals     chan struct{}
+}
+
+func (repo *fakeRoleRepo) ListPermissions(ctx context.Context) (map[string][]string, error) {
+	return repo.permissions, nil
+}
+
+func (repo *fakeRoleRepo) SetPermissions(ctx context.Context, role string, permissions []string) error {
+	repo.permissions[role] = permissions
+	return nil
+}
+
+func (repo *fakeRoleRepo) Listen(ctx context.Context) (<-chan struct{}, error) {
+	return repo.signals, nil
+}
+
+func TestRBACServiceReloadsOnNotification(t *testing.T) {
+	repo := &fakeRoleRepo{
+		permissions: map[string][]string{"barista": {"orders.read"}},
+		signals:     make(chan struct{}),
+	}
+	s, err := NewRBACService(context.Background(), repo, slog.Default())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := s.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// Another instance grants the permission
+	repo.permissions = map[string][]string{"barista": {"orders.read", "orders.delete"}}
+	repo.signals <- struct{}{}
+
+	deadline := time.Now().Add(time.Second)
+	for !s.Allowed("barista", "orders.delete") {
+		if time.Now().After(deadline) {
+			t.Fatal("permission not reloaded after a notification")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(repo.signals)
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"frappuccino/internal/dal"
+	"frappuccino/internal/receipt"
+	"strings"
+)
+
+const (
+	receiptHeaderKey = "receipt_header"
+	receiptFooterKey = "receipt_footer"
+)
+
+type ReceiptService struct {
+	orderRepo    dal.OrderInterface
+	paymentRepo  dal.PaymentInterface
+	settingsRepo dal.SettingsInterface
+}
+
+func NewReceiptService(orderRepo dal.OrderInterface, paymentRepo dal.PaymentInterface, settingsRepo dal.SettingsInterface) *ReceiptService {
+	return &ReceiptService{
+		orderRepo:    orderRepo,
+		paymentRepo:  paymentRepo,
+		settingsRepo: settingsRepo,
+	}
+}
+
+// Render builds the receipt of an order, or its kitchen ticket, and renders
+// it in the given format. It returns the content type with the bytes.
+func (s *ReceiptService) Render(ctx context.Context, orderID int, format string, kitchen bool) ([]byte, string, error) {
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, "", err
+	}
+	balance, err := s.paymentRepo.GetBalance(ctx, orderID)
+	if err != nil {
+		return nil, "", err
+	}
+	settings, err := s.settingsRepo.List(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	r := receipt.Receipt{
+		Header:              settingLines(settings[receiptHeaderKey]),
+		Footer:              settingLines(settings[receiptFooterKey]),
+		OrderID:             order.ID,
+		CreatedAt:           order.CreatedAt,
+		CustomerName:        order.CustomerName,
+		OrderType:           order.OrderType,
+		Kitchen:             kitchen,
+		SpecialInstructions: order.SpecialInstructions,
+		Subtotal:            order.Subtotal,
+		Total:               order.TotalAmount,
+		// Tax that wasn't added on top of the discounted subtotal was
+		// included in the prices when the order was taken
+		TaxInclusive: order.TaxAmount > 0 && roundMoney(order.Subtotal-order.DiscountAmount) == order.TotalAmount,
+		Refunded:     roundMoney(balance.Refunded),
+		BalanceDue:   roundMoney(balance.BalanceDue),
+	}
+
+	for _, item := range order.Items {
+		r.Lines = append(r.Lines, receipt.Line{
+			Name:      item.Name,
+			Quantity:  item.Quantity,
+			UnitPrice: item.PriceAtOrder,
+			Modifiers: item.Modifiers,
+		})
+	}
+
+	promotionDiscount := 0.0
+	for _, promotion := range order.Promotions {
+		r.Discounts = append(r.Discounts, receipt.Amount{Label: promotion.Name, Amount: promotion.DiscountAmount})
+		promotionDiscount += promotion.DiscountAmount
+	}
+	if loyalty := roundMoney(order.DiscountAmount - promotionDiscount); loyalty > 0 {
+		r.Discounts = append(r.Discounts, receipt.Amount{Label: "Loyalty points", Amount: loyalty})
+	}
+
+	// The order keeps its tax per line, the receipt shows it per rate
+	taxIndex := make(map[string]int)
+	for _, tax := range order.Taxes {
+		label := fmt.Sprintf("%s %g%%", tax.Name, tax.Rate)
+		i, ok := taxIndex[label]
+		if !ok {
+			i = len(r.Taxes)
+			taxIndex[label] = i
+			r.Taxes = append(r.Taxes, receipt.Amount{Label: label})
+		}
+		r.Taxes[i].Amount = roundMoney(r.Taxes[i].Amount + tax.TaxAmount)
+	}
+
+	for _, payment := range balance.Payments {
+		r.Payments = append(r.Payments, receipt.Payment{
+			Method:   payment.Method,
+			Amount:   payment.Amount,
+			Tip:      payment.Tip,
+			Tendered: payment.Tendered,
+			Change:   payment.Change,
+		})
+	}
+
+	return receipt.Render(r, format)
+}
+
+// settingLines splits a multi-line setting, leaving out empty lines.
+func settingLines(value string) []string {
+	var lines []string
+	for _, line := range strings.Split(value, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
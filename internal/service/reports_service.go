@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"frappuccino/internal/dal"
+	"frappuccino/models"
+	"math"
+	"time"
 )
 
 type ReportService struct {
@@ -36,7 +39,7 @@ func (s *ReportService) GetPopularItems(ctx context.Context) ([]string, error) {
 
 func (s *ReportService) GetOrderedItemsByPeriod(ctx context.Context, period string, month string, year string) (map[string]int, error) {
 	if period != "day" && period != "month" {
-		return nil, fmt.Errorf("invalid period: must be 'day' or 'month'")
+		return nil, models.Invalid("invalid period: must be 'day' or 'month'")
 	}
 
 	orders, err := s.repo.GetOrderedItems(ctx)
@@ -49,7 +52,7 @@ func (s *ReportService) GetOrderedItemsByPeriod(ctx context.Context, period stri
 	for _, order := range orders {
 		if period == "day" {
 			if month == "" {
-				return nil, fmt.Errorf("month is required when period is 'day'")
+				return nil, models.Invalid("month is required when period is 'day'")
 			}
 			orderMonth := order.CreatedAt.Month().String()
 			if orderMonth == month {
@@ -58,7 +61,7 @@ func (s *ReportService) GetOrderedItemsByPeriod(ctx context.Context, period stri
 			}
 		} else if period == "month" {
 			if year == "" {
-				return nil, fmt.Errorf("year is required when period is 'month'")
+				return nil, models.Invalid("year is required when period is 'month'")
 			}
 			orderYear := order.CreatedAt.Format("2006")
 			if orderYear == year {
@@ -74,3 +77,126 @@ func (s *ReportService) GetOrderedItemsByPeriod(ctx context.Context, period stri
 func (s *ReportService) Search(ctx context.Context, query string, filters []string, minPrice, maxPrice float64) (map[string]interface{}, error) {
 	return s.repo.FullTextSearch(ctx, query, filters, minPrice, maxPrice)
 }
+
+// GetInventoryValuation values stock at the given date (today when nil).
+// "weighted_average" costs stock with the average unit cost of all costed
+// purchases up to that date and falls back to the ingredient cost when none
+// are recorded; "current" always uses the ingredient cost.
+func (s *ReportService) GetInventoryValuation(ctx context.Context, date *string, costMethod string) (models.InventoryValuation, error) {
+	items, err := s.repo.GetInventoryValuation(ctx, date)
+	if err != nil {
+		return models.InventoryValuation{}, fmt.Errorf("could not get inventory valuation: %w", err)
+	}
+
+	valuation := models.InventoryValuation{
+		AsOf:       time.Now().Format("2006-01-02"),
+		CostMethod: costMethod,
+		Items:      items,
+	}
+	if date != nil {
+		valuation.AsOf = *date
+	}
+
+	for i := range valuation.Items {
+		item := &valuation.Items[i]
+		if costMethod == "weighted_average" && item.PurchasedQuantity > 0 {
+			item.UnitCost = item.PurchasedValue / item.PurchasedQuantity
+		}
+		item.Value = roundMoney(item.Quantity * item.UnitCost)
+		valuation.TotalValue += item.Value
+	}
+	valuation.TotalValue = roundMoney(valuation.TotalValue)
+
+	return valuation, nil
+}
+
+func (s *ReportService) GetIngredientUsage(ctx context.Context, startDate, endDate *string) (models.IngredientUsageReport, error) {
+	items, err := s.repo.GetIngredientUsage(ctx, startDate, endDate)
+	if err != nil {
+		return models.IngredientUsageReport{}, fmt.Errorf("could not get ingredient usage: %w", err)
+	}
+
+	report := models.IngredientUsageReport{StartDate: startDate, EndDate: endDate, Items: items}
+	for i := range report.Items {
+		item := &report.Items[i]
+		item.ActualUsage = item.OrderUsage + item.CountCorrections
+		item.Variance = item.ActualUsage - item.TheoreticalUsage
+		item.VarianceValue = roundMoney(item.Variance * item.UnitCost)
+		if item.TheoreticalUsage != 0 {
+			percent := math.Round(item.Variance/item.TheoreticalUsage*10000) / 100
+			item.VariancePercent = &percent
+		}
+		report.TotalVarianceValue += item.VarianceValue
+	}
+	report.TotalVarianceValue = roundMoney(report.TotalVarianceValue)
+
+	return report, nil
+}
+
+func (s *ReportService) GetSalesSummary(ctx context.Context, startDate, endDate *string) (models.SalesSummary, error) {
+	summary, err := s.repo.GetSalesSummary(ctx, startDate, endDate)
+	if err != nil {
+		return models.SalesSummary{}, fmt.Errorf("could not get sales summary: %w", err)
+	}
+
+	summary.GrossSales = roundMoney(summary.GrossSales)
+	summary.PromotionDiscounts = roundMoney(summary.PromotionDiscounts)
+	summary.TotalDiscounts = roundMoney(summary.TotalDiscounts)
+	summary.LoyaltyDiscounts = roundMoney(summary.TotalDiscounts - summary.PromotionDiscounts)
+	summary.NetSales = roundMoney(summary.GrossSales - summary.TotalDiscounts)
+	summary.Tax = roundMoney(summary.Tax)
+	summary.TotalCharged = roundMoney(summary.TotalCharged)
+	for i := range summary.Promotions {
+		summary.Promotions[i].DiscountAmount = roundMoney(summary.Promotions[i].DiscountAmount)
+	}
+
+	return summary, nil
+}
+
+func (s *ReportService) GetTaxReport(ctx context.Context, startDate, endDate *string) (models.TaxReport, error) {
+	report, err := s.repo.GetTaxReport(ctx, startDate, endDate)
+	if err != nil {
+		return models.TaxReport{}, fmt.Errorf("could not get tax report: %w", err)
+	}
+
+	for i := range report.Rates {
+		line := &report.Rates[i]
+		line.TaxableAmount = roundMoney(line.TaxableAmount)
+		line.TaxAmount = roundMoney(line.TaxAmount)
+		report.TotalTaxable += line.TaxableAmount
+		report.TotalTax += line.TaxAmount
+	}
+	report.TotalTaxable = roundMoney(report.TotalTaxable)
+	report.TotalTax = roundMoney(report.TotalTax)
+
+	return report, nil
+}
+
+// GetLaborReport prices the worked hours at the current hourly rates and
+// compares them with net sales.
+func (s *ReportService) GetLaborReport(ctx context.Context, startDate, endDate *string) (models.LaborReport, error) {
+	report, err := s.repo.GetLaborReport(ctx, startDate, endDate)
+	if err != nil {
+		return models.LaborReport{}, fmt.Errorf("could not get labor report: %w", err)
+	}
+
+	for i := range report.Employees {
+		line := &report.Employees[i]
+		line.LaborCost = roundMoney(line.WorkedHours * line.HourlyRate)
+		line.ScheduledHours = roundHours(line.ScheduledHours)
+		line.WorkedHours = roundHours(line.WorkedHours)
+		report.ScheduledHours += line.ScheduledHours
+		report.WorkedHours += line.WorkedHours
+		report.LaborCost += line.LaborCost
+	}
+	report.ScheduledHours = roundHours(report.ScheduledHours)
+	report.WorkedHours = roundHours(report.WorkedHours)
+	report.LaborCost = roundMoney(report.LaborCost)
+	report.NetSales = roundMoney(report.NetSales)
+	if report.NetSales > 0 {
+		percent := roundMoney(report.LaborCost / report.NetSales * 100)
+		report.LaborPercent = &percent
+	}
+
+	return report, nil
+}
@@ -0,0 +1,27 @@
+package service
+
+import (
+	"context"
+	"frappuccino/internal/dal"
+)
+
+type SettingsService struct {
+	repo dal.SettingsInterface
+}
+
+func NewSettingsService(repo dal.SettingsInterface) *SettingsService {
+	return &SettingsService{
+		repo: repo,
+	}
+}
+
+func (s *SettingsService) List(ctx context.Context) (map[string]string, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *SettingsService) Update(ctx context.Context, values map[string]string) (map[string]string, error) {
+	if err := s.repo.Set(ctx, values); err != nil {
+		return nil, err
+	}
+	return s.repo.List(ctx)
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"frappuccino/internal/dal"
+	"frappuccino/models"
+	"math"
+)
+
+type StockCountService struct {
+	repo dal.StockCountInterface
+}
+
+func NewStockCountService(repo dal.StockCountInterface) *StockCountService {
+	return &StockCountService{
+		repo: repo,
+	}
+}
+
+func (s *StockCountService) Open(ctx context.Context, count *models.StockCount) error {
+	countID, err := s.repo.Create(ctx, count.Note)
+	if err != nil {
+		return fmt.Errorf("failed to open stock count: %w", err)
+	}
+	opened, err := s.repo.GetByID(ctx, countID)
+	if err != nil {
+		return err
+	}
+	*count = opened
+	return nil
+}
+
+func (s *StockCountService) List(ctx context.Context) ([]models.StockCount, error) {
+	return s.repo.List(ctx)
+}
+
+// GetByID returns the session with the variance of every counted ingredient
+// in units and in money.
+func (s *StockCountService) GetByID(ctx context.Context, countID int) (models.StockCount, error) {
+	count, err := s.repo.GetByID(ctx, countID)
+	if err != nil {
+		return models.StockCount{}, err
+	}
+
+	for i := range count.Lines {
+		line := &count.Lines[i]
+		line.Variance = line.CountedQuantity - line.SystemQuantity
+		line.VarianceValue = roundMoney(line.Variance * line.UnitCost)
+		count.TotalVarianceValue += line.VarianceValue
+	}
+	count.TotalVarianceValue = roundMoney(count.TotalVarianceValue)
+
+	return count, nil
+}
+
+func (s *StockCountService) SubmitLines(ctx context.Context, countID int, lines []models.StockCountSubmission) error {
+	return s.repo.SubmitLines(ctx, countID, lines)
+}
+
+func (s *StockCountService) Finalize(ctx context.Context, countID int, employeeID *int) (models.StockCount, error) {
+	if err := s.repo.Finalize(ctx, countID, employeeID); err != nil {
+		return models.StockCount{}, err
+	}
+	return s.GetByID(ctx, countID)
+}
+
+func roundMoney(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"frappuccino/internal/dal"
+	"frappuccino/models"
+	"strings"
+)
+
+const taxInclusivePricingKey = "tax_inclusive_pricing"
+
+type TaxService struct {
+	repo dal.TaxInterface
+}
+
+func NewTaxService(repo dal.TaxInterface) *TaxService {
+	return &TaxService{
+		repo: repo,
+	}
+}
+
+func (s *TaxService) Create(ctx context.Context, rate *models.TaxRate) error {
+	return s.repo.Create(ctx, rate)
+}
+
+func (s *TaxService) GetByID(ctx context.Context, id int) (models.TaxRate, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *TaxService) List(ctx context.Context) ([]models.TaxRate, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *TaxService) Update(ctx context.Context, rate models.TaxRate, id int) error {
+	return s.repo.Update(ctx, rate, id)
+}
+
+func (s *TaxService) Deactivate(ctx context.Context, id int) error {
+	return s.repo.Deactivate(ctx, id)
+}
+
+// pricesIncludeTax reads the pricing mode; prices exclude tax unless the
+// setting says otherwise.
+func pricesIncludeTax(ctx context.Context, settingsRepo dal.SettingsInterface) (bool, error) {
+	value, err := settingsRepo.Get(ctx, taxInclusivePricingKey)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return value == "true", nil
+}
+
+// calculateTaxes taxes what is left of every line after discounts. With
+// tax-inclusive prices the tax is extracted from the line amount, so the
+// order total doesn't change; otherwise it is added on top. It returns the
+// taxes and the amount to add to the total.
+func calculateTaxes(lines []pricedLine, rates []models.TaxRate, inclusive bool) ([]models.OrderTax, float64) {
+	taxes := []models.OrderTax{}
+	var added float64
+	for _, line := range lines {
+		var matching []models.TaxRate
+		var combined float64
+		for _, rate := range rates {
+			if taxRateMatches(rate, line) {
+				matching = append(matching, rate)
+				combined += rate.Rate
+			}
+		}
+		if len(matching) == 0 {
+			continue
+		}
+
+		taxable := line.remaining
+		if inclusive {
+			taxable = line.remaining / (1 + combined/100)
+		}
+		for _, rate := range matching {
+			tax := roundMoney(taxable * rate.Rate / 100)
+			taxes = append(taxes, models.OrderTax{
+				ProductID:     line.menuItemID,
+				TaxRateID:     rate.ID,
+				Name:          rate.Name,
+				Rate:          rate.Rate,
+				TaxableAmount: roundMoney(taxable),
+				TaxAmount:     tax,
+			})
+			if !inclusive {
+				added += tax
+			}
+		}
+	}
+	return taxes, roundMoney(added)
+}
+
+func taxRateMatches(rate models.TaxRate, line pricedLine) bool {
+	if rate.Category == nil {
+		return true
+	}
+	for _, category := range line.categories {
+		if strings.EqualFold(category, *rate.Category) {
+			return true
+		}
+	}
+	return false
+}
+
+// allocateDiscount spreads an order-level discount over the lines in
+// proportion to what is left of them, so tax is charged on what the
+// customer actually pays.
+func allocateDiscount(lines []pricedLine, discount float64) {
+	var total float64
+	for _, line := range lines {
+		total += line.remaining
+	}
+	if total <= 0 || discount <= 0 {
+		return
+	}
+	for i := range lines {
+		lines[i].remaining -= discount * lines[i].remaining / total
+	}
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"frappuccino/models"
+)
+
+func TestCalculateTaxes(t *testing.T) {
+	drinks := "drinks"
+	vat := models.TaxRate{ID: 1, Name: "VAT", Rate: 10}
+	sugar := models.TaxRate{ID: 2, Name: "Sugar tax", Rate: 5, Category: &drinks}
+
+	tests := []struct {
+		name      string
+		lines     []pricedLine
+		rates     []models.TaxRate
+		inclusive bool
+		want      []models.OrderTax
+		wantAdded float64
+	}{
+		{
+			name: "exclusive prices add the tax on top",
+			lines: []pricedLine{
+				{menuItemID: 1, categories: []string{"Drinks"}, remaining: 10},
+				{menuItemID: 2, categories: []string{"pastry"}, remaining: 4},
+			},
+			rates: []models.TaxRate{vat, sugar},
+			want: []models.OrderTax{
+				{ProductID: 1, TaxRateID: 1, Name: "VAT", Rate: 10, TaxableAmount: 10, TaxAmount: 1},
+				{ProductID: 1, TaxRateID: 2, Name: "Sugar tax", Rate: 5, TaxableAmount: 10, TaxAmount: 0.5},
+				{ProductID: 2, TaxRateID: 1, Name: "VAT", Rate: 10, TaxableAmount: 4, TaxAmount: 0.4},
+			},
+			wantAdded: 1.9,
+		},
+		{
+			name:      "inclusive prices extract the tax",
+			lines:     []pricedLine{{menuItemID: 1, categories: []string{"drinks"}, remaining: 11.5}},
+			rates:     []models.TaxRate{vat, sugar},
+			inclusive: true,
+			want: []models.OrderTax{
+				{ProductID: 1, TaxRateID: 1, Name: "VAT", Rate: 10, TaxableAmount: 10, TaxAmount: 1},
+				{ProductID: 1, TaxRateID: 2, Name: "Sugar tax", Rate: 5, TaxableAmount: 10, TaxAmount: 0.5},
+			},
+			wantAdded: 0,
+		},
+		{
+			name:  "lines without a matching rate aren't taxed",
+			lines: []pricedLine{{menuItemID: 2, categories: []string{"pastry"}, remaining: 4}},
+			rates: []models.TaxRate{sugar},
+			want:  []models.OrderTax{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, added := calculateTaxes(tt.lines, tt.rates, tt.inclusive)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("calculateTaxes() taxes = %+v, want %+v", got, tt.want)
+			}
+			if added != tt.wantAdded {
+				t.Errorf("calculateTaxes() added = %v, want %v", added, tt.wantAdded)
+			}
+		})
+	}
+}
+
+func TestAllocateDiscount(t *testing.T) {
+	tests := []struct {
+		name     string
+		lines    []pricedLine
+		discount float64
+		want     []float64
+	}{
+		{"proportional", []pricedLine{{remaining: 30}, {remaining: 10}}, 4, []float64{27, 9}},
+		{"no discount", []pricedLine{{remaining: 30}, {remaining: 10}}, 0, []float64{30, 10}},
+		{"nothing left to discount", []pricedLine{{remaining: 0}}, 5, []float64{0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allocateDiscount(tt.lines, tt.discount)
+			for i, line := range tt.lines {
+				if !moneyEqual(line.remaining, tt.want[i]) {
+					t.Errorf("line %d remaining = %v, want %v", i, line.remaining, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDiscountThenTax(t *testing.T) {
+	lines := []pricedLine{
+		{menuItemID: 1, unitPrice: 5, quantity: 2},
+		{menuItemID: 2, unitPrice: 10, quantity: 1},
+	}
+	promotions := evaluatePromotions(lines, []models.Promotion{{ID: 1, Name: "10% off", Type: "percentage", Value: 10}})
+	if len(promotions) != 1 || promotions[0].DiscountAmount != 2 {
+		t.Fatalf("promotions = %+v, want one 2.00 discount", promotions)
+	}
+	allocateDiscount(lines, 3)
+
+	taxes, added := calculateTaxes(lines, []models.TaxRate{{ID: 1, Name: "VAT", Rate: 10}}, false)
+	if added != 1.5 {
+		t.Errorf("tax added = %v, want 1.5 on 15.00 after discounts", added)
+	}
+	if len(taxes) != 2 || taxes[0].TaxableAmount != 7.5 || taxes[1].TaxableAmount != 7.5 {
+		t.Errorf("taxes = %+v, want 7.50 taxable on each line", taxes)
+	}
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"frappuccino/internal/dal"
+	"frappuccino/models"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	webhookPollInterval = 2 * time.Second
+	webhookBatch        = 100
+	webhookMaxAttempts  = 8
+	webhookBaseBackoff  = 10 * time.Second
+	webhookMaxBackoff   = time.Hour
+)
+
+type WebhookService struct {
+	repo   dal.WebhookInterface
+	client *http.Client
+	logger *slog.Logger
+}
+
+func NewWebhookService(repo dal.WebhookInterface, logger *slog.Logger) *WebhookService {
+	return &WebhookService{
+		repo:   repo,
+		client: &http.Client{Timeout: 10 * time.Second},
+		logger: logger,
+	}
+}
+
+// CreateSubscription stores a subscription. A secret is generated when the
+// caller doesn't provide one; it is returned only in this response.
+func (s *WebhookService) CreateSubscription(ctx context.Context, sub models.WebhookSubscription) (models.WebhookSubscription, error) {
+	if sub.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return models.WebhookSubscription{}, fmt.Errorf("failed to generate secret: %w", err)
+		}
+		sub.Secret = hex.EncodeToString(secret)
+	}
+	return s.repo.CreateSubscription(ctx, sub)
+}
+
+func (s *WebhookService) ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	return s.repo.ListSubscriptions(ctx)
+}
+
+func (s *WebhookService) DeleteSubscription(ctx context.Context, id int) error {
+	return s.repo.DeleteSubscription(ctx, id)
+}
+
+func (s *WebhookService) ListDeadLetters(ctx context.Context) ([]models.WebhookDeadLetter, error) {
+	return s.repo.ListDeadLetters(ctx)
+}
+
+func (s *WebhookService) Redeliver(ctx context.Context, deliveryID int64) error {
+	return s.repo.Redeliver(ctx, deliveryID)
+}
+
+// Run polls the outbox and sends due deliveries until ctx is done.
+// Deliveries claimed but not sent by then are sent again once their lease
+// runs out.
+func (s *WebhookService) Run(ctx context.Context) {
+	ticker := time.NewTicker(webhookPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		s.poll(ctx)
+	}
+}
+
+func (s *WebhookService) poll(ctx context.Context) {
+	if err := s.repo.FanOut(ctx, webhookBatch); err != nil {
+		s.logger.Error("Failed to fan out webhook events", slog.Any("error", err))
+		return
+	}
+	deliveries, err := s.repo.ClaimDue(ctx, webhookBatch)
+	if err != nil {
+		s.logger.Error("Failed to claim webhook deliveries", slog.Any("error", err))
+		return
+	}
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := s.deliver(ctx, delivery); err != nil {
+			s.logger.Error("Failed to record webhook delivery", slog.Int64("DeliveryID", delivery.ID), slog.Any("error", err))
+		}
+	}
+}
+
+func (s *WebhookService) deliver(ctx context.Context, delivery models.WebhookDelivery) error {
+	statusCode, sendErr := s.send(ctx, delivery)
+	if sendErr == nil {
+		return s.repo.MarkDelivered(ctx, delivery.ID, statusCode)
+	}
+	if ctx.Err() != nil {
+		// Cut off by shutdown, not an attempt that failed
+		return nil
+	}
+
+	attempts := delivery.Attempts + 1
+	if attempts >= webhookMaxAttempts {
+		return s.repo.MarkDead(ctx, delivery.ID, statusCode, sendErr.Error())
+	}
+	return s.repo.MarkFailed(ctx, delivery.ID, statusCode, sendErr.Error(), int(backoff(attempts).Seconds()))
+}
+
+// send posts the event and returns the response status. The signature is an
+// HMAC-SHA256 of "<timestamp>.<body>" so receivers can reject replays.
+func (s *WebhookService) send(ctx context.Context, delivery models.WebhookDelivery) (int, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"id":         delivery.EventID,
+		"type":       delivery.EventType,
+		"created_at": delivery.CreatedAt,
+		"data":       delivery.Payload,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(delivery.Secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Event", delivery.EventType)
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff doubles the wait after every failed attempt: 10s, 20s, 40s, ...
+func backoff(attempts int) time.Duration {
+	wait := webhookBaseBackoff << (attempts - 1)
+	if wait > webhookMaxBackoff || wait <= 0 {
+		return webhookMaxBackoff
+	}
+	return wait
+}
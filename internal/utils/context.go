@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"context"
+	"frappuccino/models"
+	"log/slog"
+)
+
+type requestIDKey struct{}
+
+type loggerKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the id of the request.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID returns the id of the request, empty outside of one.
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// WithLogger returns a copy of ctx carrying a logger scoped to the request.
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// Logger returns the logger of the request, which tags every line with the
+// request id, or the default logger outside of a request.
+func Logger(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+type actorKey struct{}
+
+// WithActor returns a copy of ctx carrying who makes the request, so the
+// changes it makes can be attributed to them.
+func WithActor(ctx context.Context, actor models.Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom returns who makes the request, empty outside of one.
+func ActorFrom(ctx context.Context) models.Actor {
+	actor, _ := ctx.Value(actorKey{}).(models.Actor)
+	return actor
+}
@@ -0,0 +1,154 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"frappuccino/models"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxBodySize limits the JSON bodies read from requests.
+const maxBodySize = 1 << 20
+
+// DecodeJSON decodes the body of the request into v. Malformed JSON, fields v
+// has no place for and values of the wrong type are returned together as a
+// *models.ValidationError.
+func DecodeJSON(r *http.Request, v interface{}) error {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize+1))
+	if err != nil {
+		return fmt.Errorf("failed to read request body: %w", err)
+	}
+	if len(body) > maxBodySize {
+		return &models.ValidationError{Fields: []models.FieldError{{
+			Code: models.CodeOutOfRange, Message: fmt.Sprintf("request body is larger than %d bytes", maxBodySize),
+		}}}
+	}
+
+	var raw interface{}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return &models.ValidationError{Fields: []models.FieldError{{
+			Code: models.CodeMalformed, Message: "request body is not valid JSON: " + strings.TrimPrefix(err.Error(), "json: "),
+		}}}
+	}
+	if unknown := unknownFields(raw, reflect.TypeOf(v), ""); len(unknown) > 0 {
+		sort.Slice(unknown, func(i, j int) bool { return unknown[i].Pointer < unknown[j].Pointer })
+		return &models.ValidationError{Fields: unknown}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			pointer := "/" + strings.ReplaceAll(typeErr.Field, ".", "/")
+			return &models.ValidationError{Fields: []models.FieldError{{
+				Pointer: pointer,
+				Code:    models.CodeInvalidType,
+				Message: fmt.Sprintf("%s must be %s, not %s", pointer, jsonKind(typeErr.Type), typeErr.Value),
+			}}}
+		}
+		return &models.ValidationError{Fields: []models.FieldError{{
+			Code: models.CodeMalformed, Message: "request body can't be decoded: " + strings.TrimPrefix(err.Error(), "json: "),
+		}}}
+	}
+	return nil
+}
+
+// unknownFields returns the fields of value that t has no place for, the way
+// encoding/json matches them: by tag or name, ignoring case.
+func unknownFields(value interface{}, t reflect.Type, pointer string) []models.FieldError {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	var unknown []models.FieldError
+	switch value := value.(type) {
+	case map[string]interface{}:
+		if t.Kind() == reflect.Map {
+			for key, element := range value {
+				unknown = append(unknown, unknownFields(element, t.Elem(), pointer+"/"+escapePointer(key))...)
+			}
+			break
+		}
+		if t.Kind() != reflect.Struct {
+			break
+		}
+		for key, element := range value {
+			field, ok := jsonField(t, key)
+			if !ok {
+				unknown = append(unknown, models.FieldError{
+					Pointer: pointer + "/" + escapePointer(key),
+					Code:    models.CodeUnknownField,
+					Message: fmt.Sprintf("unknown field %q", key),
+				})
+				continue
+			}
+			unknown = append(unknown, unknownFields(element, field.Type, pointer+"/"+escapePointer(key))...)
+		}
+	case []interface{}:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			break
+		}
+		for i, element := range value {
+			unknown = append(unknown, unknownFields(element, t.Elem(), pointer+"/"+strconv.Itoa(i))...)
+		}
+	}
+	return unknown
+}
+
+// jsonField returns the field of the struct type t that the JSON key name
+// decodes into.
+func jsonField(t reflect.Type, name string) (reflect.StructField, bool) {
+	var folded reflect.StructField
+	found := false
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if tag == "-" {
+			continue
+		}
+		if tag == "" {
+			tag = field.Name
+		}
+		if tag == name {
+			return field, true
+		}
+		if !found && strings.EqualFold(tag, name) {
+			folded, found = field, true
+		}
+	}
+	return folded, found
+}
+
+// escapePointer escapes a key for use in a JSON pointer (RFC 6901).
+func escapePointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+func jsonKind(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
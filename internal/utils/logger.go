@@ -2,6 +2,7 @@ package utils
 
 import (
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 )
@@ -9,7 +10,7 @@ import (
 /*
 This Go code sets up a logger that writes log entries to a specified file. It uses Go's built-in log/slog package for structured logging. Here's a breakdown:
 
-SetupLogger(logFilePath string): This function creates and configures a logger that writes to the specified log file.
+SetupLogger(logFilePath string): This function creates and configures a logger that writes to the specified log file and to the standard output.
 */
 
 func SetupLogger(logFilePath string) (*slog.Logger, error) {
@@ -24,7 +25,7 @@ func SetupLogger(logFilePath string) (*slog.Logger, error) {
 	if err != nil {
 		return nil, err
 	}
-	fileHandler := slog.NewJSONHandler(file, nil)
+	fileHandler := slog.NewJSONHandler(io.MultiWriter(os.Stdout, file), nil)
 
 	logger := slog.New(fileHandler)
 
@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"frappuccino/models"
+	"log/slog"
+	"net/http"
+)
+
+// Problem is an RFC 7807 problem details body, the answer to every request
+// that fails.
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+	// Extensions of some problems
+	Errors     []models.FieldError    `json:"errors,omitempty"`
+	Shortages  []models.StockShortage `json:"shortages,omitempty"`
+	Permission string                 `json:"permission,omitempty"`
+	Role       string                 `json:"role,omitempty"`
+}
+
+// Types of the problems answered for domain errors. Other problems are
+// about:blank, their status saying all there is to say.
+const (
+	ProblemNotFound          = "/problems/not-found"
+	ProblemConflict          = "/problems/conflict"
+	ProblemValidation        = "/problems/validation"
+	ProblemInsufficientStock = "/problems/insufficient-stock"
+)
+
+// NewProblem returns the problem of a request failing with status.
+func NewProblem(r *http.Request, status int, detail string) Problem {
+	return Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  r.URL.Path,
+		RequestID: RequestID(r.Context()),
+	}
+}
+
+// WriteProblem writes problem as the response.
+func WriteProblem(w http.ResponseWriter, problem Problem) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(problem.Status)
+	json.NewEncoder(w).Encode(problem)
+}
+
+// SendProblem answers the request with status and detail.
+func SendProblem(w http.ResponseWriter, r *http.Request, status int, detail string) {
+	WriteProblem(w, NewProblem(r, status, detail))
+}
+
+// HandleError answers the request with the problem err maps to and returns
+// its status. Errors that aren't domain errors are logged and answered with
+// a 500 carrying message, so their details stay on the server.
+func HandleError(w http.ResponseWriter, r *http.Request, err error, message string) int {
+	var problem Problem
+	var domainErr *models.Error
+	var stockErr *models.InsufficientStockError
+	var validationErr *models.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		problem = NewProblem(r, http.StatusBadRequest, validationErr.Error())
+		problem.Type, problem.Title = ProblemValidation, "Invalid request"
+		problem.Errors = validationErr.Fields
+	case errors.As(err, &stockErr):
+		problem = NewProblem(r, http.StatusConflict, stockErr.Error())
+		problem.Type, problem.Title = ProblemInsufficientStock, "Insufficient stock"
+		problem.Shortages = stockErr.Shortages
+	case errors.As(err, &domainErr) && errors.Is(err, models.ErrNotFound):
+		problem = NewProblem(r, http.StatusNotFound, domainErr.Message)
+		problem.Type = ProblemNotFound
+	case errors.As(err, &domainErr) && errors.Is(err, models.ErrConflict):
+		problem = NewProblem(r, http.StatusConflict, domainErr.Message)
+		problem.Type = ProblemConflict
+	case errors.As(err, &domainErr) && errors.Is(err, models.ErrValidation):
+		problem = NewProblem(r, http.StatusBadRequest, domainErr.Message)
+		problem.Type = ProblemValidation
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		// The client is gone or the request ran out of time; either way the
+		// answer is unlikely to be read
+		Logger(r.Context()).Warn(message, slog.Any("error", err))
+		problem = NewProblem(r, http.StatusServiceUnavailable, "Request timed out!")
+	default:
+		Logger(r.Context()).Error(message, slog.Any("error", err))
+		problem = NewProblem(r, http.StatusInternalServerError, message)
+	}
+	WriteProblem(w, problem)
+	return problem.Status
+}
@@ -2,18 +2,15 @@ package utils
 
 import (
 	"errors"
-	"flag"
 	"fmt"
+	"frappuccino/internal/auth"
+	"frappuccino/models"
+	"net/http"
 	"net/url"
 	"os"
 	"strconv"
 )
 
-var (
-	Port = flag.String("port", "8080", "Port to listen on")
-	Help = flag.Bool("help", false, "Show help Definition")
-)
-
 const (
 	PINK  = "\033[38;5;206m"
 	TEAL  = "\033[38;5;32m"
@@ -68,20 +65,91 @@ func ParsePaginationParams(queryParams url.Values) (int, int) {
 	if err != nil || pageSize < 1 {
 		pageSize = 10
 	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
 
 	return page, pageSize
 }
 
+// EmployeeHeader names the employee making a request, so orders and stock
+// movements can be attributed to them.
+const EmployeeHeader = "X-Employee-ID"
+
+// EmployeeID returns the employee making the request: the logged-in staff
+// member, otherwise the one named in the header, nil when there is none.
+func EmployeeID(r *http.Request) (*int, error) {
+	if principal, ok := auth.PrincipalFrom(r.Context()); ok && principal.EmployeeID != nil {
+		return principal.EmployeeID, nil
+	}
+	value := r.Header.Get(EmployeeHeader)
+	if value == "" {
+		return nil, nil
+	}
+	id, err := strconv.Atoi(value)
+	if err != nil || id <= 0 {
+		return nil, fmt.Errorf("invalid %s header", EmployeeHeader)
+	}
+	return &id, nil
+}
+
+// RequestIDHeader carries the id of a request, taken from the client or
+// generated, and returned in the response.
+const RequestIDHeader = "X-Request-ID"
+
+// Actor returns who is making the request: the authenticated caller and
+// the employee the changes are attributed to.
+func Actor(r *http.Request) (models.Actor, error) {
+	employeeID, err := EmployeeID(r)
+	if err != nil {
+		return models.Actor{}, err
+	}
+	actor := models.Actor{EmployeeID: employeeID, RequestID: RequestID(r.Context())}
+	if principal, ok := auth.PrincipalFrom(r.Context()); ok {
+		actor.Kind, actor.ID, actor.Name = principal.Kind, principal.ID, principal.Name
+	}
+	return actor, nil
+}
+
 func PrintHelp() {
 	fmt.Println("$ ./frappuccino --help" +
 		"\nCoffee Shop Management System" +
 		"\n" +
 		"\nUsage:" +
-		"\n  frappuccino [--port <N>] [--dir <S>]" +
+		"\n  frappuccino [--config <path>] [--port <N>] [options]" +
 		"\n  frappuccino --help" +
 		"\n" +
+		"\nEvery option can also be set with the environment variable in brackets, or" +
+		"\nwith a KEY=value line in the config file. Flags win over the environment," +
+		"\nand the environment over the file." +
+		"\n" +
 		"\nOptions:" +
-		"\n  --help       Show this screen." +
-		"\n  --port N     Port number." +
-		"  --dir S      Path to the data directory.")
+		"\n  --help                       Show this screen." +
+		"\n  --config PATH                Config file [FRAPPUCCINO_CONFIG]." +
+		"\n  --port N                     Port number, 8080 by default [FRAPPUCCINO_PORT]." +
+		"\n  --log-file PATH              Log file, /log.log by default [FRAPPUCCINO_LOG_FILE]." +
+		"\n" +
+		"\nDatabase:" +
+		"\n  --db-host HOST               Host, db by default [DB_HOST]." +
+		"\n  --db-port N                  Port, 5432 by default [DB_PORT]." +
+		"\n  --db-user NAME               User, latte by default [DB_USER]." +
+		"\n  --db-password S              Password, latte by default [DB_PASSWORD]." +
+		"\n  --db-name NAME               Database, frappuccino by default [DB_NAME]." +
+		"\n  --db-sslmode MODE            disable (default), require, verify-ca or verify-full [DB_SSLMODE]." +
+		"\n  --db-max-open-conns N        Open connections, 25 by default, 0 for no limit [DB_MAX_OPEN_CONNS]." +
+		"\n  --db-max-idle-conns N        Idle connections kept, 25 by default [DB_MAX_IDLE_CONNS]." +
+		"\n  --db-conn-max-lifetime D     Age a connection is closed at, 30m by default [DB_CONN_MAX_LIFETIME]." +
+		"\n  --db-conn-max-idle-time D    Idle time a connection is closed after, 5m by default [DB_CONN_MAX_IDLE_TIME]." +
+		"\n" +
+		"\nServer:" +
+		"\n  --auth-secret S              Key session tokens are signed with, random by default [FRAPPUCCINO_AUTH_SECRET]." +
+		"\n  --cors-origins LIST          Comma separated origins browsers may call from [FRAPPUCCINO_CORS_ORIGINS]." +
+		"\n  --request-timeout D          Deadline of a request, 30s by default [FRAPPUCCINO_REQUEST_TIMEOUT]." +
+		"\n  --read-timeout D             Time to read a request, 15s by default [FRAPPUCCINO_READ_TIMEOUT]." +
+		"\n  --read-header-timeout D      Time to read request headers, 5s by default [FRAPPUCCINO_READ_HEADER_TIMEOUT]." +
+		"\n  --write-timeout D            Time to write a response, 60s by default [FRAPPUCCINO_WRITE_TIMEOUT]." +
+		"\n  --idle-timeout D             Time a keep-alive connection waits, 2m by default [FRAPPUCCINO_IDLE_TIMEOUT]." +
+		"\n  --shutdown-timeout D         Time to drain requests on SIGTERM, 30s by default [FRAPPUCCINO_SHUTDOWN_TIMEOUT]." +
+		"\n" +
+		"\nDurations are written like 30s, 5m or 1h.")
 }
@@ -1,13 +1,28 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
 	"database/sql"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
+	a "frappuccino/internal/auth"
+	"frappuccino/internal/check"
+	"frappuccino/internal/config"
 	d "frappuccino/internal/dal"
 	h "frappuccino/internal/handler"
+	m "frappuccino/internal/middleware"
+	"frappuccino/internal/router"
 	s "frappuccino/internal/service"
 	u "frappuccino/internal/utils"
 )
@@ -41,9 +56,37 @@ func initRepository(dsn string) (*d.InventoryRepository, *d.MenuRepository, *d.O
 	return invRepo, menuRepo, orderRepo, reportRepo, db, nil
 }
 
+// authSecret returns the key session tokens are signed with. Without a
+// configured secret a random key is used, and staff have to log in again
+// after every restart.
+func authSecret(secret string) []byte {
+	if secret != "" {
+		return []byte(secret)
+	}
+	log.Println("FRAPPUCCINO_AUTH_SECRET is not set, sessions won't survive a restart")
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		log.Fatalf("Error generating auth secret: %v", err)
+	}
+	return key
+}
+
 func main() {
-	logFile := "/log.log"
-	dsn := "host=db port=5432 user=latte password=latte dbname=frappuccino sslmode=disable"
+	cfg, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		u.PrintHelp()
+		return
+	}
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	// Every request logs through it, tagged with the request id
+	logger, err := u.SetupLogger(cfg.LogFile)
+	if err != nil {
+		log.Fatalf("Error creating logger: %v", err)
+	}
+	slog.SetDefault(logger)
+	dsn := cfg.DB.DSN()
 
 	log.Println("Starting application setup...")
 
@@ -52,70 +95,317 @@ func main() {
 		log.Fatalf("Initialization error: %v", err)
 	}
 	defer db.Close()
+	db.SetMaxOpenConns(cfg.DB.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.DB.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.DB.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.DB.ConnMaxIdleTime)
+
+	customerRepo, err := d.NewCustomerRepository(db)
+	if err != nil {
+		log.Fatalf("Error creating customer repository: %v", err)
+	}
+
+	promotionRepo, err := d.NewPromotionRepository(db)
+	if err != nil {
+		log.Fatalf("Error creating promotion repository: %v", err)
+	}
+
+	taxRepo, err := d.NewTaxRepository(db)
+	if err != nil {
+		log.Fatalf("Error creating tax repository: %v", err)
+	}
+
+	settingsRepo, err := d.NewSettingsRepository(db)
+	if err != nil {
+		log.Fatalf("Error creating settings repository: %v", err)
+	}
+
+	paymentRepo, err := d.NewPaymentRepository(db)
+	if err != nil {
+		log.Fatalf("Error creating payment repository: %v", err)
+	}
+
+	drawerRepo, err := d.NewDrawerRepository(db)
+	if err != nil {
+		log.Fatalf("Error creating drawer repository: %v", err)
+	}
+
+	employeeRepo, err := d.NewEmployeeRepository(db)
+	if err != nil {
+		log.Fatalf("Error creating employee repository: %v", err)
+	}
+
+	authRepo, err := d.NewAuthRepository(db)
+	if err != nil {
+		log.Fatalf("Error creating auth repository: %v", err)
+	}
+
+	roleRepo, err := d.NewRoleRepository(db, dsn, logger)
+	if err != nil {
+		log.Fatalf("Error creating role repository: %v", err)
+	}
+
+	auditRepo, err := d.NewAuditRepository(db)
+	if err != nil {
+		log.Fatalf("Error creating audit repository: %v", err)
+	}
+
+	enumRepo, err := d.NewEnumRepository(db)
+	if err != nil {
+		log.Fatalf("Error creating enum repository: %v", err)
+	}
+	enums, err := enumRepo.List(context.Background())
+	if err != nil {
+		log.Fatalf("Error loading enums: %v", err)
+	}
+	check.SetEnums(enums)
 
 	// create services
 	invService := s.NewIngredientService(invRepo)
-	menuService := s.NewMenuItemService(menuRepo)
-	orderService := s.NewOrderService(orderRepo, invRepo, menuRepo)
+	menuService := s.NewMenuItemService(menuRepo, invRepo)
+	orderService := s.NewOrderService(orderRepo, invRepo, menuRepo, customerRepo, promotionRepo, taxRepo, settingsRepo)
+	customerService := s.NewCustomerService(customerRepo)
+	promotionService := s.NewPromotionService(promotionRepo)
+	taxService := s.NewTaxService(taxRepo)
+	settingsService := s.NewSettingsService(settingsRepo)
+	paymentService := s.NewPaymentService(paymentRepo)
+	drawerService := s.NewDrawerService(drawerRepo)
+	receiptService := s.NewReceiptService(orderRepo, paymentRepo, settingsRepo)
+	employeeService := s.NewEmployeeService(employeeRepo)
+	authService := s.NewAuthService(authRepo, employeeRepo, authSecret(cfg.AuthSecret))
+	rbacService, err := s.NewRBACService(context.Background(), roleRepo, logger)
+	if err != nil {
+		log.Fatalf("Error loading role permissions: %v", err)
+	}
 	reportsService := s.NewReportService(reportRepo)
+	auditService := s.NewAuditService(auditRepo)
 
-	// create handlers
-	invHandler, err := h.NewInventoryHandler(invService, logFile)
+	stockCountRepo, err := d.NewStockCountRepository(db)
 	if err != nil {
-		log.Fatalf("Error creating inventory handler: %v", err)
+		log.Fatalf("Error creating stock count repository: %v", err)
 	}
+	stockCountService := s.NewStockCountService(stockCountRepo)
 
-	menuHandler, err := h.NewMenuHandler(menuService, logFile)
+	queueRepo, err := d.NewQueueRepository(db)
 	if err != nil {
-		log.Fatalf("Error creating menu handler: %v", err)
+		log.Fatalf("Error creating queue repository: %v", err)
 	}
+	queueService := s.NewQueueService(queueRepo)
 
-	orderHandler, err := h.NewOrderHandler(orderService, logFile)
+	webhookRepo, err := d.NewWebhookRepository(db)
 	if err != nil {
-		log.Fatalf("Error creating order handler: %v", err)
+		log.Fatalf("Error creating webhook repository: %v", err)
 	}
+	webhookService := s.NewWebhookService(webhookRepo, logger)
+	// Background workers run until a shutdown signal; main waits for them
+	// before exiting
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	var workers sync.WaitGroup
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		webhookService.Run(ctx)
+	}()
 
-	reportsHandler, err := h.NewReportsHandler(reportsService, logFile)
+	orderEventRepo, err := d.NewOrderEventRepository(db, dsn, logger)
 	if err != nil {
-		log.Fatalf("Error creating reports handler: %v", err)
+		log.Fatalf("Error creating order event repository: %v", err)
 	}
+	orderEventBroker := s.NewOrderEventBroker(orderEventRepo, logger)
+	if err := orderEventBroker.Run(ctx); err != nil {
+		log.Fatalf("Error starting order event broker: %v", err)
+	}
+	if err := rbacService.Run(ctx); err != nil {
+		log.Fatalf("Error listening for role permission changes: %v", err)
+	}
+
+	// create handlers
+	invHandler := h.NewInventoryHandler(invService)
+	menuHandler := h.NewMenuHandler(menuService)
+	orderHandler := h.NewOrderHandler(orderService, rbacService)
+	reportsHandler := h.NewReportsHandler(reportsService)
+	stockCountHandler := h.NewStockCountHandler(stockCountService)
+	orderStreamHandler := h.NewOrderStreamHandler(orderEventBroker)
+	queueHandler := h.NewQueueHandler(queueService)
+	webhookHandler := h.NewWebhookHandler(webhookService)
+	customerHandler := h.NewCustomerHandler(customerService, orderService)
+	promotionHandler := h.NewPromotionHandler(promotionService)
+	taxHandler := h.NewTaxHandler(taxService)
+	settingsHandler := h.NewSettingsHandler(settingsService)
+	paymentHandler := h.NewPaymentHandler(paymentService)
+	drawerHandler := h.NewDrawerHandler(drawerService)
+	receiptHandler := h.NewReceiptHandler(receiptService)
+	employeeHandler := h.NewEmployeeHandler(employeeService)
+	authHandler := h.NewAuthHandler(authService)
+	rbacHandler := h.NewRBACHandler(rbacService)
+	auditHandler := h.NewAuditHandler(auditService)
+	mux := router.New()
+	mux.Use(m.RequestID(logger), m.AccessLog, m.Recover, m.CORS(m.CORSConfig{AllowedOrigins: cfg.CORSOrigins, MaxAge: 10 * time.Minute}))
+	timeout := m.Timeout(cfg.RequestTimeout)
 
-	mux := u.NewCustomMux()
+	// Authentication:
+	mux.Group("", timeout).HandleFunc("POST /auth/login", authHandler.Login) // Exchange username and password for a session token
+
+	// Every other route needs an authenticated caller
+	api := mux.Group("", authHandler.Middleware, timeout)
+	// Streams stay open, so they have no deadline
+	streams := mux.Group("", authHandler.Middleware)
+	api.HandleFunc("POST /auth/logout", authHandler.Logout)                                                // Open to any authenticated caller
+	api.HandleFunc("GET /auth/me", authHandler.Me)                                                         // Open to any authenticated caller
+	api.HandleFunc("POST /auth/tokens", rbacHandler.Require(a.PermAuthManage, authHandler.CreateAPIToken)) // The token is shown only in this response
+	api.HandleFunc("GET /auth/tokens", rbacHandler.Require(a.PermAuthManage, authHandler.ListAPITokens))
+	api.HandleFunc("DELETE /auth/tokens/{id}", rbacHandler.Require(a.PermAuthManage, authHandler.RevokeAPIToken))
+
+	// Roles and permissions:
+	api.HandleFunc("GET /roles", rbacHandler.Require(a.PermRolesManage, rbacHandler.ListRoles))
+	api.HandleFunc("GET /permissions", rbacHandler.Require(a.PermRolesManage, rbacHandler.ListPermissions))
+	api.HandleFunc("PUT /roles/{role}/permissions", rbacHandler.Require(a.PermRolesManage, rbacHandler.UpdateRolePermissions))
 
 	// Orders:
-	mux.HandleFunc("POST /orders/batch-process", orderHandler.BatchProcessOrders)
-	mux.HandleFunc("POST /orders", orderHandler.CreateOrder) // Create a new order
-	mux.HandleFunc("GET /orders", orderHandler.ListOrders)   //Retrieve all orders
-	mux.HandleFunc("GET /orders/numberOfOrderedItems", orderHandler.GetOrderedItemsCount)
-	mux.HandleFunc("GET /orders/{id}", orderHandler.GetOrder)          // Retrieve a specific order by ID
-	mux.HandleFunc("PUT /orders/{id}", orderHandler.UpdateOrder)       // Update an existing order
-	mux.HandleFunc("DELETE /orders/{id}", orderHandler.DeleteOrder)    // Delete an order
-	mux.HandleFunc("POST /orders/{id}/close", orderHandler.CloseOrder) //Close an order
+	api.HandleFunc("POST /orders/batch-process", rbacHandler.Require(a.PermOrdersCreate, orderHandler.BatchProcessOrders))
+	api.HandleFunc("POST /orders", rbacHandler.Require(a.PermOrdersCreate, orderHandler.CreateOrder)) // Create a new order
+	api.HandleFunc("GET /orders", rbacHandler.Require(a.PermOrdersRead, orderHandler.ListOrders))     //Retrieve all orders
+	api.HandleFunc("GET /orders/numberOfOrderedItems", rbacHandler.Require(a.PermOrdersRead, orderHandler.GetOrderedItemsCount))
+	streams.HandleFunc("GET /orders/stream", rbacHandler.Require(a.PermOrdersRead, orderStreamHandler.StreamOrders))
+	api.HandleFunc("POST /orders/{id}/payments", rbacHandler.Require(a.PermPaymentsCreate, paymentHandler.CreatePayment))
+	api.HandleFunc("GET /orders/{id}/payments", rbacHandler.Require(a.PermOrdersRead, paymentHandler.ListPayments))
+	api.HandleFunc("POST /orders/{id}/refunds", rbacHandler.Require(a.PermPaymentsRefund, paymentHandler.CreateRefund))
+	api.HandleFunc("GET /orders/{id}/receipt", rbacHandler.Require(a.PermOrdersRead, receiptHandler.GetReceipt))
+	api.HandleFunc("GET /orders/{id}", rbacHandler.Require(a.PermOrdersRead, orderHandler.GetOrder))            // Retrieve a specific order by ID
+	api.HandleFunc("PUT /orders/{id}", rbacHandler.Require(a.PermOrdersUpdate, orderHandler.UpdateOrder))       // Update an existing order
+	api.HandleFunc("DELETE /orders/{id}", rbacHandler.Require(a.PermOrdersDelete, orderHandler.DeleteOrder))    // Delete an order
+	api.HandleFunc("POST /orders/{id}/close", rbacHandler.Require(a.PermOrdersUpdate, orderHandler.CloseOrder)) //Close an order
+
+	// Customers:
+	api.HandleFunc("POST /customers", rbacHandler.Require(a.PermCustomersWrite, customerHandler.CreateCustomer))
+	api.HandleFunc("GET /customers", rbacHandler.Require(a.PermCustomersRead, customerHandler.ListCustomers))
+	api.HandleFunc("GET /customers/{id}/orders", rbacHandler.Require(a.PermCustomersRead, customerHandler.ListCustomerOrders))
+	api.HandleFunc("GET /customers/{id}/stats", rbacHandler.Require(a.PermCustomersRead, customerHandler.GetCustomerStats))
+	api.HandleFunc("GET /customers/{id}", rbacHandler.Require(a.PermCustomersRead, customerHandler.GetCustomer))
+	api.HandleFunc("PUT /customers/{id}", rbacHandler.Require(a.PermCustomersWrite, customerHandler.UpdateCustomer))
+	api.HandleFunc("DELETE /customers/{id}", rbacHandler.Require(a.PermCustomersWrite, customerHandler.DeleteCustomer))
+
+	// Employees and shifts:
+	api.HandleFunc("POST /employees", rbacHandler.Require(a.PermEmployeesWrite, employeeHandler.CreateEmployee))
+	api.HandleFunc("GET /employees", rbacHandler.Require(a.PermEmployeesRead, employeeHandler.ListEmployees))
+	api.HandleFunc("POST /employees/{id}/clock-in", rbacHandler.Require(a.PermTimeClock, employeeHandler.ClockIn))
+	api.HandleFunc("POST /employees/{id}/clock-out", rbacHandler.Require(a.PermTimeClock, employeeHandler.ClockOut))
+	api.HandleFunc("GET /employees/{id}/time-entries", rbacHandler.Require(a.PermEmployeesRead, employeeHandler.ListTimeEntries))
+	api.HandleFunc("PUT /employees/{id}/credentials", rbacHandler.Require(a.PermAuthManage, authHandler.SetCredentials)) // Set username and password
+	api.HandleFunc("GET /employees/{id}", rbacHandler.Require(a.PermEmployeesRead, employeeHandler.GetEmployee))
+	api.HandleFunc("PUT /employees/{id}", rbacHandler.Require(a.PermEmployeesWrite, employeeHandler.UpdateEmployee))
+	api.HandleFunc("DELETE /employees/{id}", rbacHandler.Require(a.PermEmployeesWrite, employeeHandler.DeleteEmployee)) // Deactivate an employee
+	api.HandleFunc("POST /shifts", rbacHandler.Require(a.PermShiftsWrite, employeeHandler.CreateShift))
+	api.HandleFunc("GET /shifts", rbacHandler.Require(a.PermShiftsRead, employeeHandler.ListShifts))
+	api.HandleFunc("GET /shifts/{id}", rbacHandler.Require(a.PermShiftsRead, employeeHandler.GetShift))
+	api.HandleFunc("PUT /shifts/{id}", rbacHandler.Require(a.PermShiftsWrite, employeeHandler.UpdateShift))
+	api.HandleFunc("DELETE /shifts/{id}", rbacHandler.Require(a.PermShiftsWrite, employeeHandler.DeleteShift))
+
+	// Barista queue:
+	api.HandleFunc("GET /queue", rbacHandler.Require(a.PermOrdersRead, queueHandler.ListQueue))                                // Active orders by priority and age
+	api.HandleFunc("POST /queue/{id}/claim", rbacHandler.Require(a.PermOrdersUpdate, queueHandler.ClaimOrder))                 // Claim an order for a station/barista
+	api.HandleFunc("POST /queue/{id}/items/{itemId}/done", rbacHandler.Require(a.PermOrdersUpdate, queueHandler.CompleteItem)) // Mark an order line as done
+	api.HandleFunc("POST /queue/{id}/bump", rbacHandler.Require(a.PermOrdersUpdate, queueHandler.BumpOrder))                   // Bump an order to ready
 
 	// Menu:
-	mux.HandleFunc("POST /menu", menuHandler.CreateMenuItem)        // Add a new menu item
-	mux.HandleFunc("GET /menu", menuHandler.LissMenu)               // Retrieve all menu items
-	mux.HandleFunc("GET /menu/{id}", menuHandler.GetMenuItem)       // Retrieve a specific menu item
-	mux.HandleFunc("PUT /menu/{id}", menuHandler.UpdateMenuItem)    // Update a menu item
-	mux.HandleFunc("DELETE /menu/{id}", menuHandler.DeleteMenuItem) // Delete a menu item
+	api.HandleFunc("POST /menu", rbacHandler.Require(a.PermMenuWrite, menuHandler.CreateMenuItem))        // Add a new menu item
+	api.HandleFunc("GET /menu", rbacHandler.Require(a.PermMenuRead, menuHandler.LissMenu))                // Retrieve all menu items
+	api.HandleFunc("GET /menu/{id}", rbacHandler.Require(a.PermMenuRead, menuHandler.GetMenuItem))        // Retrieve a specific menu item
+	api.HandleFunc("PUT /menu/{id}", rbacHandler.Require(a.PermMenuWrite, menuHandler.UpdateMenuItem))    // Update a menu item
+	api.HandleFunc("DELETE /menu/{id}", rbacHandler.Require(a.PermMenuWrite, menuHandler.DeleteMenuItem)) // Delete a menu item
+
+	// Promotions:
+	api.HandleFunc("POST /promotions", rbacHandler.Require(a.PermPromotionsEdit, promotionHandler.CreatePromotion))
+	api.HandleFunc("GET /promotions", rbacHandler.Require(a.PermPromotionsRead, promotionHandler.ListPromotions))
+	api.HandleFunc("GET /promotions/{id}", rbacHandler.Require(a.PermPromotionsRead, promotionHandler.GetPromotion))
+	api.HandleFunc("PUT /promotions/{id}", rbacHandler.Require(a.PermPromotionsEdit, promotionHandler.UpdatePromotion))
+	api.HandleFunc("DELETE /promotions/{id}", rbacHandler.Require(a.PermPromotionsEdit, promotionHandler.DeletePromotion))
+
+	// Cash drawer:
+	api.HandleFunc("POST /drawer-sessions", rbacHandler.Require(a.PermDrawerOperate, drawerHandler.OpenSession))                // Open the drawer with a float
+	api.HandleFunc("GET /drawer-sessions", rbacHandler.Require(a.PermDrawerRead, drawerHandler.ListSessions))                   // Retrieve all drawer sessions
+	api.HandleFunc("POST /drawer-sessions/{id}/movements", rbacHandler.Require(a.PermDrawerOperate, drawerHandler.AddMovement)) // Cash in or out
+	api.HandleFunc("POST /drawer-sessions/{id}/close", rbacHandler.Require(a.PermDrawerOperate, drawerHandler.CloseSession))    // Count the drawer and take the Z-report
+	api.HandleFunc("GET /drawer-sessions/{id}/z-report", rbacHandler.Require(a.PermDrawerRead, drawerHandler.GetZReport))       // Z-report as JSON or text
+	api.HandleFunc("GET /drawer-sessions/{id}", rbacHandler.Require(a.PermDrawerRead, drawerHandler.GetSession))                // Session with its cash movements
+
+	// Taxes and settings:
+	api.HandleFunc("POST /tax-rates", rbacHandler.Require(a.PermSettingsWrite, taxHandler.CreateTaxRate))
+	api.HandleFunc("GET /tax-rates", rbacHandler.Require(a.PermSettingsRead, taxHandler.ListTaxRates))
+	api.HandleFunc("GET /tax-rates/{id}", rbacHandler.Require(a.PermSettingsRead, taxHandler.GetTaxRate))
+	api.HandleFunc("PUT /tax-rates/{id}", rbacHandler.Require(a.PermSettingsWrite, taxHandler.UpdateTaxRate))
+	api.HandleFunc("DELETE /tax-rates/{id}", rbacHandler.Require(a.PermSettingsWrite, taxHandler.DeleteTaxRate))
+	api.HandleFunc("GET /settings", rbacHandler.Require(a.PermSettingsRead, settingsHandler.GetSettings))
+	api.HandleFunc("PUT /settings", rbacHandler.Require(a.PermSettingsWrite, settingsHandler.UpdateSettings))
 
 	// Inventory:
-	mux.HandleFunc("POST /inventory", invHandler.CreateIngredient) // Add a new inventory item
-	mux.HandleFunc("GET /inventory/getLeftOvers", invHandler.GetLeftOvers)
-	mux.HandleFunc("GET /inventory", invHandler.ListInventory)            // Retrieve all inventory items
-	mux.HandleFunc("GET /inventory/{id}", invHandler.GetIngredient)       // Retrieve a specific inventory item
-	mux.HandleFunc("PUT /inventory/{id}", invHandler.UpdateIngredient)    // Update an inventory item
-	mux.HandleFunc("DELETE /inventory/{id}", invHandler.DeleteIngredient) // Delete an inventory item
+	api.HandleFunc("POST /inventory", rbacHandler.Require(a.PermInventoryWrite, invHandler.CreateIngredient)) // Add a new inventory item
+	api.HandleFunc("GET /inventory/getLeftOvers", rbacHandler.Require(a.PermInventoryRead, invHandler.GetLeftOvers))
+	api.HandleFunc("GET /inventory/transactions", rbacHandler.Require(a.PermInventoryRead, invHandler.ListTransactions))
+	api.HandleFunc("GET /inventory/{id}/transactions", rbacHandler.Require(a.PermInventoryRead, invHandler.ListIngredientTransactions))
+	api.HandleFunc("POST /inventory/counts", rbacHandler.Require(a.PermInventoryCount, stockCountHandler.OpenCount))                   // Open a stock-take session
+	api.HandleFunc("GET /inventory/counts", rbacHandler.Require(a.PermInventoryCount, stockCountHandler.ListCounts))                   // Retrieve all stock-take sessions
+	api.HandleFunc("GET /inventory/counts/{id}", rbacHandler.Require(a.PermInventoryCount, stockCountHandler.GetCount))                // Variance report of a session
+	api.HandleFunc("POST /inventory/counts/{id}/lines", rbacHandler.Require(a.PermInventoryCount, stockCountHandler.SubmitLines))      // Submit counted quantities
+	api.HandleFunc("POST /inventory/counts/{id}/finalize", rbacHandler.Require(a.PermInventoryCount, stockCountHandler.FinalizeCount)) // Post count corrections
+	api.HandleFunc("GET /inventory", rbacHandler.Require(a.PermInventoryRead, invHandler.ListInventory))                               // Retrieve all inventory items
+	api.HandleFunc("GET /inventory/{id}", rbacHandler.Require(a.PermInventoryRead, invHandler.GetIngredient))                          // Retrieve a specific inventory item
+	api.HandleFunc("PUT /inventory/{id}", rbacHandler.Require(a.PermInventoryWrite, invHandler.UpdateIngredient))                      // Update an inventory item
+	api.HandleFunc("DELETE /inventory/{id}", rbacHandler.Require(a.PermInventoryWrite, invHandler.DeleteIngredient))                   // Delete an inventory item
 
 	// Reports:
-	mux.HandleFunc("GET /reports/search", reportsHandler.HandleSearch)
-	mux.HandleFunc("GET /reports/total-sales", reportsHandler.GetTotalSales)     // Get the total sales amount
-	mux.HandleFunc("GET /reports/popular-items", reportsHandler.GetPopularItems) // Get a list of popular menu items
-	mux.HandleFunc("GET /reports/orderedItemsByPeriod", reportsHandler.GetOrderedItemsByPeriod)
-
-	address := fmt.Sprintf(":%s", *u.Port)
-	fmt.Printf("Server is starting on: \nhttp://localhost:%s\n", *u.Port)
-	if err := http.ListenAndServe(address, mux); err != nil {
+	api.HandleFunc("GET /reports/search", rbacHandler.Require(a.PermReportsSales, reportsHandler.HandleSearch))
+	api.HandleFunc("GET /reports/total-sales", rbacHandler.Require(a.PermReportsSales, reportsHandler.GetTotalSales))     // Get the total sales amount
+	api.HandleFunc("GET /reports/popular-items", rbacHandler.Require(a.PermReportsSales, reportsHandler.GetPopularItems)) // Get a list of popular menu items
+	api.HandleFunc("GET /reports/orderedItemsByPeriod", rbacHandler.Require(a.PermReportsSales, reportsHandler.GetOrderedItemsByPeriod))
+	api.HandleFunc("GET /reports/inventory-valuation", rbacHandler.Require(a.PermReportsProfit, reportsHandler.GetInventoryValuation)) // Stock value per ingredient, now or at a past date
+	api.HandleFunc("GET /reports/ingredient-usage", rbacHandler.Require(a.PermReportsProfit, reportsHandler.GetIngredientUsage))       // Theoretical vs. actual ingredient usage
+	api.HandleFunc("GET /reports/sales-summary", rbacHandler.Require(a.PermReportsSales, reportsHandler.GetSalesSummary))              // Gross vs. net sales and discounts
+	api.HandleFunc("GET /reports/tax", rbacHandler.Require(a.PermReportsSales, reportsHandler.GetTaxReport))                           // Tax collected per rate and order type
+	api.HandleFunc("GET /reports/labor", rbacHandler.Require(a.PermReportsProfit, reportsHandler.GetLaborReport))                      // Labor hours and cost as a share of net sales
+
+	// Webhooks:
+	api.HandleFunc("POST /webhooks", rbacHandler.Require(a.PermWebhooks, webhookHandler.CreateSubscription))                  // Subscribe a URL to events
+	api.HandleFunc("GET /webhooks", rbacHandler.Require(a.PermWebhooks, webhookHandler.ListSubscriptions))                    // Retrieve all subscriptions
+	api.HandleFunc("GET /webhooks/dead-letters", rbacHandler.Require(a.PermWebhooks, webhookHandler.ListDeadLetters))         // Deliveries that ran out of retries
+	api.HandleFunc("POST /webhooks/deliveries/{id}/redeliver", rbacHandler.Require(a.PermWebhooks, webhookHandler.Redeliver)) // Send a delivery again
+	api.HandleFunc("DELETE /webhooks/{id}", rbacHandler.Require(a.PermWebhooks, webhookHandler.DeleteSubscription))           // Delete a subscription
+
+	// Audit log:
+	api.HandleFunc("GET /audit", rbacHandler.Require(a.PermAuditRead, auditHandler.ListAudit)) // Changes to the menu, inventory and orders, newest first
+
+	server := &http.Server{
+		Addr:              ":" + cfg.Port,
+		Handler:           mux,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+	// Open order streams never finish on their own, so they are ended first
+	// and the clients reconnect to another instance
+	server.RegisterOnShutdown(orderEventBroker.Close)
+
+	serverErr := make(chan error, 1)
+	go func() {
+		fmt.Printf("Server is starting on: \nhttp://localhost:%s\n", cfg.Port)
+		serverErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
 		log.Fatal(err)
+	case <-ctx.Done():
+	}
+	stop()
+
+	log.Printf("Shutting down, waiting up to %s for requests in flight...", cfg.ShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Error shutting down the server: %v", err)
 	}
+	workers.Wait()
+	log.Println("Server stopped")
 }
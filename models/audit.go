@@ -0,0 +1,63 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Actor is who makes a change, recorded with it in the audit log.
+type Actor struct {
+	Kind       string `json:"kind,omitempty"`
+	ID         int    `json:"id,omitempty"`
+	Name       string `json:"name,omitempty"`
+	EmployeeID *int   `json:"employee_id,omitempty"`
+	// Id of the request making the change
+	RequestID string `json:"-"`
+}
+
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+// Entities recorded in the audit log.
+const (
+	AuditMenuItem   = "menu_item"
+	AuditIngredient = "ingredient"
+	AuditOrder      = "order"
+)
+
+// AuditEntry is one change with the entity as it was before and after it;
+// Before is null for a create and After for a delete.
+type AuditEntry struct {
+	ID        int             `json:"audit_id"`
+	Action    string          `json:"action"`
+	Entity    string          `json:"entity"`
+	EntityID  int             `json:"entity_id"`
+	Before    json.RawMessage `json:"before"`
+	After     json.RawMessage `json:"after"`
+	Actor     Actor           `json:"actor"`
+	RequestID *string         `json:"request_id"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// AuditFilter describes a page of the audit log, newest first.
+// Nil fields are not applied.
+type AuditFilter struct {
+	Entity     *string
+	EntityID   *int
+	Action     *string
+	EmployeeID *int
+	RequestID  *string
+	StartDate  *string
+	EndDate    *string
+	Cursor     int
+	Limit      int
+}
+
+type AuditPage struct {
+	Entries    []AuditEntry `json:"entries"`
+	NextCursor *int         `json:"next_cursor"`
+	HasMore    bool         `json:"has_more"`
+}
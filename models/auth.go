@@ -0,0 +1,69 @@
+package models
+
+import "time"
+
+// Principal is the authenticated caller of a request: a staff member
+// logged in with a session token or an integration using an API token.
+type Principal struct {
+	Kind       string `json:"kind"`
+	ID         int    `json:"id"`
+	EmployeeID *int   `json:"employee_id,omitempty"`
+	Name       string `json:"name"`
+	Role       string `json:"role"`
+}
+
+const (
+	PrincipalSession  = "session"
+	PrincipalAPIToken = "api_token"
+)
+
+type LoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type LoginResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Employee  Employee  `json:"employee"`
+}
+
+// Credentials set the username and password an employee logs in with.
+type Credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// StoredCredentials is what login is checked against.
+type StoredCredentials struct {
+	EmployeeID   int
+	PasswordHash string
+	Active       bool
+}
+
+// AuthSession is a staff login. The token of a session is signed and
+// carries its expiry; the row records revocation and last use.
+type AuthSession struct {
+	ID           int
+	EmployeeID   int
+	EmployeeName string
+	Role         string
+	Active       bool
+	ExpiresAt    time.Time
+	LastUsedAt   *time.Time
+}
+
+// APIToken authenticates an integration with the permissions of Role.
+// Token is set only in the response that creates it.
+type APIToken struct {
+	ID            int        `json:"token_id"`
+	Name          string     `json:"name"`
+	Role          string     `json:"role"`
+	Token         string     `json:"token,omitempty"`
+	ExpiresInDays *int       `json:"expires_in_days,omitempty"`
+	CreatedBy     *int       `json:"created_by"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ExpiresAt     *time.Time `json:"expires_at"`
+	LastUsedAt    *time.Time `json:"last_used_at"`
+	RevokedAt     *time.Time `json:"revoked_at"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+type Customer struct {
+	ID               int       `json:"customer_id"`
+	Name             string    `json:"name"`
+	Phone            *string   `json:"phone"`
+	Email            *string   `json:"email"`
+	MarketingConsent bool      `json:"marketing_consent"`
+	LoyaltyPoints    int       `json:"loyalty_points"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// CustomerStats summarizes the order history of a customer. Visits count
+// every order that wasn't cancelled or rejected; lifetime value counts only
+// completed orders.
+type CustomerStats struct {
+	CustomerID        int        `json:"customer_id"`
+	Visits            int        `json:"visits"`
+	CompletedOrders   int        `json:"completed_orders"`
+	LifetimeValue     float64    `json:"lifetime_value"`
+	AverageOrderValue float64    `json:"average_order_value"`
+	FirstVisit        *time.Time `json:"first_visit"`
+	LastVisit         *time.Time `json:"last_visit"`
+	FavoriteItem      *string    `json:"favorite_item"`
+	LoyaltyPoints     int        `json:"loyalty_points"`
+	PointsEarned      int        `json:"points_earned"`
+	PointsRedeemed    int        `json:"points_redeemed"`
+}
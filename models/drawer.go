@@ -0,0 +1,68 @@
+package models
+
+import "time"
+
+// DrawerSession is one till shift. Closed sessions keep their Z-report.
+type DrawerSession struct {
+	ID           int              `json:"session_id"`
+	OpenedBy     string           `json:"opened_by"`
+	OpeningFloat float64          `json:"opening_float"`
+	Status       string           `json:"status"`
+	OpenedAt     time.Time        `json:"opened_at"`
+	ClosedBy     *string          `json:"closed_by"`
+	ClosedAt     *time.Time       `json:"closed_at"`
+	CountedCash  *float64         `json:"counted_cash"`
+	Movements    []DrawerMovement `json:"movements,omitempty"`
+}
+
+// DrawerMovement is cash put into (cash_in) or taken out of (cash_out) the
+// drawer outside of payments, such as change top-ups or tips paid out.
+type DrawerMovement struct {
+	ID        int       `json:"movement_id"`
+	SessionID int       `json:"session_id"`
+	Type      string    `json:"type"`
+	Amount    float64   `json:"amount"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type DrawerCloseRequest struct {
+	CountedCash *float64 `json:"counted_cash"`
+	ClosedBy    string   `json:"closed_by"`
+}
+
+// ZReport is the end-of-day report of a drawer session. Expected cash is
+// the opening float plus cash taken (amounts and tips) less cash refunds,
+// plus cash in and less cash out.
+type ZReport struct {
+	SessionID      int             `json:"session_id"`
+	OpenedBy       string          `json:"opened_by"`
+	OpenedAt       time.Time       `json:"opened_at"`
+	ClosedBy       string          `json:"closed_by"`
+	ClosedAt       time.Time       `json:"closed_at"`
+	Orders         int             `json:"orders"`
+	GrossSales     float64         `json:"gross_sales"`
+	Discounts      float64         `json:"discounts"`
+	Tax            float64         `json:"tax"`
+	TotalSales     float64         `json:"total_sales"`
+	Tenders        []ZReportTender `json:"tenders"`
+	TotalPayments  float64         `json:"total_payments"`
+	TotalTips      float64         `json:"total_tips"`
+	TotalRefunds   float64         `json:"total_refunds"`
+	OpeningFloat   float64         `json:"opening_float"`
+	CashIn         float64         `json:"cash_in"`
+	CashOut        float64         `json:"cash_out"`
+	ExpectedCash   float64         `json:"expected_cash"`
+	CountedCash    float64         `json:"counted_cash"`
+	CashVariance   float64         `json:"cash_variance"`
+	MovementsCount int             `json:"movements"`
+}
+
+type ZReportTender struct {
+	Method   string  `json:"method"`
+	Payments int     `json:"payments"`
+	Amount   float64 `json:"amount"`
+	Tips     float64 `json:"tips"`
+	Refunds  float64 `json:"refunds"`
+	Net      float64 `json:"net"`
+}
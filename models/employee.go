@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+type Employee struct {
+	ID         int       `json:"employee_id"`
+	Name       string    `json:"name"`
+	Email      *string   `json:"email"`
+	Phone      *string   `json:"phone"`
+	Role       string    `json:"role"`
+	HourlyRate float64   `json:"hourly_rate"`
+	Active     *bool     `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Shift is a scheduled block of work.
+type Shift struct {
+	ID           int       `json:"shift_id"`
+	EmployeeID   int       `json:"employee_id"`
+	EmployeeName string    `json:"employee_name,omitempty"`
+	StartsAt     time.Time `json:"starts_at"`
+	EndsAt       time.Time `json:"ends_at"`
+	Note         *string   `json:"note"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type ShiftFilter struct {
+	EmployeeID *int
+	StartDate  *string
+	EndDate    *string
+}
+
+// TimeEntry is the time actually worked from clock-in to clock-out. Hours
+// of an entry still open run up to now.
+type TimeEntry struct {
+	ID         int        `json:"entry_id"`
+	EmployeeID int        `json:"employee_id"`
+	ShiftID    *int       `json:"shift_id"`
+	ClockIn    time.Time  `json:"clock_in"`
+	ClockOut   *time.Time `json:"clock_out"`
+	Hours      float64    `json:"hours"`
+}
+
+// LaborReport compares the cost of the hours worked in a period with the
+// net sales (after discounts, before tax) of the same period.
+type LaborReport struct {
+	StartDate      *string     `json:"start_date"`
+	EndDate        *string     `json:"end_date"`
+	Employees      []LaborLine `json:"employees"`
+	ScheduledHours float64     `json:"scheduled_hours"`
+	WorkedHours    float64     `json:"worked_hours"`
+	LaborCost      float64     `json:"labor_cost"`
+	NetSales       float64     `json:"net_sales"`
+	LaborPercent   *float64    `json:"labor_percent"`
+}
+
+type LaborLine struct {
+	EmployeeID     int     `json:"employee_id"`
+	Name           string  `json:"name"`
+	Role           string  `json:"role"`
+	HourlyRate     float64 `json:"hourly_rate"`
+	ScheduledHours float64 `json:"scheduled_hours"`
+	WorkedHours    float64 `json:"worked_hours"`
+	LaborCost      float64 `json:"labor_cost"`
+}
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Kinds of domain errors. Handlers tell them apart with errors.Is to choose
+// the status of the response.
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrConflict   = errors.New("conflict")
+	ErrValidation = errors.New("validation failed")
+)
+
+// Error is a domain error of one kind, with a message meant for the client.
+type Error struct {
+	Kind    error
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+// NotFound reports an entity that doesn't exist.
+func NotFound(format string, args ...interface{}) error {
+	return &Error{Kind: ErrNotFound, Message: fmt.Sprintf(format, args...)}
+}
+
+// Conflict reports a change the current state doesn't allow.
+func Conflict(format string, args ...interface{}) error {
+	return &Error{Kind: ErrConflict, Message: fmt.Sprintf(format, args...)}
+}
+
+// Invalid reports a request that can't be valid whatever the state.
+func Invalid(format string, args ...interface{}) error {
+	return &Error{Kind: ErrValidation, Message: fmt.Sprintf(format, args...)}
+}
+
+// StockShortage is an ingredient there isn't enough of.
+type StockShortage struct {
+	IngredientID int     `json:"ingredient_id"`
+	Name         string  `json:"name"`
+	Required     float64 `json:"required"`
+	Available    float64 `json:"available"`
+}
+
+// InsufficientStockError lists the ingredients an order needs more of than
+// the inventory has. It is a conflict.
+type InsufficientStockError struct {
+	Shortages []StockShortage
+}
+
+func (e *InsufficientStockError) Error() string {
+	names := make([]string, len(e.Shortages))
+	for i, shortage := range e.Shortages {
+		names[i] = shortage.Name
+	}
+	return "insufficient ingredient: " + strings.Join(names, ", ")
+}
+
+func (e *InsufficientStockError) Unwrap() error {
+	return ErrConflict
+}
+
+// FieldError is one invalid part of a request: a body field located by a
+// JSON pointer, or a query parameter.
+type FieldError struct {
+	Pointer   string `json:"pointer,omitempty"`
+	Parameter string `json:"parameter,omitempty"`
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+}
+
+// Codes of field errors.
+const (
+	CodeRequired     = "required"
+	CodeOutOfRange   = "out_of_range"
+	CodeInvalidValue = "invalid_value"
+	CodeInvalidType  = "invalid_type"
+	CodeUnknownField = "unknown_field"
+	CodeMalformed    = "malformed"
+	CodeNotFound     = "not_found"
+)
+
+// ValidationError lists every invalid field of a request, so the client can
+// fix them all at once. It is a validation error.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Fields) == 1 {
+		return e.Fields[0].Message
+	}
+	return fmt.Sprintf("%d fields are invalid", len(e.Fields))
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrValidation
+}
@@ -1,16 +1,41 @@
 package models
 
+import "time"
+
 type InventoryItem struct {
-	IngredientID int     `json:"ingredient_id"`
-	Name         string  `json:"name"`
-	Quantity     float64 `json:"quantity"`
-	Unit         string  `json:"unit"`
-	Price        float64 `json:"price"`
+	IngredientID int        `json:"ingredient_id"`
+	Name         string     `json:"name"`
+	Quantity     float64    `json:"quantity"`
+	Unit         string     `json:"unit"`
+	Price        float64    `json:"price"`
+	LastUpdated  *time.Time `json:"last_updated,omitempty"`
 }
 
 type InventoryUpdate struct {
 	IngredientID int
 	Name         string
-	QuantityUsed int
-	Remaining    int
+	QuantityUsed float64
+	Remaining    float64
+}
+
+// InventoryFilter describes a page of inventory items. SortBy is one of
+// name, quantity, cost or last_updated; zero values are not applied.
+type InventoryFilter struct {
+	Search         string
+	Unit           string
+	BelowThreshold *float64
+	InStockOnly    bool
+	SortBy         string
+	Descending     bool
+	Page           int
+	PageSize       int
+}
+
+type InventoryPage struct {
+	Items       []InventoryItem `json:"items"`
+	CurrentPage int             `json:"currentPage"`
+	PageSize    int             `json:"pageSize"`
+	TotalItems  int             `json:"totalItems"`
+	TotalPages  int             `json:"totalPages"`
+	HasNextPage bool            `json:"hasNextPage"`
 }
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+type InventoryTransaction struct {
+	ID             int       `json:"transaction_id"`
+	IngredientID   int       `json:"ingredient_id"`
+	IngredientName string    `json:"ingredient_name"`
+	QuantityChange float64   `json:"quantity_change"`
+	Type           string    `json:"transaction_type"`
+	Reason         string    `json:"reason"`
+	EmployeeID     *int      `json:"employee_id"`
+	CreatedAt      time.Time `json:"created_at"`
+	Balance        float64   `json:"running_balance"`
+}
+
+// TransactionFilter describes a page of the inventory ledger.
+// Nil fields are not applied.
+type TransactionFilter struct {
+	IngredientID *int
+	StartDate    *string
+	EndDate      *string
+	Type         *string
+	Reason       *string
+	Cursor       int
+	Limit        int
+}
+
+type TransactionPage struct {
+	Transactions []InventoryTransaction `json:"transactions"`
+	NextCursor   *int                   `json:"next_cursor"`
+	HasMore      bool                   `json:"has_more"`
+	// Set only for a single ingredient ledger
+	CurrentQuantity *float64 `json:"current_quantity,omitempty"`
+	LedgerBalance   *float64 `json:"ledger_balance,omitempty"`
+	Reconciled      *bool    `json:"reconciled,omitempty"`
+}
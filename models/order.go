@@ -3,19 +3,35 @@ package models
 import "time"
 
 type Order struct {
-	ID                  int         `json:"order_id"`
-	CustomerName        string      `json:"customer_name"`
-	TotalAmount         float64     `json:"total_amount"`
-	Items               []OrderItem `json:"items"`
-	SpecialInstructions []string    `json:"special_instructions"`
-	Status              string      `json:"status"`
-	CreatedAt           time.Time   `json:"created_at"`
-	UpdatedAt           string      `json:"update_at"`
+	ID                  int              `json:"order_id"`
+	CustomerName        string           `json:"customer_name"`
+	CustomerID          *int             `json:"customer_id"`
+	Subtotal            float64          `json:"subtotal"`
+	DiscountAmount      float64          `json:"discount_amount"`
+	TaxAmount           float64          `json:"tax_amount"`
+	TotalAmount         float64          `json:"total_amount"`
+	OrderType           string           `json:"order_type"`
+	EmployeeID          *int             `json:"employee_id"`
+	RedeemPoints        int              `json:"redeem_points,omitempty"`
+	PromoCode           string           `json:"promo_code,omitempty"`
+	Promotions          []OrderPromotion `json:"promotions,omitempty"`
+	Taxes               []OrderTax       `json:"taxes,omitempty"`
+	Items               []OrderItem      `json:"items"`
+	SpecialInstructions []string         `json:"special_instructions"`
+	Status              string           `json:"status"`
+	Priority            int              `json:"priority"`
+	CreatedAt           time.Time        `json:"created_at"`
+	UpdatedAt           string           `json:"update_at"`
 }
 
+// OrderItem is an order line. Name and PriceAtOrder are filled in when an
+// order is read back and are ignored when it is created.
 type OrderItem struct {
-	ProductID int `json:"product_id"`
-	Quantity  int `json:"quantity"`
+	ProductID    int      `json:"product_id"`
+	Name         string   `json:"name,omitempty"`
+	Quantity     int      `json:"quantity"`
+	PriceAtOrder float64  `json:"price_at_order,omitempty"`
+	Modifiers    []string `json:"modifiers,omitempty"`
 }
 
 type ChangeHistory struct {
@@ -24,3 +40,29 @@ type ChangeHistory struct {
 	OldValue  string `json:"old_value"`
 	NewValue  string `json:"new_value"`
 }
+
+// OrderFilter describes a page of orders. SortBy is created_at or
+// total_amount; Cursor is the opaque next_cursor of the previous page.
+type OrderFilter struct {
+	Statuses   []string
+	Customer   string
+	CustomerID *int
+	StartDate  *string
+	EndDate    *string
+	MenuItemID *int
+	MinTotal   *float64
+	MaxTotal   *float64
+	SortBy     string
+	Descending bool
+	Cursor     string
+	Limit      int
+	// Keyset position decoded from Cursor
+	AfterValue *string
+	AfterID    int
+}
+
+type OrderPage struct {
+	Orders     []Order `json:"orders"`
+	NextCursor *string `json:"next_cursor"`
+	HasMore    bool    `json:"has_more"`
+}
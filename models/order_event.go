@@ -0,0 +1,16 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OrderEvent is one of order.created, order.updated, order.status_changed
+// or order.cancelled. Payload is a snapshot of the order after the change.
+type OrderEvent struct {
+	ID        int64           `json:"event_id"`
+	OrderID   int             `json:"order_id"`
+	Type      string          `json:"event_type"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"created_at"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// Payment is money taken for an order. Amount goes towards the order total,
+// the tip is on top of it and change is what was handed back from the
+// tendered cash.
+type Payment struct {
+	ID        int       `json:"payment_id"`
+	OrderID   int       `json:"order_id"`
+	Method    string    `json:"method"`
+	Amount    float64   `json:"amount"`
+	Tip       float64   `json:"tip"`
+	Tendered  *float64  `json:"tendered"`
+	Change    float64   `json:"change"`
+	Reference *string   `json:"reference"`
+	Refunded  float64   `json:"refunded"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Refund struct {
+	ID        int       `json:"refund_id"`
+	OrderID   int       `json:"order_id"`
+	PaymentID int       `json:"payment_id"`
+	Amount    float64   `json:"amount"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// OrderBalance is the payment state of an order. Balance due is the total
+// less what was paid and not refunded; tips don't count towards it.
+type OrderBalance struct {
+	OrderID     int       `json:"order_id"`
+	TotalAmount float64   `json:"total_amount"`
+	Paid        float64   `json:"paid"`
+	Refunded    float64   `json:"refunded"`
+	Tips        float64   `json:"tips"`
+	BalanceDue  float64   `json:"balance_due"`
+	Payments    []Payment `json:"payments"`
+	Refunds     []Refund  `json:"refunds"`
+}
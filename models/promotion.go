@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Promotion is a discount rule. Value is a percentage for percentage and
+// buy_x_get_y promotions and an amount for fixed ones. Promotions without a
+// code apply automatically; DaysOfWeek uses ISO numbering (Monday is 1) and
+// StartTime/EndTime ("15:00") limit it to a time of day.
+type Promotion struct {
+	ID          int        `json:"promotion_id"`
+	Name        string     `json:"name"`
+	Type        string     `json:"type"`
+	Value       float64    `json:"value"`
+	Code        *string    `json:"code"`
+	MenuItemID  *int       `json:"menu_item_id"`
+	Category    *string    `json:"category"`
+	BuyQuantity *int       `json:"buy_quantity"`
+	GetQuantity *int       `json:"get_quantity"`
+	MinSubtotal float64    `json:"min_subtotal"`
+	StartsAt    *time.Time `json:"starts_at"`
+	EndsAt      *time.Time `json:"ends_at"`
+	DaysOfWeek  []int64    `json:"days_of_week"`
+	StartTime   *string    `json:"start_time"`
+	EndTime     *string    `json:"end_time"`
+	UsageLimit  *int       `json:"usage_limit"`
+	UsageCount  int        `json:"usage_count"`
+	Active      *bool      `json:"active"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type OrderPromotion struct {
+	PromotionID    int     `json:"promotion_id"`
+	Name           string  `json:"name"`
+	DiscountAmount float64 `json:"discount_amount"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// QueueOrder is an active order as shown on the barista queue.
+type QueueOrder struct {
+	OrderID             int         `json:"order_id"`
+	CustomerName        string      `json:"customer_name"`
+	Status              string      `json:"status"`
+	Priority            int         `json:"priority"`
+	SpecialInstructions []string    `json:"special_instructions"`
+	Station             *string     `json:"station"`
+	HandledBy           *string     `json:"handled_by"`
+	ClaimedAt           *time.Time  `json:"claimed_at"`
+	CreatedAt           time.Time   `json:"created_at"`
+	AgeSeconds          int         `json:"age_seconds"`
+	Items               []QueueItem `json:"items"`
+}
+
+type QueueItem struct {
+	OrderItemID int        `json:"order_item_id"`
+	ProductID   int        `json:"product_id"`
+	Name        string     `json:"name"`
+	Quantity    int        `json:"quantity"`
+	Modifiers   []string   `json:"modifiers"`
+	Done        bool       `json:"done"`
+	DoneAt      *time.Time `json:"done_at"`
+}
+
+type QueueClaim struct {
+	Station   string `json:"station"`
+	HandledBy string `json:"handled_by"`
+}
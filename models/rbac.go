@@ -0,0 +1,6 @@
+package models
+
+type RolePermissions struct {
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
+}
@@ -22,3 +22,71 @@ type InventoryItemR struct {
 	Quantity  int
 	Relevance float64
 }
+
+type InventoryValuation struct {
+	AsOf       string                   `json:"as_of"`
+	CostMethod string                   `json:"cost_method"`
+	Items      []InventoryValuationItem `json:"items"`
+	TotalValue float64                  `json:"total_value"`
+}
+
+type InventoryValuationItem struct {
+	IngredientID int     `json:"ingredient_id"`
+	Name         string  `json:"name"`
+	Unit         string  `json:"unit"`
+	Quantity     float64 `json:"quantity"`
+	UnitCost     float64 `json:"unit_cost"`
+	Value        float64 `json:"value"`
+	// Purchases are the costed additions up to the valuation date
+	PurchasedQuantity float64 `json:"-"`
+	PurchasedValue    float64 `json:"-"`
+}
+
+type IngredientUsageReport struct {
+	StartDate          *string           `json:"start_date"`
+	EndDate            *string           `json:"end_date"`
+	Items              []IngredientUsage `json:"items"`
+	TotalVarianceValue float64           `json:"total_variance_value"`
+}
+
+// IngredientUsage compares what completed orders should have used with what
+// left the stock. A positive variance means more was used than the recipes
+// account for. VariancePercent is nil when there is no theoretical usage.
+type IngredientUsage struct {
+	IngredientID     int      `json:"ingredient_id"`
+	Name             string   `json:"name"`
+	Unit             string   `json:"unit"`
+	UnitCost         float64  `json:"unit_cost"`
+	TheoreticalUsage float64  `json:"theoretical_usage"`
+	OrderUsage       float64  `json:"order_usage"`
+	CountCorrections float64  `json:"count_correction_usage"`
+	ActualUsage      float64  `json:"actual_usage"`
+	Variance         float64  `json:"variance"`
+	VarianceValue    float64  `json:"variance_value"`
+	VariancePercent  *float64 `json:"variance_percent"`
+}
+
+// SalesSummary covers completed orders. Gross sales are menu prices before
+// discounts and net sales are after discounts; total charged adds tax when
+// prices exclude it.
+type SalesSummary struct {
+	StartDate          *string          `json:"start_date"`
+	EndDate            *string          `json:"end_date"`
+	Orders             int              `json:"orders"`
+	GrossSales         float64          `json:"gross_sales"`
+	PromotionDiscounts float64          `json:"promotion_discounts"`
+	LoyaltyDiscounts   float64          `json:"loyalty_discounts"`
+	TotalDiscounts     float64          `json:"total_discounts"`
+	NetSales           float64          `json:"net_sales"`
+	Tax                float64          `json:"tax"`
+	TotalCharged       float64          `json:"total_charged"`
+	Promotions         []PromotionSales `json:"promotions"`
+}
+
+type PromotionSales struct {
+	PromotionID    int     `json:"promotion_id"`
+	Name           string  `json:"name"`
+	Code           *string `json:"code"`
+	Orders         int     `json:"orders"`
+	DiscountAmount float64 `json:"discount_amount"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+type StockCount struct {
+	ID                 int              `json:"count_id"`
+	Status             string           `json:"status"`
+	Note               string           `json:"note"`
+	CreatedAt          time.Time        `json:"created_at"`
+	FinalizedAt        *time.Time       `json:"finalized_at"`
+	Lines              []StockCountLine `json:"lines,omitempty"`
+	TotalVarianceValue float64          `json:"total_variance_value"`
+}
+
+// StockCountLine is a counted ingredient together with its variance.
+// SystemQuantity is the inventory quantity at the moment of counting and
+// ExpectedUsage is the theoretical usage from orders since the previous count.
+type StockCountLine struct {
+	IngredientID    int       `json:"ingredient_id"`
+	Name            string    `json:"name"`
+	Unit            string    `json:"unit"`
+	CountedQuantity float64   `json:"counted_quantity"`
+	SystemQuantity  float64   `json:"system_quantity"`
+	ExpectedUsage   float64   `json:"expected_usage"`
+	Variance        float64   `json:"variance"`
+	UnitCost        float64   `json:"unit_cost"`
+	VarianceValue   float64   `json:"variance_value"`
+	CountedAt       time.Time `json:"counted_at"`
+}
+
+type StockCountSubmission struct {
+	IngredientID    int     `json:"ingredient_id"`
+	CountedQuantity float64 `json:"counted_quantity"`
+}
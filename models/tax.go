@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// TaxRate applies to order lines of its menu category and order type; nil
+// fields match everything. Rate is a percentage.
+type TaxRate struct {
+	ID        int       `json:"tax_rate_id"`
+	Name      string    `json:"name"`
+	Rate      float64   `json:"rate"`
+	Category  *string   `json:"category"`
+	OrderType *string   `json:"order_type"`
+	Active    *bool     `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// OrderTax is the tax of one order line under one rate.
+type OrderTax struct {
+	ProductID     int     `json:"product_id"`
+	TaxRateID     int     `json:"tax_rate_id"`
+	Name          string  `json:"name"`
+	Rate          float64 `json:"rate"`
+	TaxableAmount float64 `json:"taxable_amount"`
+	TaxAmount     float64 `json:"tax_amount"`
+}
+
+type TaxReport struct {
+	StartDate     *string         `json:"start_date"`
+	EndDate       *string         `json:"end_date"`
+	PricesInclTax bool            `json:"prices_include_tax"`
+	Rates         []TaxReportLine `json:"rates"`
+	TotalTaxable  float64         `json:"total_taxable"`
+	TotalTax      float64         `json:"total_tax"`
+}
+
+type TaxReportLine struct {
+	TaxRateID     int     `json:"tax_rate_id"`
+	Name          string  `json:"name"`
+	Rate          float64 `json:"rate"`
+	OrderType     string  `json:"order_type"`
+	Orders        int     `json:"orders"`
+	TaxableAmount float64 `json:"taxable_amount"`
+	TaxAmount     float64 `json:"tax_amount"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type WebhookSubscription struct {
+	ID         int       `json:"subscription_id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Secret     string    `json:"secret,omitempty"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// WebhookDelivery is a pending delivery of an outbox event to one
+// subscription, together with everything needed to send it.
+type WebhookDelivery struct {
+	ID        int64
+	Attempts  int
+	URL       string
+	Secret    string
+	EventID   int64
+	EventType string
+	Payload   json.RawMessage
+	CreatedAt time.Time
+}
+
+type WebhookDeadLetter struct {
+	ID             int64      `json:"dead_letter_id"`
+	DeliveryID     int64      `json:"delivery_id"`
+	SubscriptionID int        `json:"subscription_id"`
+	URL            string     `json:"url"`
+	EventID        int64      `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	Attempts       int        `json:"attempts"`
+	LastError      string     `json:"last_error"`
+	CreatedAt      time.Time  `json:"created_at"`
+	RedeliveredAt  *time.Time `json:"redelivered_at"`
+}